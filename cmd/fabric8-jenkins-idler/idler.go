@@ -2,6 +2,9 @@ package main
 
 import (
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
@@ -10,10 +13,13 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/api"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/router"
@@ -23,7 +29,8 @@ import (
 )
 
 const (
-	profilerPort = 6060
+	profilerPort    = 6060
+	authKeysTimeout = 10 * time.Second
 )
 
 var idlerLogger = log.WithFields(log.Fields{"component": "idler"})
@@ -37,8 +44,10 @@ type Idler struct {
 	tenantService  tenant.Service
 	clusterView    cluster.View
 	config         configuration.Configuration
-	disabledUsers  *model.StringSet
+	disabledUsers  *disabled.Users
+	leases         *lease.Leases
 	userIdlers     *openshift.UserIdlerMap
+	bus            *events.Bus
 }
 
 // struct used to pass in cancelable task
@@ -50,14 +59,16 @@ type task struct {
 
 // NewIdler creates a new instance of Idler. The configuration as well as feature toggle handler needs to be passed.
 func NewIdler(features toggles.Features, tenantService tenant.Service, clusterView cluster.View,
-	config configuration.Configuration) *Idler {
+	config configuration.Configuration, disabledUsers *disabled.Users, leases *lease.Leases) *Idler {
 	return &Idler{
 		featureService: features,
 		tenantService:  tenantService,
 		clusterView:    clusterView,
 		config:         config,
-		disabledUsers:  model.NewStringSet(),
+		disabledUsers:  disabledUsers,
+		leases:         leases,
 		userIdlers:     openshift.NewUserIdlerMap(),
+		bus:            events.NewBus(),
 	}
 }
 
@@ -81,15 +92,32 @@ func (idler *Idler) startWorkers(t *task, addProfiler bool) {
 	// Start the controllers to monitor the OpenShift clusters
 	idler.watchOpenshiftEvents(t)
 
+	// Pick up the users disabled by other replicas
+	idler.disabledUsers.Watch(t.ctx, t.wg, time.Duration(idler.config.GetDisabledUsersRefresh())*time.Second)
+
+	// Pick up the keep-alive leases taken and revoked by other replicas
+	idler.leases.Watch(t.ctx, t.wg, time.Duration(idler.config.GetLeasesRefresh())*time.Second)
+
 	// Start API router
 	go func() {
 		// Create and start a Router instance to serve the REST API
+		identities := auth.NewIdentityService(idler.config.GetAuthURL())
 		idlerAPI := api.NewIdlerAPI(
 			idler.userIdlers,
 			idler.clusterView,
 			idler.tenantService,
-			idler.disabledUsers)
-		apirouter := router.CreateAPIRouter(idlerAPI)
+			identities,
+			idler.disabledUsers,
+			idler.config,
+			idler.bus)
+		idlerAPIV2 := api.NewIdlerAPIV2(idlerAPI)
+		authenticator, err := createAuthenticator(idler.config)
+		if err != nil {
+			idlerLogger.Errorf("Invalid API authentication configuration: %s", err)
+			t.cancel()
+			return
+		}
+		apirouter := router.CreateAPIRouter(idlerAPI, idlerAPIV2, authenticator)
 		router := router.NewRouter(apirouter)
 		router.AddMetrics(apirouter)
 		router.Start(t.ctx, t.wg, t.cancel)
@@ -104,6 +132,32 @@ func (idler *Idler) startWorkers(t *task, addProfiler bool) {
 	}
 }
 
+// createAuthenticator returns the authenticator for the REST API, nil if authentication is disabled.
+// Service account tokens are verified with the keys published by fabric8-auth.
+func createAuthenticator(config configuration.Configuration) (auth.Authenticator, error) {
+	if !config.GetAPIAuth() {
+		idlerLogger.Warn("REST API authentication is disabled.")
+		return nil, nil
+	}
+
+	serviceAccounts, err := auth.ParseScopedEntries(config.GetAPIServiceAccounts())
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := auth.ParseScopedEntries(config.GetAPITokens())
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, audience := config.GetAPITokenIssuer(), config.GetAPITokenAudience()
+	if issuer == "" || audience == "" {
+		idlerLogger.Warn("Issuer resp. audience of service account tokens are not checked.")
+	}
+
+	keysURL := strings.TrimSuffix(config.GetAuthURL(), "/") + "/api/token/keys?format=jwk"
+	return auth.NewAuthenticator(auth.NewJWKSKeys(keysURL, authKeysTimeout), issuer, audience, serviceAccounts, tokens), nil
+}
+
 func (idler *Idler) watchOpenshiftEvents(t *task) {
 	oc := client.NewOpenShift()
 
@@ -113,6 +167,7 @@ func (idler *Idler) watchOpenshiftEvents(t *task) {
 			t.ctx,
 			c.APIURL,
 			c.Token,
+			c.MetricsURL,
 			idler.userIdlers,
 			idler.tenantService,
 			idler.featureService,
@@ -120,6 +175,8 @@ func (idler *Idler) watchOpenshiftEvents(t *task) {
 			t.wg,
 			t.cancel,
 			idler.disabledUsers,
+			idler.leases,
+			idler.bus,
 		)
 
 		t.wg.Add(2)
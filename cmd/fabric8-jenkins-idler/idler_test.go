@@ -8,6 +8,9 @@ import (
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
@@ -21,6 +24,10 @@ func (m *mockFeatureToggle) IsIdlerEnabled(uid string) (bool, error) {
 	return true, nil
 }
 
+func (m *mockFeatureToggle) Tier(uid string, tiers []string) (string, error) {
+	return "", nil
+}
+
 type mockClusterView struct {
 	*mock.ClusterView
 }
@@ -44,7 +51,8 @@ func Test_graceful_shutdown(t *testing.T) {
 	hook := test.NewGlobal()
 
 	config, _ := configuration.New("")
-	idler := NewIdler(&mockFeatureToggle{}, &mock.TenantService{}, &mockClusterView{}, config)
+	idler := NewIdler(&mockFeatureToggle{}, &mock.TenantService{}, &mockClusterView{}, config, disabled.NewUsers(store.NewMemoryStore()),
+		lease.NewLeases(store.NewMemoryStore()))
 
 	go func() {
 		// Send SIGTERM after two seconds
@@ -3,12 +3,16 @@ package main
 import (
 	"flag"
 	"os"
+	"strings"
 
 	"context"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
 	openShiftClient "github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/toggles"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/token"
@@ -57,7 +61,13 @@ func main() {
 	// Create Tenant Service
 	tenantService := tenant.NewTenantService(config.GetTenantURL(), osioToken)
 
-	idler := NewIdler(featuresService, tenantService, clusterView, config)
+	// Load the users idling is disabled for
+	disabledUsers := disabledUsers(config, clusterView)
+
+	// Load the keep-alive leases
+	leases := leases(config, clusterView)
+
+	idler := NewIdler(featuresService, tenantService, clusterView, config, disabledUsers, leases)
 	idler.Run()
 }
 
@@ -151,3 +161,51 @@ func clusterView(osioToken string, config configuration.Configuration) cluster.V
 
 	return clusterView
 }
+
+func disabledUsers(config configuration.Configuration, clusterView cluster.View) *disabled.Users {
+	configMap := config.GetDisabledUsersConfigMap()
+	if configMap == "" {
+		mainLogger.Warn("No config map configured for disabled users, they are kept in memory only.")
+		return disabled.NewUsers(store.NewMemoryStore())
+	}
+
+	apiURL := config.GetDisabledUsersCluster()
+	bearerToken, ok := clusterView.GetToken(apiURL)
+	if !ok {
+		// Fatal with exit program
+		mainLogger.WithField("cluster", apiURL).Fatal("Unknown cluster for disabled users config map")
+	}
+	parts := strings.SplitN(configMap, "/", 2)
+	configMapStore := store.NewConfigMapStore(openShiftClient.NewOpenShift(), apiURL, bearerToken, parts[0], parts[1], disabled.ConfigMapKey)
+
+	users := disabled.NewUsers(configMapStore)
+	if err := users.Refresh(); err != nil {
+		// Fatal with exit program
+		mainLogger.WithField("err", err).Fatal("Unable to load disabled users")
+	}
+	return users
+}
+
+func leases(config configuration.Configuration, clusterView cluster.View) *lease.Leases {
+	configMap := config.GetLeasesConfigMap()
+	if configMap == "" {
+		mainLogger.Warn("No config map configured for keep-alive leases, they are kept in memory only.")
+		return lease.NewLeases(store.NewMemoryStore())
+	}
+
+	apiURL := config.GetLeasesCluster()
+	bearerToken, ok := clusterView.GetToken(apiURL)
+	if !ok {
+		// Fatal with exit program
+		mainLogger.WithField("cluster", apiURL).Fatal("Unknown cluster for leases config map")
+	}
+	parts := strings.SplitN(configMap, "/", 2)
+	configMapStore := store.NewConfigMapStore(openShiftClient.NewOpenShift(), apiURL, bearerToken, parts[0], parts[1], lease.ConfigMapKey)
+
+	leases := lease.NewLeases(configMapStore)
+	if err := leases.Refresh(); err != nil {
+		// Fatal with exit program
+		mainLogger.WithField("err", err).Fatal("Unable to load keep-alive leases")
+	}
+	return leases
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,7 +9,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
 	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
@@ -24,6 +29,8 @@ const (
 	// OpenShiftAPIParam is the parameter name under which the OpenShift cluster API URL is passed using
 	// Idle, UnIdle and IsIdle.
 	OpenShiftAPIParam = "openshift_api_url"
+
+	jenkinsNamespaceSuffix = "-jenkins"
 )
 
 var (
@@ -63,6 +70,70 @@ type IdlerAPI interface {
 
 	// GetDisabledUserIdlers gets the user status for idler.
 	GetDisabledUserIdlers(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// DryRunReport writes a JSON report of how many Jenkins instances the user idlers running in
+	// dry-run mode would have idled resp. un-idled.
+	DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// KeepAlive cancels a pending idle of the Jenkins service in the namespace specified in the namespace
+	// parameter of the request and postpones any new one for the duration of the grace period. If the
+	// optional JSON body contains a duration and a reason, Jenkins is kept running by a lease until the
+	// duration has passed, replacing any active lease of the namespace.
+	// If there is no idler for the namespace a response with the HTTP status 404 is returned.
+	KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Unpin revokes the keep-alive lease of the Jenkins service in the namespace specified in the namespace
+	// parameter of the request. If there is no active lease a response with the HTTP status 404 is returned.
+	Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Leases lists the active keep-alive leases.
+	Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Explain writes a JSON explanation of the most recent idling decision for the Jenkins service in
+	// the namespace specified in the namespace parameter of the request, including a forecast of when
+	// Jenkins will be idled next. If there is no idler for the namespace or the idler has not evaluated
+	// its conditions yet, a response with the HTTP status 404 is returned.
+	Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// SelfService lets end users idle, un-idle, reset or pin their own Jenkins, authenticated with their own
+	// OSIO token. The action and the Jenkins namespace are specified in the action and namespace parameters
+	// of the request. Invalid tokens are rejected with HTTP status 401, namespaces which do not belong to
+	// the user with 403.
+	SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Namespaces lists all namespaces tracked by the Idler with cluster, user, last observed Jenkins state,
+	// last build, last idle resp. un-idle status and next check. The list can be filtered by the cluster
+	// and state query parameters, sorted by the sort parameter (prefixed with "-" for descending order)
+	// and paginated with the page and per_page parameters. Invalid parameters result in HTTP status 400.
+	Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Bulk starts an asynchronous idle resp. un-idle of all namespaces matching the selector in the JSON body
+	// of the request and responds with HTTP status 202 and the job. At most GetBulkConcurrency namespaces per
+	// cluster are handled concurrently.
+	Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// BulkJob reports the progress and the per-namespace results of the bulk job specified in the id parameter
+	// of the request. Unknown jobs result in HTTP status 404.
+	BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Events streams the decisions of the user idlers and the Jenkins state changes seen by the controllers
+	// as server-sent events, optionally restricted to the namespace given in the namespace query parameter.
+	// The stream ends when the client disconnects.
+	Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Operation reports the state, timing and failure reason of the un-idle operation specified in the id
+	// parameter of the request. With the wait query parameter, e.g. "60s", the request waits at most that
+	// long (and at most 5 minutes) for Jenkins to be running or the operation to fail. Unknown operations
+	// result in HTTP status 404.
+	Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Check runs an idle check for the Jenkins service in the namespace specified in the namespace parameter
+	// of the request right away and writes a JSON report of the user state, the condition verdicts, the retry
+	// counters and the action taken. With the dryRun query parameter set to true the action is only determined,
+	// not taken, but the conditions are evaluated as in any other check, so e.g. prewarm predictions and webhook
+	// verdicts are recorded. If there is no idler for the namespace a response with the HTTP status 404 is
+	// returned, if the check does not complete in time one with 503.
+	Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
 }
 
 type idler struct {
@@ -70,7 +141,12 @@ type idler struct {
 	clusterView     cluster.View
 	openShiftClient client.OpenShiftClient
 	tenantService   tenant.Service
-	disabledUsers   *model.StringSet
+	identities      auth.IdentityService
+	disabledUsers   *disabled.Users
+	config          configuration.Configuration
+	bulkJobs        *bulkJobs
+	operations      *operations
+	bus             *events.Bus
 }
 
 type status struct {
@@ -80,6 +156,11 @@ type status struct {
 type userStatus struct {
 	Disable []string `json:"disable"`
 	Enable  []string `json:"enable"`
+	// Reason explains why idling is disabled for the users of Disable.
+	Reason string `json:"reason"`
+	// ExpiresIn is the duration after which idling is enabled again for the users of Disable, e.g. "24h".
+	// They stay disabled until enabled explicitly if empty.
+	ExpiresIn string `json:"expires_in"`
 }
 
 // NewIdlerAPI creates a new instance of IdlerAPI.
@@ -87,7 +168,10 @@ func NewIdlerAPI(
 	userIdlers *openshift.UserIdlerMap,
 	clusterView cluster.View,
 	ts tenant.Service,
-	du *model.StringSet) IdlerAPI {
+	identities auth.IdentityService,
+	du *disabled.Users,
+	config configuration.Configuration,
+	bus *events.Bus) IdlerAPI {
 	// Initialize metrics
 	Recorder.Initialize()
 	return &idler{
@@ -95,7 +179,12 @@ func NewIdlerAPI(
 		clusterView:     clusterView,
 		openShiftClient: client.NewOpenShift(),
 		tenantService:   ts,
+		identities:      identities,
 		disabledUsers:   du,
+		config:          config,
+		bulkJobs:        newBulkJobs(),
+		operations:      newOperations(),
+		bus:             bus,
 	}
 }
 
@@ -106,21 +195,28 @@ func (api *idler) Idle(w http.ResponseWriter, r *http.Request, ps httprouter.Par
 		return
 	}
 
+	if err := api.idle(openShiftAPI, openShiftBearerToken, ps.ByName("namespace")); err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (api *idler) idle(openShiftAPI, openShiftBearerToken, ns string) *apiError {
 	for _, service := range pidler.JenkinsServices {
 		startTime := time.Now()
-		err = api.openShiftClient.Idle(openShiftAPI, openShiftBearerToken, ps.ByName("namespace"), service)
+		err := api.openShiftClient.Idle(openShiftAPI, openShiftBearerToken, ns, service)
 		elapsedTime := time.Since(startTime).Seconds()
 
 		if err != nil {
 			Recorder.RecordReqDuration(service, "Idle", http.StatusInternalServerError, elapsedTime)
-			respondWithError(w, http.StatusInternalServerError, err)
-			return
+			return newAPIError(http.StatusInternalServerError, codeOpenShiftError, err)
 		}
 
 		Recorder.RecordReqDuration(service, "Idle", http.StatusOK, elapsedTime)
 	}
-
-	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 func (api *idler) UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -138,27 +234,49 @@ func (api *idler) UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.P
 		return
 	}
 
-	// may be jenkins is already running and in that case we don't have to do unidle it
-	running, err := api.isJenkinsUnIdled(openshiftURL, openshiftToken, ns)
-	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err)
+	if err := api.unIdle(openshiftURL, openshiftToken, ns); err != nil {
+		respondWithError(w, err.status, err)
 		return
-	} else if running {
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// unIdle un-idles Jenkins in the given namespace unless it is already starting or running.
+func (api *idler) unIdle(openshiftURL, openshiftToken, ns string) *apiError {
+	// an un-idle request means the user is active, which is what the pre-warming learns from
+	if userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix)); ok {
+		userIdler.ObserveActivity(time.Now())
+	}
+
+	dc, err := api.openShiftClient.DeploymentConfig(openshiftURL, openshiftToken, ns, "jenkins")
+	if err != nil {
+		return newAPIError(http.StatusInternalServerError, codeOpenShiftError, err)
+	}
+
+	// a soft-idled jenkins is running, but its resources need to be restored
+	if dc.SoftIdled() {
+		for _, service := range pidler.JenkinsServices {
+			if err := api.openShiftClient.SoftUnIdle(openshiftURL, openshiftToken, ns, service); err != nil {
+				return newAPIError(http.StatusInternalServerError, codeOpenShiftError, err)
+			}
+		}
+	}
+
+	// may be jenkins is already running and in that case we don't have to do unidle it
+	if state := dc.State(); state == model.PodStarting || state == model.PodRunning {
 		log.Infof("Jenkins is already starting/running on %s", ns)
-		w.WriteHeader(http.StatusOK)
-		return
+		return nil
 	}
 
 	// now that jenkins isn't running we need to check if the cluster has reached
 	// its maximum capacity
 	clusterFull, err := api.tenantService.HasReachedMaxCapacity(openshiftURL, ns)
 	if err != nil {
-		respondWithError(w, http.StatusInternalServerError, err)
-		return
+		return newAPIError(http.StatusInternalServerError, codeTenantError, err)
 	} else if clusterFull {
-		err := fmt.Errorf("Maximum Resource limit reached on %s for %s", openshiftURL, ns)
-		respondWithError(w, http.StatusServiceUnavailable, err)
-		return
+		return newAPIError(http.StatusServiceUnavailable, codeCapacityExhausted,
+			fmt.Errorf("Maximum Resource limit reached on %s for %s", openshiftURL, ns))
 	}
 
 	// unidle now
@@ -169,14 +287,12 @@ func (api *idler) UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.P
 		elapsedTime := time.Since(startTime).Seconds()
 		if err != nil {
 			Recorder.RecordReqDuration(service, "UnIdle", http.StatusInternalServerError, elapsedTime)
-			respondWithError(w, http.StatusInternalServerError, err)
-			return
+			return newAPIError(http.StatusInternalServerError, codeOpenShiftError, err)
 		}
 
 		Recorder.RecordReqDuration(service, "UnIdle", http.StatusOK, elapsedTime)
 	}
-
-	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 func (api *idler) IsIdle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
@@ -219,6 +335,14 @@ func (api *idler) Status(w http.ResponseWriter, r *http.Request, ps httprouter.P
 	}
 
 	response.SetState(state)
+	if userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ps.ByName("namespace"), jenkinsNamespaceSuffix)); ok {
+		if pending, ok := userIdler.PendingIdle(); ok {
+			response.SetPendingIdle(pending)
+		}
+		if lease, ok := userIdler.Lease(); ok {
+			response.SetLease(lease)
+		}
+	}
 	writeResponse(w, http.StatusOK, *response)
 }
 
@@ -257,22 +381,227 @@ func (api *idler) SetUserIdlerStatus(w http.ResponseWriter, r *http.Request, ps
 		return
 	}
 
-	// enabled users will take precedence over disabled
-	api.disabledUsers.Add(users.Disable)
-	api.disabledUsers.Remove(users.Enable)
+	if err := api.updateDisabledUsers(r, users); err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
 type idlerStatusResponse struct {
-	Users []string `json:"users,omitempty"`
+	Users   []string         `json:"users,omitempty"`
+	Entries []disabled.Entry `json:"entries"`
 }
 
 //GetDisabledUserIdlers set the user status
 func (api *idler) GetDisabledUserIdlers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
-	users := &idlerStatusResponse{Users: api.disabledUsers.Keys()}
+	entries := api.disabledUsers.Entries()
+	users := &idlerStatusResponse{Users: api.disabledUsers.Keys(), Entries: entries}
 	writeResponse(w, http.StatusOK, users)
 }
 
+type dryRunEntry struct {
+	Namespace string          `json:"namespace"`
+	Decision  pidler.Decision `json:"decision"`
+}
+
+type dryRunReport struct {
+	WouldIdle   int           `json:"would_idle"`
+	WouldUnIdle int           `json:"would_unidle"`
+	Namespaces  []dryRunEntry `json:"namespaces"`
+}
+
+// DryRunReport reports the most recent decision of each user idler running in dry-run mode.
+func (api *idler) DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeResponse(w, http.StatusOK, api.dryRunReport())
+}
+
+func (api *idler) dryRunReport() dryRunReport {
+	report := dryRunReport{Namespaces: []dryRunEntry{}}
+
+	api.userIdlers.Range(func(ns string, userIdler *pidler.UserIdler) bool {
+		if !userIdler.IsDryRun() {
+			return true
+		}
+
+		decision, ok := userIdler.History().Last()
+		if !ok {
+			return true
+		}
+
+		if decision.WouldIdle() {
+			report.WouldIdle++
+		} else if decision.WouldUnIdle() {
+			report.WouldUnIdle++
+		}
+		report.Namespaces = append(report.Namespaces, dryRunEntry{Namespace: ns, Decision: decision})
+		return true
+	})
+	return report
+}
+
+type keepAliveResponse struct {
+	Cancelled bool          `json:"cancelled"`
+	Lease     *pidler.Lease `json:"lease,omitempty"`
+}
+
+// KeepAlive cancels a pending idle and reports whether one was pending. If the body requests a lease,
+// Jenkins is kept running until the lease expires.
+func (api *idler) KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := api.keepAlive(r, strings.TrimSpace(ps.ByName("namespace")))
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, response)
+}
+
+// keepAlive records activity for the namespace and cancels a pending idle. If the optional body of the
+// request contains a duration, a lease is taken in addition.
+func (api *idler) keepAlive(r *http.Request, ns string) (keepAliveResponse, *apiError) {
+	userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		return keepAliveResponse{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No idler found for namespace %s", ns))
+	}
+
+	var request keepAliveRequest
+	if err := decodeOptionalBody(r, &request); err != nil {
+		return keepAliveResponse{}, err
+	}
+	duration, err := api.leaseDuration(request)
+	if err != nil {
+		return keepAliveResponse{}, err
+	}
+
+	userIdler.ObserveActivity(time.Now())
+	response := keepAliveResponse{Cancelled: userIdler.KeepAlive()}
+	if duration > 0 {
+		lease, err := userIdler.Pin(duration, request.Reason)
+		if err != nil {
+			return keepAliveResponse{}, newAPIError(http.StatusInternalServerError, codeStoreError, fmt.Errorf("Failed to store lease: %s", err))
+		}
+		response.Lease = &lease
+	}
+	return response, nil
+}
+
+type explainResponse struct {
+	Namespace string `json:"namespace"`
+	pidler.Explanation
+}
+
+// Explain explains the most recent idling decision for a namespace.
+func (api *idler) Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := strings.TrimSpace(ps.ByName("namespace"))
+	userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		respondWithError(w, http.StatusNotFound, fmt.Errorf("No idler found for namespace %s", ns))
+		return
+	}
+
+	explanation, ok := userIdler.Explain()
+	if !ok {
+		respondWithError(w, http.StatusNotFound, fmt.Errorf("No decision taken yet for namespace %s", ns))
+		return
+	}
+
+	writeResponse(w, http.StatusOK, explainResponse{Namespace: ns, Explanation: explanation})
+}
+
+// SelfService resolves the identity and the tenant of the caller from their token and performs the requested
+// action on their Jenkins, using the token of the cluster the Jenkins namespace lives on.
+func (api *idler) SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := api.selfService(r, ps.ByName("action"), strings.TrimSpace(ps.ByName("namespace")))
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+
+	if response != nil {
+		writeResponse(w, http.StatusOK, response)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// selfService performs the given action on behalf of the owner of the token of the request. It returns the
+// body of the response, nil if there is none.
+func (api *idler) selfService(r *http.Request, action string, ns string) (any, *apiError) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return nil, newAPIError(http.StatusUnauthorized, codeUnauthorized, errors.New("Missing bearer token"))
+	}
+	userToken := strings.TrimPrefix(header, "Bearer ")
+
+	identity, err := api.identities.Identity(r.Context(), userToken)
+	if err != nil {
+		return nil, newAPIError(http.StatusUnauthorized, codeUnauthorized, fmt.Errorf("Unable to resolve user: %s", err))
+	}
+
+	tenantInfo, err := api.tenantService.GetTenantInfoByToken(userToken)
+	if err != nil {
+		return nil, newAPIError(http.StatusInternalServerError, codeTenantError,
+			fmt.Errorf("Unable to resolve tenant of %s: %s", identity.Username, err))
+	}
+
+	namespace, ok := ownedJenkinsNamespace(identity, tenantInfo, ns)
+	if !ok {
+		return nil, newAPIError(http.StatusForbidden, codeForbidden, fmt.Errorf("Namespace %s is not owned by %s", ns, identity.Username))
+	}
+
+	c, err := api.resolveCluster(r.Context(), namespace.ClusterURL)
+	if err != nil {
+		return nil, newAPIError(http.StatusNotFound, codeUnknownCluster,
+			fmt.Errorf("Cluster %s of namespace %s is not served by this idler", namespace.ClusterURL, ns))
+	}
+	openShiftAPIURL, openShiftBearerToken := c.APIURL, c.Token
+
+	log.WithFields(log.Fields{"component": "api", "user": identity.Username, "namespace": ns, "action": action}).
+		Info("Self-service request")
+
+	switch action {
+	case "idle":
+		return nil, api.idle(openShiftAPIURL, openShiftBearerToken, ns)
+	case "unidle":
+		return nil, api.unIdle(openShiftAPIURL, openShiftBearerToken, ns)
+	case "reset":
+		return nil, api.reset(openShiftAPIURL, openShiftBearerToken, ns)
+	case "pin":
+		response, err := api.keepAlive(r, ns)
+		if err != nil {
+			return nil, err
+		}
+		return response, nil
+	}
+	return nil, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Unknown action %s", action))
+}
+
+// resolveCluster returns the cluster with the given API URL, ignoring differences in the trailing slash the
+// same way the cluster resolution does.
+func (api *idler) resolveCluster(ctx context.Context, apiURL string) (*cluster.Cluster, error) {
+	var clusters []*cluster.Cluster
+	for _, c := range api.clusterView.GetClusters() {
+		c := c
+		clusters = append(clusters, &c)
+	}
+	return cluster.NewResolve(clusters)(ctx, apiURL)
+}
+
+// ownedJenkinsNamespace returns the Jenkins namespace with the given name if the tenant belongs to the identity.
+func ownedJenkinsNamespace(identity auth.Identity, tenantInfo tenant.Info, ns string) (tenant.Namespace, bool) {
+	if tenantInfo.Data.ID != identity.ID {
+		return tenant.Namespace{}, false
+	}
+
+	for _, namespace := range tenantInfo.Data.Attributes.Namespaces {
+		if namespace.Name == ns && namespace.Type == "jenkins" {
+			return namespace, true
+		}
+	}
+	return tenant.Namespace{}, false
+}
+
 func (api *idler) getURLAndToken(r *http.Request) (string, string, error) {
 	var openShiftAPIURL string
 	values, ok := r.URL.Query()[OpenShiftAPIParam]
@@ -288,14 +617,11 @@ func (api *idler) getURLAndToken(r *http.Request) (string, string, error) {
 	return "", "", fmt.Errorf("Unknown or invalid OpenShift API URL: %s", openShiftAPIURL)
 }
 
-func (api idler) isJenkinsUnIdled(openshiftURL, openshiftToken, namespace string) (bool, error) {
-	state, err := api.openShiftClient.State(openshiftURL, openshiftToken, namespace, "jenkins")
-	if err != nil {
-		return false, err
+func (api *idler) reset(openShiftAPI, openShiftBearerToken, ns string) *apiError {
+	if err := api.openShiftClient.Reset(openShiftAPI, openShiftBearerToken, ns); err != nil {
+		return newAPIError(http.StatusInternalServerError, codeOpenShiftError, err)
 	}
-
-	status := state == model.PodStarting || state == model.PodRunning
-	return status, nil
+	return nil
 }
 
 func respondWithError(w http.ResponseWriter, status int, err error) {
@@ -311,7 +637,9 @@ type responseError struct {
 }
 
 type jenkinsInfo struct {
-	State string `json:"state"`
+	State       string              `json:"state"`
+	PendingIdle *pidler.PendingIdle `json:"pending_idle,omitempty"`
+	Lease       *pidler.Lease       `json:"lease,omitempty"`
 }
 
 type statusResponse struct {
@@ -340,6 +668,22 @@ func (s *statusResponse) SetState(state model.PodState) *statusResponse {
 	return s
 }
 
+func (s *statusResponse) SetPendingIdle(pending pidler.PendingIdle) *statusResponse {
+	if s.Data == nil {
+		s.Data = &jenkinsInfo{}
+	}
+	s.Data.PendingIdle = &pending
+	return s
+}
+
+func (s *statusResponse) SetLease(lease pidler.Lease) *statusResponse {
+	if s.Data == nil {
+		s.Data = &jenkinsInfo{}
+	}
+	s.Data.Lease = &lease
+	return s
+}
+
 type any interface{}
 
 func writeResponse(w http.ResponseWriter, status int, response any) {
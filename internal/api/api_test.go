@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
 	"github.com/julienschmidt/httprouter"
 	"github.com/stretchr/testify/require"
@@ -25,6 +32,7 @@ func Test_success(t *testing.T) {
 		openShiftClient: mosc,
 		clusterView:     &mock.ClusterView{},
 		tenantService:   &mock.TenantService{},
+		userIdlers:      openshift.NewUserIdlerMap(),
 	}
 	functions := []ReqFuncType{
 		mockidle.Idle, mockidle.UnIdle,
@@ -75,6 +83,7 @@ func Test_fail(t *testing.T) {
 		},
 		clusterView:   &mock.ClusterView{},
 		tenantService: &mock.TenantService{},
+		userIdlers:    openshift.NewUserIdlerMap(),
 	}
 	functions = []ReqFuncType{mockidle.Idle, mockidle.UnIdle, mockidle.IsIdle}
 	params := httprouter.Params{
@@ -151,6 +160,148 @@ func Test_Status_BadRequest_fail(t *testing.T) {
 		"failed to obtain openshift token", "Error must have a description")
 }
 
+func Test_DryRunReport(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	config := &mock.Config{DryRunUsers: []string{"foo", "bar"}}
+	features := mock.NewMockFeatureToggle([]string{})
+
+	decisions := map[string]pidler.Decision{
+		"foo": {Action: "idle", DryRun: true, State: "running"},
+		"bar": {Action: "unidle", DryRun: true, State: "idled"},
+		"baz": {Action: "idle", State: "running"},
+	}
+	for name, decision := range decisions {
+		userIdler := pidler.NewUserIdler(model.NewUser(name, name), "", "", "", config, features, &mock.TenantService{}, nil, nil)
+		userIdler.History().Add(decision)
+		userIdlers.Store(name, userIdler)
+	}
+
+	mockIdler := idler{userIdlers: userIdlers}
+	w := httptest.NewRecorder()
+	mockIdler.DryRunReport(w, nil, nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	report := &dryRunReport{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), report))
+	require.Equal(t, 1, report.WouldIdle, "Unexpected number of would be idled instances")
+	require.Equal(t, 1, report.WouldUnIdle, "Unexpected number of would be un-idled instances")
+	require.Len(t, report.Namespaces, 2, "Only dry-run namespaces should be reported")
+}
+
+func Test_KeepAlive(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdler := pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{PreIdleGracePeriod: 10},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+	userIdlers.Store("foo", userIdler)
+	mockIdler := idler{userIdlers: userIdlers}
+
+	w := httptest.NewRecorder()
+	mockIdler.KeepAlive(w, nil, httprouter.Params{{Key: "namespace", Value: "bar-jenkins"}})
+	require.Equal(t, http.StatusNotFound, w.Code, "Unknown namespace should not be found")
+
+	w = httptest.NewRecorder()
+	mockIdler.KeepAlive(w, httptest.NewRequest("POST", "/", nil), httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	response := &keepAliveResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), response))
+	require.False(t, response.Cancelled, "No idle was pending")
+}
+
+func Test_Explain(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdler := pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+	userIdlers.Store("foo", userIdler)
+	mockIdler := idler{userIdlers: userIdlers}
+
+	w := httptest.NewRecorder()
+	mockIdler.Explain(w, nil, httprouter.Params{{Key: "namespace", Value: "bar-jenkins"}})
+	require.Equal(t, http.StatusNotFound, w.Code, "Unknown namespace should not be found")
+
+	w = httptest.NewRecorder()
+	mockIdler.Explain(w, nil, httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+	require.Equal(t, http.StatusNotFound, w.Code, "Nothing to explain before the first evaluation")
+}
+
+type stubIdentities map[string]auth.Identity
+
+func (s stubIdentities) Identity(ctx context.Context, token string) (auth.Identity, error) {
+	identity, ok := s[token]
+	if !ok {
+		return auth.Identity{}, errors.New("invalid token")
+	}
+	return identity, nil
+}
+
+func Test_SelfService(t *testing.T) {
+	tenantInfo := func(id string, namespaces ...string) tenant.Info {
+		info := tenant.Info{}
+		info.Data.ID = id
+		for _, ns := range namespaces {
+			info.Data.Attributes.Namespaces = append(info.Data.Attributes.Namespaces,
+				tenant.Namespace{Name: ns, Type: "jenkins", ClusterURL: "http://localhost"})
+		}
+		return info
+	}
+
+	otherCluster := tenantInfo("3", "baz-jenkins")
+	otherCluster.Data.Attributes.Namespaces[0].ClusterURL = "https://api.other.example.com"
+
+	tests := []struct {
+		name      string
+		token     string
+		action    string
+		namespace string
+		status    int
+		idled     bool
+	}{
+		{"missing token", "", "idle", "foo-jenkins", http.StatusUnauthorized, false},
+		{"invalid token", "invalid", "idle", "foo-jenkins", http.StatusUnauthorized, false},
+		{"own namespace", "foo-token", "idle", "foo-jenkins", http.StatusOK, true},
+		{"foreign namespace", "foo-token", "idle", "bar-jenkins", http.StatusForbidden, false},
+		{"tenant of other identity", "stolen-token", "idle", "bar-jenkins", http.StatusForbidden, false},
+		{"unknown action", "foo-token", "delete", "foo-jenkins", http.StatusBadRequest, false},
+		{"pin without idler", "foo-token", "pin", "foo-jenkins", http.StatusNotFound, false},
+		{"namespace on other cluster", "baz-token", "idle", "baz-jenkins", http.StatusNotFound, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mosc := &mock.OpenShiftClient{}
+			mockIdler := idler{
+				openShiftClient: mosc,
+				// the tenant service reports the cluster URL without trailing slash
+				clusterView: cluster.NewView([]cluster.Cluster{{APIURL: "http://localhost/", Token: "secret"}}),
+				userIdlers:  openshift.NewUserIdlerMap(),
+				identities: stubIdentities{
+					"foo-token":    {ID: "1", Username: "foo"},
+					"stolen-token": {ID: "1", Username: "foo"},
+					"baz-token":    {ID: "3", Username: "baz"},
+				},
+				tenantService: &mock.TenantService{Tenants: map[string]tenant.Info{
+					"foo-token":    tenantInfo("1", "foo-jenkins"),
+					"stolen-token": tenantInfo("2", "bar-jenkins"),
+					"baz-token":    otherCluster,
+				}},
+			}
+
+			r := httptest.NewRequest("POST", "/", nil)
+			if test.token != "" {
+				r.Header.Set("Authorization", "Bearer "+test.token)
+			}
+			w := httptest.NewRecorder()
+			mockIdler.SelfService(w, r, httprouter.Params{
+				{Key: "action", Value: test.action},
+				{Key: "namespace", Value: test.namespace},
+			})
+
+			require.Equal(t, test.status, w.Code, "Unexpected status code")
+			require.Equal(t, test.idled, mosc.IdleCallCount > 0, "Unexpected idling")
+		})
+	}
+}
+
 func Test_writeFunctions(t *testing.T) {
 	w := httptest.NewRecorder()
 	testStatus := http.StatusBadRequest
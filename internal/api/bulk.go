@@ -0,0 +1,339 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxFinishedBulkJobs limits how many finished bulk jobs are kept for their results to be queried.
+const maxFinishedBulkJobs = 100
+
+// Status of a bulk job resp. of one of its namespaces.
+const (
+	bulkPending   = "pending"
+	bulkRunning   = "running"
+	bulkCompleted = "completed"
+	bulkSucceeded = "succeeded"
+	bulkFailed    = "failed"
+)
+
+// bulkSelector selects the namespaces of a bulk operation. All given criteria need to match.
+type bulkSelector struct {
+	Cluster    string   `json:"cluster"`
+	Namespaces []string `json:"namespaces"`
+	// State is the state of Jenkins as last observed by the idler, e.g. "running".
+	State string `json:"state"`
+	// InactiveFor is the minimal duration since the last activity, e.g. "2h".
+	InactiveFor string `json:"inactive_for"`
+}
+
+type bulkRequest struct {
+	Action   string       `json:"action"`
+	Selector bulkSelector `json:"selector"`
+}
+
+type bulkResult struct {
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	Status    string `json:"status"`
+	Error     string `json:"error,omitempty"`
+}
+
+// bulkJob is the state of a bulk operation, as reported by the REST API.
+type bulkJob struct {
+	ID        string       `json:"id"`
+	Action    string       `json:"action"`
+	Status    string       `json:"status"`
+	Created   time.Time    `json:"created"`
+	Finished  *time.Time   `json:"finished,omitempty"`
+	Total     int          `json:"total"`
+	Done      int          `json:"done"`
+	Succeeded int          `json:"succeeded"`
+	Failed    int          `json:"failed"`
+	Results   []bulkResult `json:"results"`
+}
+
+// bulkJobs keeps the running and the most recently finished bulk jobs.
+type bulkJobs struct {
+	lock     sync.Mutex
+	jobs     map[string]*bulkJob
+	finished []string
+}
+
+func newBulkJobs() *bulkJobs {
+	return &bulkJobs{jobs: make(map[string]*bulkJob)}
+}
+
+func (b *bulkJobs) add(job *bulkJob) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.jobs[job.ID] = job
+}
+
+// get returns a copy of the job with the given id.
+func (b *bulkJobs) get(id string) (bulkJob, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	job, ok := b.jobs[id]
+	if !ok {
+		return bulkJob{}, false
+	}
+	snapshot := *job
+	snapshot.Results = append([]bulkResult{}, job.Results...)
+	return snapshot, true
+}
+
+// update applies f to the job with the given id.
+func (b *bulkJobs) update(id string, f func(job *bulkJob)) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	f(b.jobs[id])
+}
+
+// finish marks the job as completed and forgets the oldest finished jobs beyond maxFinishedBulkJobs.
+func (b *bulkJobs) finish(id string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := time.Now().UTC()
+	b.jobs[id].Status = bulkCompleted
+	b.jobs[id].Finished = &now
+
+	b.finished = append(b.finished, id)
+	for len(b.finished) > maxFinishedBulkJobs {
+		delete(b.jobs, b.finished[0])
+		b.finished = b.finished[1:]
+	}
+}
+
+// Bulk starts a bulk idle resp. un-idle of the selected namespaces and responds with the job.
+func (api *idler) Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, err := api.startBulkJob(r)
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, job)
+}
+
+// startBulkJob starts the bulk job requested by the JSON body of the request and returns its initial state.
+func (api *idler) startBulkJob(r *http.Request) (bulkJob, *apiError) {
+	var request bulkRequest
+	if err := decodeBody(r, &request); err != nil {
+		return bulkJob{}, err
+	}
+
+	if request.Action != "idle" && request.Action != "unidle" {
+		return bulkJob{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Action needs to be idle or unidle"))
+	}
+
+	results, err := api.selectNamespaces(request.Selector, time.Now())
+	if err != nil {
+		return bulkJob{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, err)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return bulkJob{}, newAPIError(http.StatusInternalServerError, codeInternalError, err)
+	}
+
+	job := &bulkJob{
+		ID:      id,
+		Action:  request.Action,
+		Status:  bulkRunning,
+		Created: time.Now().UTC(),
+		Total:   len(results),
+		Results: results,
+	}
+	for _, result := range results {
+		if result.Status == bulkFailed {
+			job.Done++
+			job.Failed++
+		}
+	}
+	api.bulkJobs.add(job)
+	snapshot, _ := api.bulkJobs.get(id)
+
+	log.WithFields(log.Fields{"component": "api", "job": id, "action": request.Action, "namespaces": len(results)}).
+		Info("Starting bulk job")
+	go api.runBulkJob(id, request.Action, snapshot.Results)
+	return snapshot, nil
+}
+
+// BulkJob reports the progress and the results of a bulk job.
+func (api *idler) BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, err := api.bulkJob(ps.ByName("id"))
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, job)
+}
+
+func (api *idler) bulkJob(id string) (bulkJob, *apiError) {
+	job, ok := api.bulkJobs.get(id)
+	if !ok {
+		return bulkJob{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No bulk job %s", id))
+	}
+	return job, nil
+}
+
+// selectNamespaces returns a pending result for each namespace matching the selector. Namespaces listed
+// explicitly, with or without the Jenkins namespace suffix, which are not tracked by the idler resp. do not
+// match the other criteria of the selector are reported as failed right away.
+func (api *idler) selectNamespaces(selector bulkSelector, now time.Time) ([]bulkResult, error) {
+	if selector.Cluster == "" && len(selector.Namespaces) == 0 && selector.State == "" && selector.InactiveFor == "" {
+		return nil, fmt.Errorf("Selector needs at least one of cluster, namespaces, state and inactive_for")
+	}
+
+	var inactiveFor time.Duration
+	if selector.InactiveFor != "" {
+		d, err := time.ParseDuration(selector.InactiveFor)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid inactive_for: %s", err)
+		}
+		inactiveFor = d
+	}
+
+	var requested []string
+	for _, ns := range selector.Namespaces {
+		if ns = strings.TrimSpace(ns); ns == "" {
+			continue
+		}
+		if ns = strings.TrimSuffix(ns, jenkinsNamespaceSuffix) + jenkinsNamespaceSuffix; !util.Contains(requested, ns) {
+			requested = append(requested, ns)
+		}
+	}
+
+	results := []bulkResult{}
+	selected := make(map[string]bool)
+	for _, entry := range api.namespaces(selector.Cluster, selector.State) {
+		if len(requested) > 0 && !util.Contains(requested, entry.Namespace) {
+			continue
+		}
+		if inactiveFor > 0 && now.Sub(api.lastActivity(entry.Namespace)) < inactiveFor {
+			continue
+		}
+		results = append(results, bulkResult{Namespace: entry.Namespace, Cluster: entry.Cluster, Status: bulkPending})
+		selected[entry.Namespace] = true
+	}
+
+	for _, ns := range requested {
+		if selected[ns] {
+			continue
+		}
+		result := bulkResult{Namespace: ns, Status: bulkFailed, Error: "namespace does not match the selector"}
+		if _, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix)); !ok {
+			result.Error = "namespace is not tracked"
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// lastActivity returns the time of the last update of Jenkins resp. the last build, whichever is later.
+func (api *idler) lastActivity(ns string) time.Time {
+	userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		return time.Time{}
+	}
+
+	user := userIdler.Summary().User
+	last := user.JenkinsLastUpdate
+	build := user.LastBuild()
+	for _, t := range []time.Time{build.Status.StartTimestamp.Time, build.Status.CompletionTimestamp.Time} {
+		if t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// runBulkJob performs the action on the pending namespaces of the job, with at most the configured number of
+// concurrent requests per cluster.
+func (api *idler) runBulkJob(id string, action string, results []bulkResult) {
+	byCluster := map[string][]int{}
+	for i, result := range results {
+		if result.Status == bulkPending {
+			byCluster[result.Cluster] = append(byCluster[result.Cluster], i)
+		}
+	}
+
+	concurrency := api.config.GetBulkConcurrency()
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var wg sync.WaitGroup
+	for cluster, indices := range byCluster {
+		queue := make(chan int, len(indices))
+		for _, i := range indices {
+			queue <- i
+		}
+		close(queue)
+
+		workers := concurrency
+		if workers > len(indices) {
+			workers = len(indices)
+		}
+		for n := 0; n < workers; n++ {
+			wg.Add(1)
+			go func(cluster string) {
+				defer wg.Done()
+				for i := range queue {
+					api.runBulkAction(id, action, cluster, i, results[i].Namespace)
+				}
+			}(cluster)
+		}
+	}
+	wg.Wait()
+
+	api.bulkJobs.finish(id)
+	log.WithFields(log.Fields{"component": "api", "job": id}).Info("Bulk job completed")
+}
+
+func (api *idler) runBulkAction(id string, action string, cluster string, i int, ns string) {
+	api.bulkJobs.update(id, func(job *bulkJob) {
+		job.Results[i].Status = bulkRunning
+	})
+
+	var err *apiError
+	token, ok := api.clusterView.GetToken(cluster)
+	if !ok {
+		err = newAPIError(http.StatusBadRequest, codeUnknownCluster, fmt.Errorf("Unknown OpenShift API URL: %s", cluster))
+	} else if action == "idle" {
+		err = api.idle(cluster, token, ns)
+	} else {
+		err = api.unIdle(cluster, token, ns)
+	}
+
+	api.bulkJobs.update(id, func(job *bulkJob) {
+		job.Done++
+		if err != nil {
+			job.Failed++
+			job.Results[i].Status = bulkFailed
+			job.Results[i].Error = err.Error()
+			return
+		}
+		job.Succeeded++
+		job.Results[i].Status = bulkSucceeded
+	})
+}
+
+// newJobID returns a random id for a job.
+func newJobID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Bulk(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	for _, u := range []struct{ name, cluster string }{{"alice", "http://cluster-a/"}, {"bob", "http://cluster-a/"}, {"carol", "http://cluster-b/"}} {
+		userIdler := pidler.NewUserIdler(model.NewUser(u.name, u.name), u.cluster, "", "", &mock.Config{},
+			mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+		userIdlers.Store(u.name, userIdler)
+	}
+
+	mosc := &mock.OpenShiftClient{}
+	mockIdler := idler{
+		openShiftClient: mosc,
+		clusterView: cluster.NewView([]cluster.Cluster{
+			{APIURL: "http://cluster-a/", Token: "a"},
+			{APIURL: "http://cluster-b/", Token: "b"},
+		}),
+		userIdlers:    userIdlers,
+		disabledUsers: disabled.NewUsers(store.NewMemoryStore()),
+		config:        &mock.Config{BulkConcurrency: 1},
+		bulkJobs:      newBulkJobs(),
+	}
+
+	for _, body := range []string{
+		`{"action": "delete", "selector": {"cluster": "http://cluster-a/"}}`,
+		`{"action": "idle", "selector": {}}`,
+		`{"action": "idle", "selector": {"inactive_for": "two hours"}}`,
+	} {
+		w := httptest.NewRecorder()
+		mockIdler.Bulk(w, httptest.NewRequest("POST", "/", strings.NewReader(body)), nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, "Invalid request %s should be rejected", body)
+	}
+
+	w := httptest.NewRecorder()
+	body := `{"action": "idle", "selector": {"cluster": "http://cluster-a/", "namespaces": ["alice", "carol-jenkins", "ghost-jenkins", "alice-jenkins"]}}`
+	mockIdler.Bulk(w, httptest.NewRequest("POST", "/", strings.NewReader(body)), nil)
+	require.Equal(t, http.StatusAccepted, w.Code, "Unexpected status code")
+
+	job := bulkJob{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	require.Equal(t, 3, job.Total, "Only alice should be selected, besides the requested namespaces not matching")
+
+	for deadline := time.Now().Add(5 * time.Second); job.Status != bulkCompleted; time.Sleep(10 * time.Millisecond) {
+		require.True(t, time.Now().Before(deadline), "Job should complete")
+		w = httptest.NewRecorder()
+		mockIdler.BulkJob(w, nil, httprouter.Params{{Key: "id", Value: job.ID}})
+		require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	}
+
+	require.Equal(t, 3, job.Done)
+	require.Equal(t, 1, job.Succeeded)
+	require.Equal(t, 2, job.Failed)
+	require.Equal(t, []bulkResult{
+		{Namespace: "alice-jenkins", Cluster: "http://cluster-a/", Status: bulkSucceeded},
+		{Namespace: "carol-jenkins", Status: bulkFailed, Error: "namespace does not match the selector"},
+		{Namespace: "ghost-jenkins", Status: bulkFailed, Error: "namespace is not tracked"},
+	}, job.Results)
+	require.Equal(t, len(pidler.JenkinsServices), mosc.IdleCallCount, "Only alice should have been idled")
+
+	w = httptest.NewRecorder()
+	mockIdler.BulkJob(w, nil, httprouter.Params{{Key: "id", Value: "unknown"}})
+	require.Equal(t, http.StatusNotFound, w.Code, "Unknown job should not be found")
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/julienschmidt/httprouter"
+)
+
+// checkTimeout is how long a forced idle check may take, including the wait for the user idler to be ready.
+var checkTimeout = 2 * time.Minute
+
+type checkResponse struct {
+	Namespace string `json:"namespace"`
+	pidler.CheckReport
+}
+
+// checkRequest is the optional body of a check request of the v2 API.
+type checkRequest struct {
+	DryRun bool `json:"dry_run"`
+}
+
+// Check forces an immediate idle check for a namespace and reports its outcome.
+func (api *idler) Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	dryRun := false
+	if value := r.URL.Query().Get("dryRun"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			respondWithError(w, http.StatusBadRequest, fmt.Errorf("Invalid dryRun %q", value))
+			return
+		}
+	}
+
+	response, err := api.check(r.Context(), strings.TrimSpace(ps.ByName("namespace")), dryRun)
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+func (api *idler) check(ctx context.Context, ns string, dryRun bool) (checkResponse, *apiError) {
+	userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		return checkResponse{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No idler found for namespace %s", ns))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	report, err := userIdler.Check(ctx, dryRun)
+	if err != nil {
+		return checkResponse{}, newAPIError(http.StatusServiceUnavailable, codeUnavailable, fmt.Errorf("Idle check for namespace %s did not complete: %s", ns, err))
+	}
+	return checkResponse{Namespace: ns, CheckReport: report}, nil
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Check(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdler := pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+	userIdlers.Store("foo", userIdler)
+	mockIdler := idler{userIdlers: userIdlers}
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	userIdler.Run(ctx, &wg, cancel, time.Hour, time.Hour)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	tests := []struct {
+		name      string
+		namespace string
+		query     string
+		expected  int
+	}{
+		{"dry run", "foo-jenkins", "?dryRun=true", http.StatusOK},
+		{"check", "foo-jenkins", "", http.StatusOK},
+		{"unknown namespace", "bar-jenkins", "", http.StatusNotFound},
+		{"invalid dry run", "foo-jenkins", "?dryRun=maybe", http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/api/idler/check/"+test.namespace+test.query, nil)
+			mockIdler.Check(w, r, httprouter.Params{{Key: "namespace", Value: test.namespace}})
+			require.Equal(t, test.expected, w.Code, "Unexpected status code")
+			if test.expected != http.StatusOK {
+				return
+			}
+
+			response := map[string]interface{}{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, test.namespace, response["namespace"])
+			assert.Equal(t, test.query != "", response["dry_run"])
+			assert.Equal(t, "foo", response["user"].(map[string]interface{})["Name"])
+			assert.Equal(t, "idler disabled for user", response["evaluation"].(map[string]interface{})["reason"])
+		})
+	}
+}
+
+func Test_Check_times_out(t *testing.T) {
+	defer func(timeout time.Duration) { checkTimeout = timeout }(checkTimeout)
+	checkTimeout = 10 * time.Millisecond
+
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdlers.Store("foo", pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil))
+	mockIdler := idler{userIdlers: userIdlers}
+
+	w := httptest.NewRecorder()
+	mockIdler.Check(w, httptest.NewRequest("POST", "/", nil), httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "Check of a user idler which is not running should time out")
+}
+
+func Test_v2_Check(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdler := pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+	userIdlers.Store("foo", userIdler)
+	v2 := NewIdlerAPIV2(&idler{userIdlers: userIdlers})
+	params := httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}}
+
+	defer func(timeout time.Duration) { checkTimeout = timeout }(checkTimeout)
+	checkTimeout = 10 * time.Millisecond
+	w := httptest.NewRecorder()
+	v2.Check(w, httptest.NewRequest("POST", "/", nil), params)
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, "Check of a user idler which is not running should time out")
+	envelope := errorEnvelope{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	assert.Equal(t, codeUnavailable, envelope.Error)
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	userIdler.Run(ctx, &wg, cancel, time.Hour, time.Hour)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	checkTimeout = time.Minute
+
+	w = httptest.NewRecorder()
+	v2.Check(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"dry_run": true}`)), params)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+	response := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "foo-jenkins", response["namespace"])
+	assert.Equal(t, true, response["dry_run"])
+}
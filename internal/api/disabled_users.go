@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	log "github.com/sirupsen/logrus"
+)
+
+// updateDisabledUsers disables resp. enables idling for the users of the given request. The user names are
+// trimmed and empty ones are skipped. The disable entries record the reason and expiry of the request and
+// the authenticated principal as actor.
+func (api *idler) updateDisabledUsers(r *http.Request, users userStatus) *apiError {
+	now := time.Now().UTC()
+
+	var expires *time.Time
+	if users.ExpiresIn != "" {
+		d, err := time.ParseDuration(users.ExpiresIn)
+		if err != nil || d <= 0 {
+			return newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid expires_in %q, needs to be a positive duration", users.ExpiresIn))
+		}
+		t := now.Add(d)
+		expires = &t
+	}
+
+	actor := ""
+	if principal, ok := auth.PrincipalFromContext(r.Context()); ok {
+		actor = principal.Name
+	}
+
+	disable := normaliseUsers(users.Disable)
+	var entries []disabled.Entry
+	for _, user := range disable {
+		entries = append(entries, disabled.Entry{
+			User:    user,
+			Reason:  strings.TrimSpace(users.Reason),
+			Actor:   actor,
+			Created: now,
+			Expires: expires,
+		})
+	}
+	enable := normaliseUsers(users.Enable)
+
+	// enabled users will take precedence over disabled
+	if err := api.disabledUsers.Update(entries, enable); err != nil {
+		return newAPIError(http.StatusInternalServerError, codeStoreError, fmt.Errorf("Failed to update disabled users: %s", err))
+	}
+	logger := log.WithFields(log.Fields{"component": "api", "actor": actor})
+	if len(disable) > 0 {
+		logger.Infof("Disabled idling for %v: %s", disable, users.Reason)
+	}
+	if len(enable) > 0 {
+		logger.Infof("Enabled idling for %v", enable)
+	}
+	return nil
+}
+
+// normaliseUsers returns the trimmed user names, skipping empty ones.
+func normaliseUsers(users []string) []string {
+	var result []string
+	for _, user := range users {
+		if user = strings.TrimSpace(user); user != "" {
+			result = append(result, user)
+		}
+	}
+	return result
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type opsAuthenticator struct{}
+
+func (a opsAuthenticator) Authenticate(token string) (auth.Principal, error) {
+	return auth.Principal{Name: "ops", Scope: auth.ScopeAdmin}, nil
+}
+
+type failingStore struct{}
+
+func (s failingStore) Load() ([]byte, string, error) {
+	return nil, "", errors.New("config map unavailable")
+}
+
+func (s failingStore) Save(data []byte, version string) error {
+	return errors.New("config map unavailable")
+}
+
+func Test_SetUserIdlerStatus_records_reason_actor_and_expiry(t *testing.T) {
+	mockIdler := idler{disabledUsers: disabled.NewUsers(store.NewMemoryStore())}
+	handler := auth.Authorize(opsAuthenticator{}, auth.ScopeAdmin, mockIdler.SetUserIdlerStatus)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"disable": ["alice"], "reason": "debugging", "expires_in": "2h"}`))
+	r.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	handler(w, r, nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	w = httptest.NewRecorder()
+	mockIdler.GetDisabledUserIdlers(w, httptest.NewRequest("GET", "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	response := idlerStatusResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, []string{"alice"}, response.Users)
+	require.Len(t, response.Entries, 1)
+	entry := response.Entries[0]
+	assert.Equal(t, "debugging", entry.Reason)
+	assert.Equal(t, "ops", entry.Actor)
+	require.NotNil(t, entry.Expires)
+	assert.WithinDuration(t, time.Now().Add(2*time.Hour), *entry.Expires, time.Minute)
+}
+
+func Test_SetUserIdlerStatus_trims_user_names(t *testing.T) {
+	mockIdler := idler{disabledUsers: disabled.NewUsers(store.NewMemoryStore())}
+
+	w := httptest.NewRecorder()
+	mockIdler.SetUserIdlerStatus(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"disable": [" alice ", "bob", ""]}`)), nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+	assert.Equal(t, []string{"alice", "bob"}, mockIdler.disabledUsers.Keys())
+
+	w = httptest.NewRecorder()
+	mockIdler.SetUserIdlerStatus(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"enable": ["alice ", " "]}`)), nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+	assert.Equal(t, []string{"bob"}, mockIdler.disabledUsers.Keys(), "Enabled user should be matched after trimming")
+}
+
+func Test_SetUserIdlerStatus_fail(t *testing.T) {
+	tests := []struct {
+		name     string
+		store    store.Store
+		body     string
+		expected int
+	}{
+		{"invalid expiry", store.NewMemoryStore(), `{"disable": ["alice"], "expires_in": "tomorrow"}`, http.StatusBadRequest},
+		{"negative expiry", store.NewMemoryStore(), `{"disable": ["alice"], "expires_in": "-1h"}`, http.StatusBadRequest},
+		{"store error", failingStore{}, `{"disable": ["alice"]}`, http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockIdler := idler{disabledUsers: disabled.NewUsers(test.store)}
+			w := httptest.NewRecorder()
+			mockIdler.SetUserIdlerStatus(w, httptest.NewRequest("POST", "/", strings.NewReader(test.body)), nil)
+			assert.Equal(t, test.expected, w.Code, "Unexpected status code")
+		})
+	}
+}
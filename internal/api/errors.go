@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Stable error codes reported in the error envelope of the v2 API.
+const (
+	codeInvalidRequest    = "invalid_request"
+	codeUnauthorized      = "unauthorized"
+	codeForbidden         = "forbidden"
+	codeNotFound          = "not_found"
+	codeUnknownCluster    = "unknown_cluster"
+	codeOpenShiftError    = "openshift_error"
+	codeTenantError       = "tenant_error"
+	codeCapacityExhausted = "capacity_exhausted"
+	codeStoreError        = "store_error"
+	codeInternalError     = "internal_error"
+	codeUnavailable       = "unavailable"
+)
+
+// apiError is an error together with the HTTP status and the code it is reported with.
+type apiError struct {
+	status int
+	code   string
+	err    error
+}
+
+func newAPIError(status int, code string, err error) *apiError {
+	return &apiError{status: status, code: code, err: err}
+}
+
+func (e *apiError) Error() string {
+	return e.err.Error()
+}
+
+// errorEnvelope is the body of every error response of the v2 API. It has the same shape as the
+// responses of rejected authentication, e.g. {"error": "not_found", "description": "No idler found for namespace foo"}.
+type errorEnvelope struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+}
+
+func respondWithAPIError(w http.ResponseWriter, err *apiError) {
+	log.WithField("code", err.code).Error(err)
+	writeResponse(w, err.status, errorEnvelope{Error: err.code, Description: err.Error()})
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventsHeartbeat is the interval of the comments written to idle event streams, so that proxies
+// do not close them.
+const eventsHeartbeat = 30 * time.Second
+
+// Events streams the events of the bus as server-sent events.
+func (api *idler) Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := api.streamEvents(w, r); err != nil {
+		respondWithError(w, err.status, err)
+	}
+}
+
+// streamEvents streams the events of the bus until the client disconnects. It returns an error, without
+// writing anything, if the events cannot be streamed.
+func (api *idler) streamEvents(w http.ResponseWriter, r *http.Request) *apiError {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return newAPIError(http.StatusInternalServerError, codeInternalError, errors.New("Streaming is not supported"))
+	}
+	if api.bus == nil {
+		return newAPIError(http.StatusServiceUnavailable, codeUnavailable, errors.New("No events are published"))
+	}
+
+	ns := r.URL.Query().Get("namespace")
+	events, unsubscribe := api.bus.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case event := <-events:
+			if ns != "" && event.Namespace != ns {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				log.WithField("component", "api").Errorf("Could not marshal event: %s", err)
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Events(t *testing.T) {
+	bus := events.NewBus()
+	mockIdler := idler{bus: bus}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mockIdler.Events(w, r, httprouter.Params{})
+	}))
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "?namespace=foo-jenkins")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	// the subscription exists once the headers are sent
+	bus.Publish(events.Event{Type: events.IdleStarted, Namespace: "bar-jenkins"})
+	bus.Publish(events.Event{Type: events.StateChanged, Namespace: "foo-jenkins", Data: events.StateChange{From: "running", To: "idled"}})
+
+	reader := bufio.NewReader(res.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: state_changed\n", line)
+
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "data: "), line)
+	var event struct {
+		Type      string             `json:"type"`
+		Namespace string             `json:"namespace"`
+		Data      events.StateChange `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event))
+	assert.Equal(t, "foo-jenkins", event.Namespace)
+	assert.Equal(t, events.StateChange{From: "running", To: "idled"}, event.Data)
+}
+
+func Test_Events_without_bus(t *testing.T) {
+	w := httptest.NewRecorder()
+	mockIdler := idler{}
+	mockIdler.Events(w, httptest.NewRequest("GET", "/api/idler/events", nil), httprouter.Params{})
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/julienschmidt/httprouter"
+)
+
+// keepAliveRequest is the optional body of a keep-alive request. A lease is taken if the duration is set.
+type keepAliveRequest struct {
+	// Duration is the duration of the lease, e.g. "2h".
+	Duration string `json:"duration"`
+	Reason   string `json:"reason"`
+}
+
+type leaseEntry struct {
+	Namespace string `json:"namespace"`
+	pidler.Lease
+}
+
+type leasesResponse struct {
+	Leases []leaseEntry `json:"leases"`
+}
+
+type unpinResponse struct {
+	Revoked bool `json:"revoked"`
+}
+
+// Unpin revokes the keep-alive lease of a namespace.
+func (api *idler) Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := api.unpin(strings.TrimSpace(ps.ByName("namespace")))
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+func (api *idler) unpin(ns string) (unpinResponse, *apiError) {
+	userIdler, ok := api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		return unpinResponse{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No idler found for namespace %s", ns))
+	}
+
+	revoked, err := userIdler.Unpin()
+	if err != nil {
+		return unpinResponse{}, newAPIError(http.StatusInternalServerError, codeStoreError, fmt.Errorf("Failed to revoke lease: %s", err))
+	}
+	if !revoked {
+		return unpinResponse{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No active lease for namespace %s", ns))
+	}
+	return unpinResponse{Revoked: true}, nil
+}
+
+// Leases lists the active keep-alive leases by namespace.
+func (api *idler) Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeResponse(w, http.StatusOK, api.leases())
+}
+
+func (api *idler) leases() leasesResponse {
+	response := leasesResponse{Leases: []leaseEntry{}}
+	api.userIdlers.Range(func(name string, userIdler *pidler.UserIdler) bool {
+		if lease, ok := userIdler.Lease(); ok {
+			response.Leases = append(response.Leases, leaseEntry{Namespace: name + jenkinsNamespaceSuffix, Lease: lease})
+		}
+		return true
+	})
+	sort.Slice(response.Leases, func(i, j int) bool {
+		return response.Leases[i].Namespace < response.Leases[j].Namespace
+	})
+	return response
+}
+
+// leaseDuration validates the requested lease and returns its duration, zero if no lease is requested.
+func (api *idler) leaseDuration(request keepAliveRequest) (time.Duration, *apiError) {
+	if request.Duration == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(request.Duration)
+	if err != nil {
+		return 0, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid duration: %s", err))
+	}
+	max := time.Duration(api.config.GetMaxKeepAlive()) * time.Minute
+	if d <= 0 || d > max {
+		return 0, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Duration needs to be positive and at most %s", max))
+	}
+	if strings.TrimSpace(request.Reason) == "" {
+		return 0, newAPIError(http.StatusBadRequest, codeInvalidRequest, errors.New("A lease needs a reason"))
+	}
+	return d, nil
+}
+
+// decodeOptionalBody decodes the JSON body of the request into v, if there is one.
+func decodeOptionalBody(r *http.Request, v interface{}) *apiError {
+	if r.Body == nil {
+		return nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil && err != io.EOF {
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid request body: %s", err))
+	}
+	return nil
+}
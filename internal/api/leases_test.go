@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Leases(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	for _, name := range []string{"foo", "bar"} {
+		userIdlers.Store(name, pidler.NewUserIdler(model.NewUser("42", name), "", "", "", &mock.Config{},
+			mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil))
+	}
+	mockIdler := idler{userIdlers: userIdlers, config: &mock.Config{MaxKeepAlive: 120}}
+	params := httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}}
+
+	for _, body := range []string{
+		`{"duration": "forever", "reason": "release"}`,
+		`{"duration": "3h", "reason": "release"}`,
+		`{"duration": "-1h", "reason": "release"}`,
+		`{"duration": "1h"}`,
+		`{`,
+	} {
+		w := httptest.NewRecorder()
+		mockIdler.KeepAlive(w, httptest.NewRequest("POST", "/", strings.NewReader(body)), params)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+
+	w := httptest.NewRecorder()
+	mockIdler.KeepAlive(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"duration": "1h", "reason": "release"}`)), params)
+	require.Equal(t, http.StatusOK, w.Code)
+	pinned := keepAliveResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &pinned))
+	require.NotNil(t, pinned.Lease)
+	assert.Equal(t, "release", pinned.Lease.Reason)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), pinned.Lease.Expires, time.Minute)
+
+	w = httptest.NewRecorder()
+	mockIdler.Leases(w, httptest.NewRequest("GET", "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	leases := leasesResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &leases))
+	require.Len(t, leases.Leases, 1)
+	assert.Equal(t, "foo-jenkins", leases.Leases[0].Namespace)
+	assert.Equal(t, "release", leases.Leases[0].Reason)
+
+	w = httptest.NewRecorder()
+	mockIdler.Unpin(w, httptest.NewRequest("DELETE", "/", nil), params)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	mockIdler.Unpin(w, httptest.NewRequest("DELETE", "/", nil), params)
+	assert.Equal(t, http.StatusNotFound, w.Code, "Revoked lease should not be found")
+
+	w = httptest.NewRecorder()
+	mockIdler.Unpin(w, httptest.NewRequest("DELETE", "/", nil), httprouter.Params{{Key: "namespace", Value: "baz-jenkins"}})
+	assert.Equal(t, http.StatusNotFound, w.Code, "Unknown namespace should not be found")
+}
+
+func Test_Status_shows_lease(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdler := pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+	userIdler.Pin(time.Hour, "release")
+	userIdlers.Store("foo", userIdler)
+	mockIdler := idler{
+		openShiftClient: &mock.OpenShiftClient{IdleState: model.PodRunning},
+		clusterView:     &mock.ClusterView{},
+		userIdlers:      userIdlers,
+	}
+
+	w := httptest.NewRecorder()
+	mockIdler.Status(w, httptest.NewRequest("GET", "/?"+OpenShiftAPIParam+"=http://localhost", nil),
+		httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	status := statusResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &status))
+	require.NotNil(t, status.Data.Lease)
+	assert.Equal(t, "release", status.Data.Lease.Reason)
+}
+
+func Test_v2_leases(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	userIdlers.Store("foo", pidler.NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil))
+	v2 := NewIdlerAPIV2(&idler{userIdlers: userIdlers, config: &mock.Config{MaxKeepAlive: 120}})
+	params := httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}}
+
+	w := httptest.NewRecorder()
+	v2.KeepAlive(w, httptest.NewRequest("POST", "/", strings.NewReader(`{"duration": "1h", "reason": "release"}`)), params)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	v2.Leases(w, httptest.NewRequest("GET", "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	leases := leasesResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &leases))
+	require.Len(t, leases.Leases, 1)
+	assert.Equal(t, "foo-jenkins", leases.Leases[0].Namespace)
+
+	w = httptest.NewRecorder()
+	v2.Unpin(w, httptest.NewRequest("POST", "/", nil), params)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"revoked": true}`, w.Body.String())
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/julienschmidt/httprouter"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 500
+)
+
+type buildEntry struct {
+	Name      string     `json:"name"`
+	Phase     string     `json:"phase"`
+	Started   *time.Time `json:"started,omitempty"`
+	Completed *time.Time `json:"completed,omitempty"`
+}
+
+type idleStatusEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Success   bool      `json:"success"`
+	Reason    string    `json:"reason"`
+}
+
+type namespaceEntry struct {
+	Namespace       string           `json:"namespace"`
+	Cluster         string           `json:"cluster"`
+	UserID          string           `json:"user_id"`
+	State           string           `json:"state"`
+	StateObservedAt *time.Time       `json:"state_observed_at,omitempty"`
+	LastBuild       *buildEntry      `json:"last_build,omitempty"`
+	IdleStatus      *idleStatusEntry `json:"idle_status,omitempty"`
+	Disabled        bool             `json:"disabled"`
+	NextCheck       *time.Time       `json:"next_check,omitempty"`
+}
+
+type namespacesResponse struct {
+	Namespaces []namespaceEntry `json:"namespaces"`
+	Total      int              `json:"total"`
+	Page       int              `json:"page"`
+	PerPage    int              `json:"per_page"`
+}
+
+// namespaceLess compares two entries by the field named by the sort parameter.
+var namespaceLess = map[string]func(a, b namespaceEntry) bool{
+	"namespace": func(a, b namespaceEntry) bool { return a.Namespace < b.Namespace },
+	"cluster":   func(a, b namespaceEntry) bool { return a.Cluster < b.Cluster },
+	"user_id":   func(a, b namespaceEntry) bool { return a.UserID < b.UserID },
+	"state":     func(a, b namespaceEntry) bool { return a.State < b.State },
+	"next_check": func(a, b namespaceEntry) bool {
+		return timeOrZero(a.NextCheck).Before(timeOrZero(b.NextCheck))
+	},
+	"last_build": func(a, b namespaceEntry) bool {
+		return timeOrZero(startedOf(a.LastBuild)).Before(timeOrZero(startedOf(b.LastBuild)))
+	},
+}
+
+// Namespaces lists the namespaces tracked by the user idlers.
+func (api *idler) Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := api.namespacesPage(r.URL.Query())
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+// namespacesPage returns the page of tracked namespaces selected by the filter, sort and pagination parameters.
+func (api *idler) namespacesPage(query url.Values) (namespacesResponse, *apiError) {
+	sortBy := query.Get("sort")
+	descending := strings.HasPrefix(sortBy, "-")
+	sortBy = strings.TrimPrefix(sortBy, "-")
+	if sortBy == "" {
+		sortBy = "namespace"
+	}
+	less, ok := namespaceLess[sortBy]
+	if !ok {
+		return namespacesResponse{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Unknown sort field %s", sortBy))
+	}
+
+	page, err := positiveParam(query.Get("page"), 1)
+	if err != nil {
+		return namespacesResponse{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid page: %s", err))
+	}
+	perPage, err := positiveParam(query.Get("per_page"), defaultPerPage)
+	if err != nil || perPage > maxPerPage {
+		return namespacesResponse{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("per_page needs to be between 1 and %d", maxPerPage))
+	}
+
+	entries := api.namespaces(query.Get("cluster"), query.Get("state"))
+	sort.SliceStable(entries, func(i, j int) bool {
+		if descending {
+			return less(entries[j], entries[i])
+		}
+		return less(entries[i], entries[j])
+	})
+
+	response := namespacesResponse{Namespaces: []namespaceEntry{}, Total: len(entries), Page: page, PerPage: perPage}
+	if start := (page - 1) * perPage; start < len(entries) {
+		end := start + perPage
+		if end > len(entries) {
+			end = len(entries)
+		}
+		response.Namespaces = entries[start:end]
+	}
+	return response, nil
+}
+
+// namespaces returns the entries of all tracked namespaces on the given cluster in the given state.
+// Empty filters match everything.
+func (api *idler) namespaces(cluster string, state string) []namespaceEntry {
+	var entries []namespaceEntry
+	api.userIdlers.Range(func(name string, userIdler *pidler.UserIdler) bool {
+		entry := newNamespaceEntry(name+jenkinsNamespaceSuffix, userIdler.Summary(), api.disabledUsers.Has(name))
+		if (cluster == "" || entry.Cluster == cluster) && (state == "" || entry.State == state) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	return entries
+}
+
+func newNamespaceEntry(ns string, summary pidler.Summary, disabled bool) namespaceEntry {
+	entry := namespaceEntry{
+		Namespace: ns,
+		Cluster:   summary.Cluster,
+		UserID:    summary.User.ID,
+		State:     summary.State.String(),
+		Disabled:  disabled,
+	}
+	if !summary.StateObservedAt.IsZero() {
+		entry.StateObservedAt = &summary.StateObservedAt
+	}
+	if !summary.NextCheck.IsZero() {
+		entry.NextCheck = &summary.NextCheck
+	}
+	if status := summary.User.IdleStatus; !status.Timestamp.IsZero() {
+		entry.IdleStatus = &idleStatusEntry{Timestamp: status.Timestamp, Success: status.Success, Reason: status.Reason}
+	}
+	if build := summary.User.LastBuild(); build.Metadata.Name != "" {
+		entry.LastBuild = newBuildEntry(build)
+	}
+	return entry
+}
+
+func newBuildEntry(build model.Build) *buildEntry {
+	entry := &buildEntry{Name: build.Metadata.Name, Phase: build.Status.Phase}
+	if started := build.Status.StartTimestamp.Time; !started.IsZero() {
+		entry.Started = &started
+	}
+	if completed := build.Status.CompletionTimestamp.Time; !completed.IsZero() {
+		entry.Completed = &completed
+	}
+	return entry
+}
+
+func startedOf(build *buildEntry) *time.Time {
+	if build == nil {
+		return nil
+	}
+	return build.Started
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// positiveParam parses the given query parameter value, returning def if it is empty.
+func positiveParam(value string, def int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if i < 1 {
+		return 0, fmt.Errorf("%d is not positive", i)
+	}
+	return i, nil
+}
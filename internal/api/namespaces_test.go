@@ -0,0 +1,70 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Namespaces(t *testing.T) {
+	userIdlers := openshift.NewUserIdlerMap()
+	for i, name := range []string{"carol", "alice", "bob"} {
+		cluster := "http://cluster-a/"
+		if name == "bob" {
+			cluster = "http://cluster-b/"
+		}
+		userIdler := pidler.NewUserIdler(model.NewUser(string('1'+rune(i)), name), cluster, "", "", &mock.Config{},
+			mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+		userIdlers.Store(name, userIdler)
+	}
+	disabledUsers := disabled.NewUsers(store.NewMemoryStore())
+	disabledUsers.Update([]disabled.Entry{{User: "alice"}}, nil)
+	mockIdler := idler{userIdlers: userIdlers, disabledUsers: disabledUsers}
+
+	tests := []struct {
+		query      string
+		status     int
+		namespaces []string
+		total      int
+	}{
+		{"", http.StatusOK, []string{"alice-jenkins", "bob-jenkins", "carol-jenkins"}, 3},
+		{"?sort=-namespace", http.StatusOK, []string{"carol-jenkins", "bob-jenkins", "alice-jenkins"}, 3},
+		{"?sort=user_id", http.StatusOK, []string{"carol-jenkins", "alice-jenkins", "bob-jenkins"}, 3},
+		{"?cluster=http://cluster-a/", http.StatusOK, []string{"alice-jenkins", "carol-jenkins"}, 2},
+		{"?state=unknown&per_page=2&page=2", http.StatusOK, []string{"carol-jenkins"}, 3},
+		{"?state=running", http.StatusOK, []string{}, 0},
+		{"?page=5", http.StatusOK, []string{}, 3},
+		{"?sort=foo", http.StatusBadRequest, nil, 0},
+		{"?page=0", http.StatusBadRequest, nil, 0},
+		{"?per_page=10000", http.StatusBadRequest, nil, 0},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		mockIdler.Namespaces(w, httptest.NewRequest("GET", "/api/idler/namespaces"+test.query, nil), nil)
+		require.Equal(t, test.status, w.Code, "Unexpected status code for %s", test.query)
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		response := namespacesResponse{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Equal(t, test.total, response.Total, "Unexpected total for %s", test.query)
+
+		namespaces := []string{}
+		for _, entry := range response.Namespaces {
+			namespaces = append(namespaces, entry.Namespace)
+			require.Equal(t, entry.Namespace == "alice-jenkins", entry.Disabled, "Unexpected disabled flag of %s", entry.Namespace)
+		}
+		require.Equal(t, test.namespaces, namespaces, "Unexpected namespaces for %s", test.query)
+	}
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/julienschmidt/httprouter"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxFinishedOperations limits how many finished operations are kept for their results to be queried.
+	maxFinishedOperations = 100
+	// maxOperationWait is the longest time a request for an operation waits for the operation to finish.
+	maxOperationWait = 5 * time.Minute
+)
+
+// Status of an operation.
+const (
+	operationRunning   = "running"
+	operationSucceeded = "succeeded"
+	operationFailed    = "failed"
+)
+
+// operationPollInterval is the interval in which the state of Jenkins is checked while it is un-idling.
+var operationPollInterval = 5 * time.Second
+
+// operation is the state of an asynchronous un-idle, as reported by the REST API.
+type operation struct {
+	ID        string `json:"id"`
+	Action    string `json:"action"`
+	Namespace string `json:"namespace"`
+	Cluster   string `json:"cluster"`
+	Status    string `json:"status"`
+	// State is the state of Jenkins as last observed by the operation.
+	State    string     `json:"state"`
+	Created  time.Time  `json:"created"`
+	Deadline time.Time  `json:"deadline"`
+	Finished *time.Time `json:"finished,omitempty"`
+	// Elapsed is the time in seconds from the request until the operation finished, resp. until now.
+	Elapsed float64 `json:"elapsed_seconds"`
+	Error   string  `json:"error,omitempty"`
+}
+
+// operations keeps the running and the most recently finished operations.
+type operations struct {
+	lock     sync.Mutex
+	ops      map[string]*operation
+	done     map[string]chan struct{}
+	finished []string
+	// running maps the namespaces to the id of their running operation.
+	running map[string]string
+}
+
+func newOperations() *operations {
+	return &operations{
+		ops:     make(map[string]*operation),
+		done:    make(map[string]chan struct{}),
+		running: make(map[string]string),
+	}
+}
+
+// start adds the given operation unless an operation is already running for its namespace. It returns a copy
+// of the added resp. the running operation and whether the given operation was added.
+func (o *operations) start(op *operation) (operation, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if id, ok := o.running[op.Namespace]; ok {
+		return o.ops[id].snapshot(), false
+	}
+	o.ops[op.ID] = op
+	o.done[op.ID] = make(chan struct{})
+	o.running[op.Namespace] = op.ID
+	return op.snapshot(), true
+}
+
+// get returns a copy of the operation with the given id.
+func (o *operations) get(id string) (operation, bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	op, ok := o.ops[id]
+	if !ok {
+		return operation{}, false
+	}
+	return op.snapshot(), true
+}
+
+// snapshot returns a copy of the operation with the elapsed time filled in.
+func (op *operation) snapshot() operation {
+	snapshot := *op
+	end := time.Now().UTC()
+	if op.Finished != nil {
+		end = *op.Finished
+	}
+	snapshot.Elapsed = end.Sub(op.Created).Seconds()
+	return snapshot
+}
+
+// wait waits at most d for the operation with the given id to finish and returns a copy of it.
+func (o *operations) wait(ctx context.Context, id string, d time.Duration) (operation, bool) {
+	o.lock.Lock()
+	done, ok := o.done[id]
+	o.lock.Unlock()
+	if !ok {
+		return o.get(id)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	return o.get(id)
+}
+
+// observe records the observed state of Jenkins in the operation with the given id.
+func (o *operations) observe(id string, state model.PodState) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.ops[id].State = state.String()
+}
+
+// finish marks the operation as succeeded if err is nil, as failed otherwise, and forgets the oldest finished
+// operations beyond maxFinishedOperations.
+func (o *operations) finish(id string, err error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	now := time.Now().UTC()
+	op := o.ops[id]
+	op.Finished = &now
+	op.Status = operationSucceeded
+	if err != nil {
+		op.Status = operationFailed
+		op.Error = err.Error()
+	}
+	close(o.done[id])
+	delete(o.done, id)
+	if o.running[op.Namespace] == id {
+		delete(o.running, op.Namespace)
+	}
+
+	o.finished = append(o.finished, id)
+	for len(o.finished) > maxFinishedOperations {
+		delete(o.ops, o.finished[0])
+		o.finished = o.finished[1:]
+	}
+}
+
+// Operation reports the state of an operation, waiting for it to finish for the duration given in the wait
+// query parameter.
+func (api *idler) Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	op, err := api.operation(r, ps.ByName("id"))
+	if err != nil {
+		respondWithError(w, err.status, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, op)
+}
+
+func (api *idler) operation(r *http.Request, id string) (operation, *apiError) {
+	var wait time.Duration
+	if value := r.URL.Query().Get("wait"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return operation{}, newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid wait: %s", value))
+		}
+		wait = d
+	}
+	if wait > maxOperationWait {
+		wait = maxOperationWait
+	}
+
+	op, ok := api.operations.wait(r.Context(), id, wait)
+	if !ok {
+		return operation{}, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No operation %s", id))
+	}
+	return op, nil
+}
+
+// startUnIdleOperation un-idles Jenkins in the given namespace and tracks the un-idle as operation until Jenkins
+// is running, the rollout failed or the configured un-idle timeout has passed. If an un-idle operation is
+// already running for the namespace, that one is returned instead. If the un-idle fails right away, the
+// operation is marked as failed and the error is returned.
+func (api *idler) startUnIdleOperation(openshiftURL, openshiftToken, ns string) (operation, *apiError) {
+	id, err := newJobID()
+	if err != nil {
+		return operation{}, newAPIError(http.StatusInternalServerError, codeInternalError, err)
+	}
+
+	created := time.Now().UTC()
+	op, started := api.operations.start(&operation{
+		ID:        id,
+		Action:    "unidle",
+		Namespace: ns,
+		Cluster:   openshiftURL,
+		Status:    operationRunning,
+		State:     model.PodState(model.PodStateUnknown).String(),
+		Created:   created,
+		Deadline:  created.Add(time.Duration(api.config.GetUnIdleTimeout()) * time.Minute),
+	})
+	if !started {
+		return op, nil
+	}
+
+	if err := api.unIdle(openshiftURL, openshiftToken, ns); err != nil {
+		api.operations.finish(op.ID, err)
+		return operation{}, err
+	}
+
+	go api.watchUnIdle(op, openshiftToken)
+	return op, nil
+}
+
+// watchUnIdle polls the deployment config of Jenkins until Jenkins is running, the rollout failed or the
+// deadline of the operation has passed.
+func (api *idler) watchUnIdle(op operation, openshiftToken string) {
+	logger := log.WithFields(log.Fields{"component": "api", "operation": op.ID, "ns": op.Namespace})
+	var lastErr error
+	for {
+		dc, err := api.openShiftClient.DeploymentConfig(op.Cluster, openshiftToken, op.Namespace, "jenkins")
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = nil
+			state := dc.State()
+			api.operations.observe(op.ID, state)
+			if state == model.PodRunning {
+				api.operations.finish(op.ID, nil)
+				logger.Info("Jenkins is running, un-idle operation succeeded")
+				return
+			}
+			if reason, failed := dc.RolloutFailed(); failed {
+				err := fmt.Errorf("Rollout of Jenkins failed: %s", reason)
+				api.operations.finish(op.ID, err)
+				logger.Warn(err)
+				return
+			}
+		}
+
+		if time.Now().After(op.Deadline) {
+			err := fmt.Errorf("Jenkins did not become ready until %s", op.Deadline.Format(time.RFC3339))
+			if lastErr != nil {
+				err = fmt.Errorf("%s, last error: %s", err, lastErr)
+			}
+			api.operations.finish(op.ID, err)
+			logger.Warn(err)
+			return
+		}
+		time.Sleep(operationPollInterval)
+	}
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UnIdle_is_tracked_as_operation(t *testing.T) {
+	mockIdler := &idler{
+		openShiftClient: &mock.OpenShiftClient{IdleState: model.PodRunning},
+		clusterView:     cluster.NewView([]cluster.Cluster{{APIURL: "http://localhost/", Token: "secret"}}),
+		tenantService:   &mock.TenantService{},
+		userIdlers:      openshift.NewUserIdlerMap(),
+		config:          &mock.Config{UnIdleTimeout: 1},
+		operations:      newOperations(),
+	}
+	v2 := NewIdlerAPIV2(mockIdler)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v2/jenkins/foo-jenkins/unidle", strings.NewReader(`{"cluster": "http://localhost/"}`))
+	v2.UnIdle(w, req, httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+	require.Equal(t, http.StatusAccepted, w.Code)
+
+	var started operation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	assert.Equal(t, "/api/v2/operations/"+started.ID, w.Header().Get("Location"))
+	assert.Equal(t, "unidle", started.Action)
+	assert.Equal(t, "foo-jenkins", started.Namespace)
+	assert.Equal(t, "http://localhost/", started.Cluster)
+	assert.WithinDuration(t, started.Created.Add(time.Minute), started.Deadline, time.Second)
+
+	w = httptest.NewRecorder()
+	v2.Operation(w, httptest.NewRequest("GET", "/api/v2/operations/"+started.ID+"?wait=5s", nil),
+		httprouter.Params{{Key: "id", Value: started.ID}})
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var finished operation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &finished))
+	assert.Equal(t, operationSucceeded, finished.Status)
+	assert.Equal(t, "running", finished.State)
+	require.NotNil(t, finished.Finished)
+	assert.Empty(t, finished.Error)
+}
+
+func Test_running_operation_is_reused(t *testing.T) {
+	ops := newOperations()
+
+	first, started := ops.start(&operation{ID: "42", Namespace: "foo-jenkins", Status: operationRunning})
+	require.True(t, started)
+	assert.Equal(t, "42", first.ID)
+
+	again, started := ops.start(&operation{ID: "43", Namespace: "foo-jenkins", Status: operationRunning})
+	assert.False(t, started, "Un-idle of a namespace with a running operation should not start a new one")
+	assert.Equal(t, "42", again.ID)
+
+	other, started := ops.start(&operation{ID: "44", Namespace: "bar-jenkins", Status: operationRunning})
+	assert.True(t, started)
+	assert.Equal(t, "44", other.ID)
+
+	ops.finish("42", nil)
+	next, started := ops.start(&operation{ID: "45", Namespace: "foo-jenkins", Status: operationRunning})
+	assert.True(t, started, "A finished operation should not be reused")
+	assert.Equal(t, "45", next.ID)
+}
+
+func Test_UnIdle_operation_fails_if_unidle_fails(t *testing.T) {
+	mockIdler := idler{
+		openShiftClient: &mock.OpenShiftClient{IdleError: "boom"},
+		userIdlers:      openshift.NewUserIdlerMap(),
+		config:          &mock.Config{UnIdleTimeout: 1},
+		operations:      newOperations(),
+	}
+
+	_, err := mockIdler.startUnIdleOperation("http://localhost/", "secret", "foo-jenkins")
+	require.NotNil(t, err)
+
+	require.Len(t, mockIdler.operations.finished, 1)
+	failed, _ := mockIdler.operations.get(mockIdler.operations.finished[0])
+	assert.Equal(t, operationFailed, failed.Status)
+	assert.Contains(t, failed.Error, "boom")
+}
+
+func Test_UnIdle_operation_fails_after_deadline(t *testing.T) {
+	mockIdler := idler{
+		openShiftClient: &mock.OpenShiftClient{IdleState: model.PodIdled},
+		operations:      newOperations(),
+	}
+	op := &operation{ID: "42", Namespace: "foo-jenkins", Status: operationRunning, Created: time.Now().UTC(), Deadline: time.Now().UTC()}
+	mockIdler.operations.start(op)
+
+	// the operation is still running, so the wait times out
+	w := httptest.NewRecorder()
+	mockIdler.Operation(w, httptest.NewRequest("GET", "/api/idler/operations/42?wait=10ms", nil), httprouter.Params{{Key: "id", Value: "42"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	running, _ := mockIdler.operations.get("42")
+	assert.Equal(t, operationRunning, running.Status)
+
+	mockIdler.watchUnIdle(*op, "")
+	failed, _ := mockIdler.operations.get("42")
+	assert.Equal(t, operationFailed, failed.Status)
+	assert.Equal(t, "idled", failed.State)
+	assert.Contains(t, failed.Error, "Jenkins did not become ready")
+}
+
+func Test_UnIdle_operation_fails_on_failed_rollout(t *testing.T) {
+	mockIdler := idler{
+		openShiftClient: &mock.OpenShiftClient{IdleState: model.PodStarting, RolloutFailure: "replication controller jenkins-2 has failed progressing"},
+		operations:      newOperations(),
+	}
+	op := &operation{ID: "42", Namespace: "foo-jenkins", Status: operationRunning, Created: time.Now().UTC(), Deadline: time.Now().UTC().Add(time.Hour)}
+	mockIdler.operations.start(op)
+
+	mockIdler.watchUnIdle(*op, "")
+	failed, _ := mockIdler.operations.get("42")
+	assert.Equal(t, operationFailed, failed.Status)
+	assert.Equal(t, "starting", failed.State)
+	assert.Contains(t, failed.Error, "has failed progressing")
+}
+
+func Test_Operation_fail(t *testing.T) {
+	mockIdler := idler{operations: newOperations()}
+	mockIdler.operations.start(&operation{ID: "42"})
+
+	tests := []struct {
+		path   string
+		id     string
+		status int
+	}{
+		{"/api/idler/operations/43", "43", http.StatusNotFound},
+		{"/api/idler/operations/42?wait=forever", "42", http.StatusBadRequest},
+		{"/api/idler/operations/42?wait=-1s", "42", http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		mockIdler.Operation(w, httptest.NewRequest("GET", test.path, nil), httprouter.Params{{Key: "id", Value: test.id}})
+		assert.Equal(t, test.status, w.Code, test.path)
+	}
+}
@@ -0,0 +1,345 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	pidler "github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/julienschmidt/httprouter"
+)
+
+// ClusterParam is the query parameter resp. the field of the request body under which the OpenShift cluster
+// API URL is passed to the v2 API.
+const ClusterParam = "cluster"
+
+// IdlerAPIV2 defines version 2 of the REST endpoints of the Idler. State is only changed by POST and PATCH
+// requests, which take JSON bodies. Every response is JSON; errors are reported with HTTP status and the
+// envelope {"error": "<code>", "description": "<message>"}, where code is one of a fixed set of codes.
+type IdlerAPIV2 interface {
+	// Idle idles the Jenkins service in the namespace parameter of the request on the cluster given in the body.
+	Idle(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// UnIdle un-idles the Jenkins service in the namespace parameter of the request on the cluster given in the body
+	// and responds with HTTP status 202 and the un-idle operation, which is also referenced by the Location header.
+	// If an un-idle operation is already running for the namespace, that one is returned.
+	UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Reset deletes the Jenkins pod in the namespace parameter of the request so that a new one is started.
+	Reset(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// KeepAlive cancels a pending idle of the Jenkins service in the namespace parameter of the request and
+	// takes a lease if the body contains a duration and a reason.
+	KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Unpin revokes the keep-alive lease of the Jenkins service in the namespace parameter of the request.
+	Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Leases lists the active keep-alive leases.
+	Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Status returns the state of the Jenkins service in the namespace parameter of the request on the cluster
+	// given in the cluster query parameter.
+	Status(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Check runs an idle check for the namespace parameter of the request right away, as IdlerAPI.Check. With
+	// {"dry_run": true} as body the action is only determined, not taken, with the side effects of evaluating
+	// the conditions described there.
+	Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Explain explains the most recent idling decision for the namespace parameter of the request.
+	Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Clusters returns the API URL and the application DNS of all clusters.
+	Clusters(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// DisabledUsers returns the users idling is disabled for.
+	DisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// UpdateDisabledUsers disables resp. enables idling for the users given in the body.
+	UpdateDisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// DryRunReport reports the decisions of the user idlers running in dry-run mode.
+	DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// SelfService lets end users idle, un-idle, reset or pin their own Jenkins, authenticated with their
+	// own OSIO token.
+	SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Namespaces lists the namespaces tracked by the Idler, with the query parameters of IdlerAPI.Namespaces.
+	Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Bulk starts an asynchronous idle resp. un-idle of all namespaces matching the selector in the body and
+	// responds with HTTP status 202 and the job, as IdlerAPI.Bulk.
+	Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// BulkJob reports the progress and the results of the bulk job specified in the id parameter of the request.
+	BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Events streams the decisions of the user idlers and the Jenkins state changes as server-sent events,
+	// as IdlerAPI.Events.
+	Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+
+	// Operation reports the un-idle operation specified in the id parameter of the request, as IdlerAPI.Operation.
+	Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params)
+}
+
+type idlerV2 struct {
+	api *idler
+}
+
+// NewIdlerAPIV2 creates a new instance of IdlerAPIV2 on top of the given IdlerAPI created by NewIdlerAPI, so that
+// both versions share the bulk jobs, the operations and the metrics.
+func NewIdlerAPIV2(v1 IdlerAPI) IdlerAPIV2 {
+	return &idlerV2{api: v1.(*idler)}
+}
+
+type clusterRequest struct {
+	Cluster string `json:"cluster"`
+}
+
+type actionResponse struct {
+	Namespace string `json:"namespace"`
+	Action    string `json:"action"`
+}
+
+type jenkinsStatus struct {
+	Namespace   string              `json:"namespace"`
+	State       string              `json:"state"`
+	PendingIdle *pidler.PendingIdle `json:"pending_idle,omitempty"`
+	Lease       *pidler.Lease       `json:"lease,omitempty"`
+}
+
+type clustersResponse struct {
+	Clusters []cluster.DNSView `json:"clusters"`
+}
+
+type disabledUsersResponse struct {
+	Users   []string         `json:"users"`
+	Entries []disabled.Entry `json:"entries"`
+}
+
+func (v *idlerV2) Idle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	openShiftAPI, openShiftBearerToken, err := v.clusterFromBody(r)
+	if err == nil {
+		err = v.api.idle(openShiftAPI, openShiftBearerToken, ns)
+	}
+	v.respond(w, actionResponse{Namespace: ns, Action: "idle"}, err)
+}
+
+func (v *idlerV2) UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	openShiftAPI, openShiftBearerToken, err := v.clusterFromBody(r)
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	op, err := v.api.startUnIdleOperation(openShiftAPI, openShiftBearerToken, ns)
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v2/operations/"+op.ID)
+	writeResponse(w, http.StatusAccepted, op)
+}
+
+func (v *idlerV2) Reset(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	openShiftAPI, openShiftBearerToken, err := v.clusterFromBody(r)
+	if err == nil {
+		err = v.api.reset(openShiftAPI, openShiftBearerToken, ns)
+	}
+	v.respond(w, actionResponse{Namespace: ns, Action: "reset"}, err)
+}
+
+func (v *idlerV2) KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := v.api.keepAlive(r, ps.ByName("namespace"))
+	v.respond(w, response, err)
+}
+
+func (v *idlerV2) Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := v.api.unpin(ps.ByName("namespace"))
+	v.respond(w, response, err)
+}
+
+func (v *idlerV2) Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeResponse(w, http.StatusOK, v.api.leases())
+}
+
+func (v *idlerV2) Status(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	openShiftAPI, openShiftBearerToken, err := v.clusterToken(r.URL.Query().Get(ClusterParam))
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	state, stateErr := v.api.openShiftClient.State(openShiftAPI, openShiftBearerToken, ns, "jenkins")
+	if stateErr != nil {
+		respondWithAPIError(w, newAPIError(http.StatusInternalServerError, codeOpenShiftError, stateErr))
+		return
+	}
+
+	status := jenkinsStatus{Namespace: ns, State: state.String()}
+	if userIdler, ok := v.api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix)); ok {
+		if pending, ok := userIdler.PendingIdle(); ok {
+			status.PendingIdle = &pending
+		}
+		if lease, ok := userIdler.Lease(); ok {
+			status.Lease = &lease
+		}
+	}
+	writeResponse(w, http.StatusOK, status)
+}
+
+func (v *idlerV2) Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var request checkRequest
+	if err := decodeOptionalBody(r, &request); err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+	response, err := v.api.check(r.Context(), ps.ByName("namespace"), request.DryRun)
+	v.respond(w, response, err)
+}
+
+func (v *idlerV2) Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	userIdler, ok := v.api.userIdlers.Load(strings.TrimSuffix(ns, jenkinsNamespaceSuffix))
+	if !ok {
+		respondWithAPIError(w, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No idler found for namespace %s", ns)))
+		return
+	}
+
+	explanation, ok := userIdler.Explain()
+	if !ok {
+		respondWithAPIError(w, newAPIError(http.StatusNotFound, codeNotFound, fmt.Errorf("No decision taken yet for namespace %s", ns)))
+		return
+	}
+	writeResponse(w, http.StatusOK, explainResponse{Namespace: ns, Explanation: explanation})
+}
+
+func (v *idlerV2) Clusters(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	clusters := v.api.clusterView.GetDNSView()
+	if clusters == nil {
+		clusters = []cluster.DNSView{}
+	}
+	writeResponse(w, http.StatusOK, clustersResponse{Clusters: clusters})
+}
+
+func (v *idlerV2) DisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeResponse(w, http.StatusOK, v.disabledUsers())
+}
+
+func (v *idlerV2) UpdateDisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	var users userStatus
+	if err := decodeBody(r, &users); err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+
+	if err := v.api.updateDisabledUsers(r, users); err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, v.disabledUsers())
+}
+
+func (v *idlerV2) disabledUsers() disabledUsersResponse {
+	entries := v.api.disabledUsers.Entries()
+	users := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		users = append(users, entry.User)
+	}
+	return disabledUsersResponse{Users: users, Entries: entries}
+}
+
+func (v *idlerV2) DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	writeResponse(w, http.StatusOK, v.api.dryRunReport())
+}
+
+func (v *idlerV2) SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	ns := ps.ByName("namespace")
+	action := ps.ByName("action")
+	response, err := v.api.selfService(r, action, ns)
+	if response == nil {
+		response = actionResponse{Namespace: ns, Action: action}
+	}
+	v.respond(w, response, err)
+}
+
+func (v *idlerV2) Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	response, err := v.api.namespacesPage(r.URL.Query())
+	v.respond(w, response, err)
+}
+
+func (v *idlerV2) Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, err := v.api.startBulkJob(r)
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusAccepted, job)
+}
+
+func (v *idlerV2) BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	job, err := v.api.bulkJob(ps.ByName("id"))
+	v.respond(w, job, err)
+}
+
+func (v *idlerV2) Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	if err := v.api.streamEvents(w, r); err != nil {
+		respondWithAPIError(w, err)
+	}
+}
+
+func (v *idlerV2) Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	op, err := v.api.operation(r, ps.ByName("id"))
+	v.respond(w, op, err)
+}
+
+// respond writes the response, resp. the error if it is not nil.
+func (v *idlerV2) respond(w http.ResponseWriter, response any, err *apiError) {
+	if err != nil {
+		respondWithAPIError(w, err)
+		return
+	}
+	writeResponse(w, http.StatusOK, response)
+}
+
+// clusterFromBody returns the API URL and the token of the cluster given in the JSON body of the request.
+func (v *idlerV2) clusterFromBody(r *http.Request) (string, string, *apiError) {
+	request := clusterRequest{}
+	if err := decodeBody(r, &request); err != nil {
+		return "", "", err
+	}
+	return v.clusterToken(request.Cluster)
+}
+
+func (v *idlerV2) clusterToken(openShiftAPIURL string) (string, string, *apiError) {
+	if openShiftAPIURL == "" {
+		return "", "", newAPIError(http.StatusBadRequest, codeInvalidRequest, errors.New("OpenShift API URL needs to be specified"))
+	}
+
+	bearerToken, ok := v.api.clusterView.GetToken(openShiftAPIURL)
+	if !ok {
+		return "", "", newAPIError(http.StatusBadRequest, codeUnknownCluster, fmt.Errorf("Unknown OpenShift API URL: %s", openShiftAPIURL))
+	}
+	return openShiftAPIURL, bearerToken, nil
+}
+
+// decodeBody decodes the JSON body of the request into v.
+func decodeBody(r *http.Request, v interface{}) *apiError {
+	if r.Body == nil {
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, errors.New("Missing request body"))
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return newAPIError(http.StatusBadRequest, codeInvalidRequest, fmt.Errorf("Invalid request body: %s", err))
+	}
+	return nil
+}
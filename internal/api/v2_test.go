@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_v2_errors_use_envelope(t *testing.T) {
+	mosc := &mock.OpenShiftClient{}
+	v2 := &idlerV2{api: &idler{
+		openShiftClient: mosc,
+		clusterView:     cluster.NewView([]cluster.Cluster{{APIURL: "http://localhost/", Token: "secret"}}),
+		tenantService:   &mock.TenantService{},
+		userIdlers:      openshift.NewUserIdlerMap(),
+		disabledUsers:   disabled.NewUsers(store.NewMemoryStore()),
+		bulkJobs:        newBulkJobs(),
+		operations:      newOperations(),
+	}}
+	params := httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}}
+
+	tests := []struct {
+		name     string
+		function ReqFuncType
+		body     string
+		status   int
+		code     string
+	}{
+		{"missing body", v2.Idle, "", http.StatusBadRequest, codeInvalidRequest},
+		{"invalid body", v2.Idle, "{", http.StatusBadRequest, codeInvalidRequest},
+		{"missing cluster", v2.UnIdle, "{}", http.StatusBadRequest, codeInvalidRequest},
+		{"unknown cluster", v2.Reset, `{"cluster": "http://unknown/"}`, http.StatusBadRequest, codeUnknownCluster},
+		{"unknown idler", v2.KeepAlive, "", http.StatusNotFound, codeNotFound},
+		{"nothing to unpin", v2.Unpin, "", http.StatusNotFound, codeNotFound},
+		{"nothing to explain", v2.Explain, "", http.StatusNotFound, codeNotFound},
+		{"nothing to check", v2.Check, "", http.StatusNotFound, codeNotFound},
+		{"invalid check body", v2.Check, `{"dry_run": "yes"}`, http.StatusBadRequest, codeInvalidRequest},
+		{"invalid users", v2.UpdateDisabledUsers, `{"disable": "foo"}`, http.StatusBadRequest, codeInvalidRequest},
+		{"invalid expiry", v2.UpdateDisabledUsers, `{"disable": ["foo"], "expires_in": "soon"}`, http.StatusBadRequest, codeInvalidRequest},
+		{"invalid bulk action", v2.Bulk, `{"action": "delete", "selector": {"state": "running"}}`, http.StatusBadRequest, codeInvalidRequest},
+		{"empty bulk selector", v2.Bulk, `{"action": "idle", "selector": {}}`, http.StatusBadRequest, codeInvalidRequest},
+		{"unknown bulk job", v2.BulkJob, "", http.StatusNotFound, codeNotFound},
+		{"no events", v2.Events, "", http.StatusServiceUnavailable, codeUnavailable},
+		{"unknown operation", v2.Operation, "", http.StatusNotFound, codeNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(test.body))
+			w := httptest.NewRecorder()
+			test.function(w, r, params)
+
+			require.Equal(t, test.status, w.Code, "Unexpected status code")
+			require.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+			envelope := errorEnvelope{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+			require.Equal(t, test.code, envelope.Error, "Unexpected error code")
+			require.NotEmpty(t, envelope.Description, "Error should be described")
+		})
+	}
+	require.Equal(t, 0, mosc.IdleCallCount, "Nothing should have been idled")
+}
+
+func Test_v2_idle(t *testing.T) {
+	mosc := &mock.OpenShiftClient{}
+	v2 := &idlerV2{api: &idler{
+		openShiftClient: mosc,
+		clusterView:     cluster.NewView([]cluster.Cluster{{APIURL: "http://localhost/", Token: "secret"}}),
+		userIdlers:      openshift.NewUserIdlerMap(),
+	}}
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"cluster": "http://localhost/"}`))
+	w := httptest.NewRecorder()
+	v2.Idle(w, r, httprouter.Params{{Key: "namespace", Value: "foo-jenkins"}})
+
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+	require.Equal(t, 1, mosc.IdleCallCount, "Jenkins should have been idled")
+
+	response := actionResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, actionResponse{Namespace: "foo-jenkins", Action: "idle"}, response)
+}
+
+func Test_v2_disabled_users(t *testing.T) {
+	v2 := &idlerV2{api: &idler{disabledUsers: disabled.NewUsers(store.NewMemoryStore())}}
+
+	r := httptest.NewRequest("PATCH", "/", strings.NewReader(`{"disable": ["foo", "bar"], "enable": ["bar"], "reason": "debugging"}`))
+	w := httptest.NewRecorder()
+	v2.UpdateDisabledUsers(w, r, nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	w = httptest.NewRecorder()
+	v2.DisabledUsers(w, httptest.NewRequest("GET", "/", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+
+	response := disabledUsersResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, []string{"foo"}, response.Users)
+	require.Len(t, response.Entries, 1)
+	require.Equal(t, "debugging", response.Entries[0].Reason)
+}
+
+func Test_v2_shares_state_with_v1(t *testing.T) {
+	v1 := NewIdlerAPI(openshift.NewUserIdlerMap(), &mock.ClusterView{}, &mock.TenantService{}, nil,
+		disabled.NewUsers(store.NewMemoryStore()), &mock.Config{}, nil)
+	v2 := NewIdlerAPIV2(v1).(*idlerV2)
+	require.True(t, v2.api == v1.(*idler), "Both API versions should share the bulk jobs and operations")
+}
+
+func Test_v2_namespaces(t *testing.T) {
+	v2 := &idlerV2{api: &idler{userIdlers: openshift.NewUserIdlerMap(), disabledUsers: disabled.NewUsers(store.NewMemoryStore())}}
+
+	w := httptest.NewRecorder()
+	v2.Namespaces(w, httptest.NewRequest("GET", "/api/v2/namespaces?sort=foo", nil), nil)
+	require.Equal(t, http.StatusBadRequest, w.Code, "Unexpected status code")
+	envelope := errorEnvelope{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &envelope))
+	require.Equal(t, codeInvalidRequest, envelope.Error, "Unexpected error code")
+
+	w = httptest.NewRecorder()
+	v2.Namespaces(w, httptest.NewRequest("GET", "/api/v2/namespaces", nil), nil)
+	require.Equal(t, http.StatusOK, w.Code, "Unexpected status code")
+	response := namespacesResponse{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, namespacesResponse{Namespaces: []namespaceEntry{}, Page: 1, PerPage: defaultPerPage}, response)
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var logger = logrus.WithField("component", "auth")
+
+// Principal is an authenticated caller of the REST API.
+type Principal struct {
+	Name  string
+	Scope Scope
+}
+
+// Authenticator authenticates bearer tokens.
+type Authenticator interface {
+	// Authenticate returns the principal the given token belongs to, or an error if the token is invalid.
+	Authenticate(token string) (Principal, error)
+}
+
+type authenticator struct {
+	keys            KeySource
+	issuer          string
+	audience        string
+	serviceAccounts map[string]Scope
+	tokens          map[string]Scope
+	now             func() time.Time
+}
+
+// NewAuthenticator creates an Authenticator which accepts fabric8-auth service account tokens signed with one
+// of the given keys as well as the given static tokens. Service accounts get the scope they are mapped to by
+// name, unknown service accounts get no scope at all. keys may be nil to only accept static tokens. Unless they
+// are empty, service account tokens need to be issued by the given issuer for the given audience.
+func NewAuthenticator(keys KeySource, issuer string, audience string, serviceAccounts map[string]Scope, tokens map[string]Scope) Authenticator {
+	return &authenticator{
+		keys:            keys,
+		issuer:          issuer,
+		audience:        audience,
+		serviceAccounts: serviceAccounts,
+		tokens:          tokens,
+		now:             time.Now,
+	}
+}
+
+// Authenticate checks static tokens first and falls back to verifying the token as JWT.
+func (a *authenticator) Authenticate(token string) (Principal, error) {
+	for t, scope := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return Principal{Name: "static-token", Scope: scope}, nil
+		}
+	}
+
+	if a.keys == nil || strings.Count(token, ".") != 2 {
+		return Principal{}, fmt.Errorf("invalid token")
+	}
+
+	claims, err := parseRS256(token, a.keys, a.issuer, a.audience, a.now())
+	if err != nil {
+		return Principal{}, err
+	}
+
+	name := claims.ServiceAccountName
+	if name == "" {
+		name = claims.Subject
+	}
+	return Principal{Name: name, Scope: a.serviceAccounts[name]}, nil
+}
+
+// ParseScopedEntries parses entries of the form name:scope, e.g. "fabric8-jenkins-proxy:operate",
+// as used by the configuration of service accounts and static tokens.
+func ParseScopedEntries(entries []string) (map[string]Scope, error) {
+	scopes := make(map[string]Scope)
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("entry needs to be of the form name:scope")
+		}
+		scope, err := ParseScope(entry[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		scopes[entry[:i]] = scope
+	}
+	return scopes, nil
+}
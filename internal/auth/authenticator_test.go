@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	require.NoError(t, err)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func jwksServer(key *rsa.PrivateKey, kid string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"keys": [{"kid": %q, "kty": "RSA", "alg": "RS256", "use": "sig", "n": %q, "e": %q}]}`, kid,
+			base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()))
+	}))
+}
+
+func Test_service_account_tokens_are_verified(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := jwksServer(key, "key-1")
+	defer server.Close()
+
+	authenticator := NewAuthenticator(NewJWKSKeys(server.URL, time.Second), "", "",
+		map[string]Scope{"fabric8-jenkins-proxy": ScopeOperate}, nil)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	token := signToken(t, key, "key-1", map[string]interface{}{"sub": "1234", "service_accountname": "fabric8-jenkins-proxy", "exp": exp})
+	principal, err := authenticator.Authenticate(token)
+	assert.NoError(t, err)
+	assert.Equal(t, Principal{Name: "fabric8-jenkins-proxy", Scope: ScopeOperate}, principal)
+
+	token = signToken(t, key, "key-1", map[string]interface{}{"sub": "1234", "service_accountname": "unknown", "exp": exp})
+	principal, err = authenticator.Authenticate(token)
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeNone, principal.Scope, "Unknown service accounts should not get any scope")
+
+	token = signToken(t, key, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": time.Now().Add(-time.Minute).Unix()})
+	_, err = authenticator.Authenticate(token)
+	assert.EqualError(t, err, "token expired")
+
+	token = signToken(t, key, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy"})
+	_, err = authenticator.Authenticate(token)
+	assert.EqualError(t, err, "token without expiry")
+
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	token = signToken(t, other, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": exp})
+	_, err = authenticator.Authenticate(token)
+	assert.EqualError(t, err, "invalid token signature")
+
+	token = signToken(t, key, "key-2", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": exp})
+	_, err = authenticator.Authenticate(token)
+	assert.Error(t, err, "Tokens signed with unknown keys should be rejected")
+}
+
+func Test_issuer_and_audience_are_checked(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := jwksServer(key, "key-1")
+	defer server.Close()
+
+	authenticator := NewAuthenticator(NewJWKSKeys(server.URL, time.Second), "https://auth.openshift.io", "fabric8-jenkins-idler",
+		map[string]Scope{"fabric8-jenkins-proxy": ScopeOperate}, nil)
+	exp := time.Now().Add(time.Hour).Unix()
+
+	for _, aud := range []interface{}{"fabric8-jenkins-idler", []string{"fabric8-tenant", "fabric8-jenkins-idler"}} {
+		token := signToken(t, key, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": exp,
+			"iss": "https://auth.openshift.io", "aud": aud})
+		principal, err := authenticator.Authenticate(token)
+		assert.NoError(t, err)
+		assert.Equal(t, ScopeOperate, principal.Scope)
+	}
+
+	token := signToken(t, key, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": exp,
+		"iss": "https://auth.example.com", "aud": "fabric8-jenkins-idler"})
+	_, err = authenticator.Authenticate(token)
+	assert.EqualError(t, err, `token issued by "https://auth.example.com"`)
+
+	token = signToken(t, key, "key-1", map[string]interface{}{"service_accountname": "fabric8-jenkins-proxy", "exp": exp,
+		"iss": "https://auth.openshift.io"})
+	_, err = authenticator.Authenticate(token)
+	assert.EqualError(t, err, `token not issued for audience "fabric8-jenkins-idler"`)
+}
+
+func Test_static_tokens(t *testing.T) {
+	tokens, err := ParseScopedEntries([]string{"s3cr3t:admin", "viewer:read"})
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(nil, "", "", nil, tokens)
+
+	principal, err := authenticator.Authenticate("s3cr3t")
+	assert.NoError(t, err)
+	assert.Equal(t, ScopeAdmin, principal.Scope)
+
+	_, err = authenticator.Authenticate("guess")
+	assert.Error(t, err)
+
+	_, err = ParseScopedEntries([]string{"s3cr3t:root"})
+	assert.Error(t, err, "Unknown scope should be rejected")
+	_, err = ParseScopedEntries([]string{"s3cr3t"})
+	assert.Error(t, err, "Missing scope should be rejected")
+}
+
+func Test_scopes_include_lower_scopes(t *testing.T) {
+	assert.True(t, ScopeAdmin.Includes(ScopeRead))
+	assert.True(t, ScopeOperate.Includes(ScopeOperate))
+	assert.False(t, ScopeRead.Includes(ScopeOperate))
+	assert.False(t, ScopeNone.Includes(ScopeRead))
+}
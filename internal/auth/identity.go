@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+
+	authclient "github.com/fabric8-services/fabric8-jenkins-idler/internal/auth/client"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+)
+
+// Identity is the fabric8-auth identity of an end user.
+type Identity struct {
+	ID       string
+	Username string
+}
+
+// IdentityService resolves the tokens of end users to their identity.
+type IdentityService interface {
+	// Identity returns the identity the given user token belongs to, or an error if fabric8-auth does
+	// not accept the token.
+	Identity(ctx context.Context, token string) (Identity, error)
+}
+
+type identityService struct {
+	authURL       string
+	clientOptions []configuration.HTTPClientOption
+}
+
+// NewIdentityService creates an IdentityService which looks up users with the fabric8-auth service at the given URL.
+func NewIdentityService(authURL string, options ...configuration.HTTPClientOption) IdentityService {
+	return &identityService{
+		authURL:       authURL,
+		clientOptions: options,
+	}
+}
+
+// Identity retrieves the user the token belongs to from the /api/user endpoint of fabric8-auth.
+func (s *identityService) Identity(ctx context.Context, token string) (Identity, error) {
+	if token == "" {
+		return Identity{}, fmt.Errorf("token must not be empty")
+	}
+
+	client, err := NewClient(s.authURL, token, s.clientOptions...)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	res, err := client.ShowUser(ctx, authclient.ShowUserPath(), nil, nil)
+	if err != nil {
+		return Identity{}, fmt.Errorf("error while retrieving the user: %s", err)
+	}
+	defer func() {
+		ioutil.ReadAll(res.Body)
+		res.Body.Close()
+	}()
+
+	if err := ValidateResponse(client, res); err != nil {
+		return Identity{}, fmt.Errorf("error while retrieving the user: %s", err)
+	}
+
+	user, err := client.DecodeUser(res)
+	if err != nil {
+		return Identity{}, fmt.Errorf("error while decoding the user: %s", err)
+	}
+	if user.Data == nil || user.Data.Attributes == nil || user.Data.Attributes.IdentityID == nil {
+		return Identity{}, fmt.Errorf("no identity in response of %s", s.authURL)
+	}
+
+	identity := Identity{ID: *user.Data.Attributes.IdentityID}
+	if user.Data.Attributes.Username != nil {
+		identity.Username = *user.Data.Attributes.Username
+	}
+	return identity, nil
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// keysRefreshInterval limits how often the keys are fetched again because of an unknown key id.
+const keysRefreshInterval = time.Minute
+
+// Claims are the claims of a fabric8-auth token the Idler is interested in.
+type Claims struct {
+	Subject            string   `json:"sub"`
+	ServiceAccountName string   `json:"service_accountname"`
+	Issuer             string   `json:"iss"`
+	Audience           audience `json:"aud"`
+	ExpiresAt          int64    `json:"exp"`
+	NotBefore          int64    `json:"nbf"`
+}
+
+// audience is the aud claim, which is either a single string or an array of strings.
+type audience []string
+
+// UnmarshalJSON accepts a single string as well as an array of strings.
+func (a *audience) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(b, &multiple); err != nil {
+		return fmt.Errorf("aud needs to be a string or an array of strings")
+	}
+	*a = multiple
+	return nil
+}
+
+func (a audience) contains(s string) bool {
+	for _, aud := range a {
+		if aud == s {
+			return true
+		}
+	}
+	return false
+}
+
+// KeySource returns the public key with the given key id used to sign tokens.
+type KeySource interface {
+	Key(kid string) (*rsa.PublicKey, error)
+}
+
+// parseRS256 verifies the signature of the given RS256 signed JWT and returns its claims. It also
+// checks that the token has an expiry, is neither expired nor used before it is valid and, unless they
+// are empty, that it has been issued by the given issuer for the given audience.
+func parseRS256(token string, keys KeySource, issuer string, aud string, now time.Time) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Claims{}, fmt.Errorf("malformed token")
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Claims{}, fmt.Errorf("malformed token header: %s", err)
+	}
+	if header.Alg != "RS256" {
+		return Claims{}, fmt.Errorf("unsupported signing algorithm %q", header.Alg)
+	}
+
+	key, err := keys.Key(header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, fmt.Errorf("malformed token signature: %s", err)
+	}
+	hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hash[:], signature); err != nil {
+		return Claims{}, fmt.Errorf("invalid token signature")
+	}
+
+	claims := Claims{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Claims{}, fmt.Errorf("malformed token claims: %s", err)
+	}
+	if claims.ExpiresAt == 0 {
+		return Claims{}, fmt.Errorf("token without expiry")
+	}
+	if now.Unix() >= claims.ExpiresAt {
+		return Claims{}, fmt.Errorf("token expired")
+	}
+	if claims.NotBefore != 0 && now.Unix() < claims.NotBefore {
+		return Claims{}, fmt.Errorf("token not valid yet")
+	}
+	if issuer != "" && claims.Issuer != issuer {
+		return Claims{}, fmt.Errorf("token issued by %q", claims.Issuer)
+	}
+	if aud != "" && !claims.Audience.contains(aud) {
+		return Claims{}, fmt.Errorf("token not issued for audience %q", aud)
+	}
+	return claims, nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+type jwksKeys struct {
+	url    string
+	client *http.Client
+
+	lock      sync.Mutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// NewJWKSKeys creates a KeySource for the JSON Web Key Set published at the given URL, e.g.
+// https://auth.openshift.io/api/token/keys?format=jwk. The keys are fetched again if a token is
+// signed with an unknown key, at most once per minute.
+func NewJWKSKeys(url string, timeout time.Duration) KeySource {
+	return &jwksKeys{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+		keys:   make(map[string]*rsa.PublicKey),
+	}
+}
+
+// Key returns the key with the given id.
+func (j *jwksKeys) Key(kid string) (*rsa.PublicKey, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+
+	if time.Since(j.fetchedAt) > keysRefreshInterval {
+		j.fetchedAt = time.Now()
+		keys, err := j.fetch()
+		if err != nil {
+			logger.Errorf("Failed to fetch token keys from %s: %s", j.url, err)
+		} else {
+			j.keys = keys
+		}
+	}
+
+	if key, ok := j.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown key %q", kid)
+}
+
+func (j *jwksKeys) fetch() (map[string]*rsa.PublicKey, error) {
+	resp, err := j.client.Get(j.url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s from %s", resp.Status, j.url)
+	}
+
+	set := struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus of key %q: %s", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent of key %q: %s", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	}
+	return keys, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+type contextKey int
+
+const principalKey contextKey = 0
+
+// errorResponse is the body of 401 and 403 responses.
+type errorResponse struct {
+	Error       string `json:"error"`
+	Description string `json:"description"`
+}
+
+// Authorize wraps the given handler so that it is only called for requests with a bearer token which the
+// authenticator accepts and which grants at least the given scope. Requests without valid token are rejected
+// with 401, requests with insufficient scope with 403. The principal is added to the request context.
+func Authorize(authenticator Authenticator, required Scope, handle httprouter.Handle) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		header := r.Header.Get("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jenkins-idler"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", "missing bearer token")
+			return
+		}
+
+		principal, err := authenticator.Authenticate(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			logger.WithField("path", r.URL.Path).Warnf("Rejecting request: %s", err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="jenkins-idler", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+			return
+		}
+
+		if !principal.Scope.Includes(required) {
+			logger.WithField("path", r.URL.Path).Warnf("Rejecting request of %s with scope %s, %s required",
+				principal.Name, principal.Scope, required)
+			writeError(w, http.StatusForbidden, "forbidden", "scope "+required.String()+" required")
+			return
+		}
+
+		handle(w, r.WithContext(context.WithValue(r.Context(), principalKey, principal)), ps)
+	}
+}
+
+// PrincipalFromContext returns the principal of an authorized request. The second return value is false
+// if the request has not been authorized, e.g. because authentication is disabled.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey).(Principal)
+	return principal, ok
+}
+
+func writeError(w http.ResponseWriter, status int, code string, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(errorResponse{Error: code, Description: description})
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"fmt"
+)
+
+// Scope is the level of access granted to a caller of the REST API. Each scope includes the lower ones.
+type Scope int
+
+const (
+	// ScopeNone grants no access at all.
+	ScopeNone Scope = iota
+	// ScopeRead grants access to the read-only routes, e.g. status and explanations.
+	ScopeRead
+	// ScopeOperate additionally allows to idle, un-idle and keep Jenkins alive.
+	ScopeOperate
+	// ScopeAdmin additionally allows to reset Jenkins and to disable idling for users.
+	ScopeAdmin
+)
+
+func (s Scope) String() string {
+	return [...]string{"none", "read", "operate", "admin"}[s]
+}
+
+// Includes returns true if the scope grants at least the given scope.
+func (s Scope) Includes(required Scope) bool {
+	return s >= required
+}
+
+// ParseScope parses the name of a scope as used in the configuration, i.e. "read", "operate" or "admin".
+func ParseScope(s string) (Scope, error) {
+	switch s {
+	case "read":
+		return ScopeRead, nil
+	case "operate":
+		return ScopeOperate, nil
+	case "admin":
+		return ScopeAdmin, nil
+	}
+	return ScopeNone, fmt.Errorf("unknown scope %q", s)
+}
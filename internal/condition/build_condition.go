@@ -2,6 +2,8 @@ package condition
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
@@ -12,21 +14,28 @@ import (
 type BuildCondition struct {
 	idleAfter     time.Duration
 	idleLongBuild time.Duration
+	hung          HungBuildPolicy
 }
 
 // NewBuildCondition creates a new instance of BuildCondition given
 // idleAfter(time after which jenkins should be idled).
 func NewBuildCondition(idleAfter time.Duration, idleLongBuild time.Duration) Condition {
-	b := &BuildCondition{idleAfter: idleAfter, idleLongBuild: idleLongBuild}
+	return NewHungBuildCondition(idleAfter, idleLongBuild, HungBuildPolicy{})
+}
+
+// NewHungBuildCondition creates a new instance of BuildCondition which additionally no longer considers
+// builds to be in progress which are hung according to the given policy, unless the policy keeps them.
+func NewHungBuildCondition(idleAfter time.Duration, idleLongBuild time.Duration, hung HungBuildPolicy) Condition {
+	b := &BuildCondition{idleAfter: idleAfter, idleLongBuild: idleLongBuild, hung: hung}
 	return b
 }
 
-// Eval returns true if the passed User does not have any builds or does not have any
-// active builds and the time elapsed since the last completed build is created than the configured idle after time.
-func (c *BuildCondition) Eval(object interface{}) (Action, error) {
+// Eval returns true if the passed User does not have any builds or none of the active builds is in
+// progress any longer and the time elapsed since the last completed build is greater than the configured idle after time.
+func (c *BuildCondition) Eval(object interface{}) (Verdict, error) {
 	u, ok := object.(model.User)
 	if !ok {
-		return NoAction, fmt.Errorf("%T is not of type User", object)
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
 	}
 
 	log := logrus.WithFields(logrus.Fields{
@@ -38,43 +47,41 @@ func (c *BuildCondition) Eval(object interface{}) (Action, error) {
 	log.WithField("check", "any-builds").Infof("Checking if there are any builds")
 	if !u.HasBuilds() {
 		log.WithField("action", "idle").Infof("user has no builds")
-		return Idle, nil
+		return NewVerdict(Idle, "user has no builds"), nil
 	}
 
 	now := time.Now().UTC()
 
-	log.WithField("check", "active-builds").Infof("Checking active builds")
+	log.WithField("check", "active-builds").Infof("Checking %d active builds", len(u.ActiveBuilds))
 	if u.HasActiveBuilds() {
-		// if we have activebuild being active over x time then see it as
-		// expired or they would be lingering forever (i.e: approval process pipelines)
-
-		startTime := u.ActiveBuild.Status.StartTimestamp.Time
-		maxBuildTime := startTime.Add(c.idleLongBuild)
-		log.WithField("check", "active-build-timedout").Infof(
-			"has active build started at %v has exceeded max build time: %v ", startTime, maxBuildTime)
-
-		if now.After(maxBuildTime) {
-			log.WithField("action", "idle").Infof(
-				"active build started at %v has exceeded timeout %v", startTime, c.idleLongBuild)
-			return Idle, nil
+		// Jenkins is only inactive once none of the active builds is in progress any longer
+		var running, stopped []string
+		var runningSince, changesAt time.Time
+		for _, name := range sortedBuildNames(u.ActiveBuilds) {
+			build := u.ActiveBuilds[name]
+			if ok, reason := c.inProgress(build, now, log.WithField("build", name)); !ok {
+				stopped = append(stopped, fmt.Sprintf("%s %s", name, reason))
+				continue
+			}
+
+			startTime := build.Status.StartTimestamp.Time
+			running = append(running, name)
+			if runningSince.IsZero() || startTime.Before(runningSince) {
+				runningSince = startTime
+			}
+			if maxBuildTime := startTime.Add(c.idleLongBuild); maxBuildTime.After(changesAt) {
+				changesAt = maxBuildTime
+			}
 		}
 
-		completionTime := u.ActiveBuild.Status.CompletionTimestamp.Time
-
-		log.WithField("check", "active-build-completed").Infof(
-			"has active build started at %v gone past completion time %v", startTime, completionTime)
-		if u.ActiveBuild.Status.Phase == "New" &&
-			completionTime.Sub(startTime) > 2*time.Second {
-			log.WithField("action", "idle").Infof(
-				"active build started at %v has gone past completion time %v",
-				startTime, completionTime)
-			/// TODO: not sure about this
-			return Idle, nil
+		if len(running) > 0 {
+			log.WithField("action", "unidle").Infof("builds %s seem to be in progress", strings.Join(running, ", "))
+			return NewVerdict(UnIdle, fmt.Sprintf("%d of %d builds in progress", len(running), len(u.ActiveBuilds))).
+				At("build_started", runningSince).ChangesAt(changesAt), nil
 		}
 
-		log.WithField("action", "unidle").Infof(
-			"active build started at %v seems to be in progress", startTime)
-		return UnIdle, nil
+		log.WithField("action", "idle").Infof("none of the %d active builds is in progress", len(u.ActiveBuilds))
+		return NewVerdict(Idle, fmt.Sprintf("no active build in progress: %s", strings.Join(stopped, ", "))), nil
 	}
 
 	// Done builds
@@ -83,7 +90,7 @@ func (c *BuildCondition) Eval(object interface{}) (Action, error) {
 
 	if u.DoneBuild.Status.Phase == "Cancelled" {
 		log.WithField("action", "idle").Infof("Build is cancelled")
-		return Idle, nil
+		return NewVerdict(Idle, "last build was cancelled"), nil
 	}
 
 	completionTime := u.DoneBuild.Status.CompletionTimestamp.Time
@@ -95,10 +102,64 @@ func (c *BuildCondition) Eval(object interface{}) (Action, error) {
 	if now.After(terminateTime) {
 		log.WithField("action", "idle").Infof(
 			"%v has elapsed after last done-build at %v ", c.idleAfter, completionTime)
-		return Idle, nil
+		return NewVerdict(Idle, fmt.Sprintf("%v elapsed after last build", c.idleAfter)).
+			At("build_completed", completionTime), nil
 	}
 
 	log.WithField("action", "none").Infof(
 		"%v has not yet elapsed after last done-build at %v ", c.idleAfter, completionTime)
-	return UnIdle, nil
+	return NewVerdict(UnIdle, fmt.Sprintf("%v not elapsed after last build", c.idleAfter)).
+		At("build_completed", completionTime).ChangesAt(terminateTime), nil
+}
+
+// inProgress returns false together with the reason if the given active build has been running for longer
+// than the long build timeout, is hung and not kept by the hung build policy or has finished resp. gone
+// past its completion time, true otherwise.
+func (c *BuildCondition) inProgress(build model.Build, now time.Time, log *logrus.Entry) (bool, string) {
+	// if we have activebuild being active over x time then see it as
+	// expired or they would be lingering forever (i.e: approval process pipelines)
+	startTime := build.Status.StartTimestamp.Time
+	maxBuildTime := startTime.Add(c.idleLongBuild)
+	log.WithField("check", "active-build-timedout").Infof(
+		"has active build started at %v has exceeded max build time: %v ", startTime, maxBuildTime)
+
+	if now.After(maxBuildTime) {
+		log.Infof("active build started at %v has exceeded timeout %v", startTime, c.idleLongBuild)
+		return false, fmt.Sprintf("exceeded timeout of %v", c.idleLongBuild)
+	}
+
+	if status, ok := build.PipelineStatus(); ok {
+		// the pipeline status reported by Jenkins is more accurate than the build phase
+		log.WithField("check", "active-build-pipeline").Infof("pipeline status is %s", status.Status)
+		if status.Finished() {
+			return false, "finished in Jenkins"
+		}
+		if hung, ok := c.hung.Classify(build, now); ok && hung.Action != KeepBuild {
+			log.Infof("build is %s in stage %q since %v, action %s", hung.State, hung.Stage, hung.Since, hung.Action)
+			return false, fmt.Sprintf("%s since %v", hung.State, hung.Since)
+		}
+		return true, ""
+	}
+
+	completionTime := build.Status.CompletionTimestamp.Time
+
+	log.WithField("check", "active-build-completed").Infof(
+		"has active build started at %v gone past completion time %v", startTime, completionTime)
+	// Builds without pipeline status are not covered by the hung build policy, the heuristic below is unchanged.
+	if build.Status.Phase == "New" &&
+		completionTime.Sub(startTime) > 2*time.Second {
+		/// TODO: not sure about this
+		log.Infof("active build started at %v has gone past completion time %v", startTime, completionTime)
+		return false, "gone past completion time"
+	}
+	return true, ""
+}
+
+func sortedBuildNames(builds map[string]model.Build) []string {
+	names := make([]string, 0, len(builds))
+	for name := range builds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
@@ -20,23 +20,23 @@ func Test_eval_idle_if_there_are_no_builds(t *testing.T) {
 	condition := NewBuildCondition(time.Duration(5)*time.Minute, time.Duration(5)*time.Minute)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, Idle, result, "Condition should evaluate to Idle.")
+	assert.Equal(t, Idle, result.Action, "Condition should evaluate to Idle.")
 }
 
 func Test_eval_unidle_when_active_build_exists(t *testing.T) {
 	user := model.NewUser("123", "foo")
-	user.ActiveBuild = model.Build{
+	user.StartBuild(model.Build{
 		Metadata: model.Metadata{
 			Name: "test build",
 		},
 		Status: model.Status{
 			StartTimestamp: model.BuildTime{Time: time.Now()},
 		},
-	}
+	})
 	condition := NewBuildCondition(time.Duration(5)*time.Minute, time.Duration(5)*time.Minute)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, UnIdle, result, "Condition should evaluate to UnIdle.")
+	assert.Equal(t, UnIdle, result.Action, "Condition should evaluate to UnIdle.")
 }
 
 func Test_eval_idle_when_activebuild_is_old(t *testing.T) {
@@ -44,18 +44,18 @@ func Test_eval_idle_when_activebuild_is_old(t *testing.T) {
 		time.RFC3339,
 		"1979-04-16T16:30:41+00:00")
 	user := model.NewUser("123", "foo")
-	user.ActiveBuild = model.Build{
+	user.StartBuild(model.Build{
 		Metadata: model.Metadata{
 			Name: "test build",
 		},
 		Status: model.Status{
 			StartTimestamp: model.BuildTime{Time: oldTime},
 		},
-	}
+	})
 	condition := NewBuildCondition(time.Duration(5)*time.Minute, time.Duration(10)*time.Hour)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, Idle, result, "Condition should evaluate to Idle.")
+	assert.Equal(t, Idle, result.Action, "Condition should evaluate to Idle.")
 }
 
 func Test_eval_completion_before_idletime_expires(t *testing.T) {
@@ -71,7 +71,7 @@ func Test_eval_completion_before_idletime_expires(t *testing.T) {
 	condition := NewBuildCondition(time.Duration(5)*time.Minute, time.Duration(5)*time.Minute)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, UnIdle, result, "Condition should evaluate to UnIdle.")
+	assert.Equal(t, UnIdle, result.Action, "Condition should evaluate to UnIdle.")
 }
 
 func Test_eval_completion_after_idletime_expires(t *testing.T) {
@@ -91,14 +91,14 @@ func Test_eval_completion_after_idletime_expires(t *testing.T) {
 	condition := NewBuildCondition(5*time.Minute, 5*time.Minute)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, Idle, result, "Condition should evaluate to Idle.")
+	assert.Equal(t, Idle, result.Action, "Condition should evaluate to Idle.")
 }
 
 func Test_eval_ignore_pesky_jenkins_sync_plugin(t *testing.T) {
 	startTime := time.Now()
 	completionTime := startTime.Add(time.Duration(4 * time.Second))
 	user := model.NewUser("123", "foo")
-	user.ActiveBuild = model.Build{
+	user.StartBuild(model.Build{
 		Metadata: model.Metadata{
 			Name: "test build",
 		},
@@ -107,9 +107,38 @@ func Test_eval_ignore_pesky_jenkins_sync_plugin(t *testing.T) {
 			StartTimestamp:      model.BuildTime{Time: startTime},
 			CompletionTimestamp: model.BuildTime{Time: completionTime},
 		},
-	}
+	})
 	condition := NewBuildCondition(time.Duration(5)*time.Minute, time.Duration(10)*time.Hour)
 	result, err := condition.Eval(user)
 	assert.NoError(t, err)
-	assert.Equal(t, Idle, result, "Condition should evaluate to Idle.")
+	assert.Equal(t, Idle, result.Action, "Condition should evaluate to Idle.")
+}
+
+func Test_eval_unidle_while_any_concurrent_build_is_running(t *testing.T) {
+	user := model.NewUser("123", "foo")
+	first := model.Build{
+		Metadata: model.Metadata{Name: "foo-1"},
+		Status:   model.Status{Phase: "Running", StartTimestamp: model.BuildTime{Time: time.Now()}},
+	}
+	second := model.Build{
+		Metadata: model.Metadata{Name: "foo-2"},
+		Status:   model.Status{Phase: "Running", StartTimestamp: model.BuildTime{Time: time.Now()}},
+	}
+	user.StartBuild(first)
+	user.StartBuild(second)
+	condition := NewBuildCondition(5*time.Minute, 5*time.Minute)
+
+	first.Status.Phase = "Complete"
+	first.Status.CompletionTimestamp = model.BuildTime{Time: time.Now().Add(-time.Hour)}
+	user.FinishBuild(first)
+	result, err := condition.Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, UnIdle, result.Action, "Second build is still running.")
+
+	second.Status.Phase = "Complete"
+	second.Status.CompletionTimestamp = model.BuildTime{Time: time.Now().Add(-time.Hour)}
+	user.FinishBuild(second)
+	result, err = condition.Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, Idle, result.Action, "All builds are done.")
 }
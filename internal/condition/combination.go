@@ -0,0 +1,107 @@
+package condition
+
+import (
+	"fmt"
+)
+
+// ParseAction parses the name of an action as used in the configuration, i.e. "idle", "unidle" or "none".
+func ParseAction(s string) (Action, error) {
+	switch s {
+	case "idle":
+		return Idle, nil
+	case "unidle":
+		return UnIdle, nil
+	case "none":
+		return NoAction, nil
+	}
+	return NoAction, fmt.Errorf("unknown action %q", s)
+}
+
+// Expr is a predicate over the results of named conditions. Exactly one of Condition, All or Any is set.
+type Expr struct {
+	// Condition is the name of the condition whose result is compared with Is.
+	Condition string
+	Is        Action
+	// All is true if all of the expressions are true.
+	All []Expr
+	// Any is true if at least one of the expressions is true.
+	Any []Expr
+}
+
+// matches evaluates the expression, using eval to get the result of a named condition.
+// Groups are evaluated from left to right and stop as soon as their result is known.
+func (e Expr) matches(eval func(name string) Action) bool {
+	switch {
+	case len(e.All) > 0:
+		for _, sub := range e.All {
+			if !sub.matches(eval) {
+				return false
+			}
+		}
+		return true
+	case len(e.Any) > 0:
+		for _, sub := range e.Any {
+			if sub.matches(eval) {
+				return true
+			}
+		}
+		return false
+	}
+	return eval(e.Condition) == e.Is
+}
+
+// Rule decides the combined result as soon as its expression matches.
+type Rule struct {
+	Name string
+	When Expr
+	Then Action
+}
+
+// Combination defines how the results of the single conditions are combined. Rules are tried in order,
+// the first matching rule decides the result. Only the conditions needed to evaluate a rule are evaluated.
+// If no rule matches, all conditions are evaluated in Order and combined either by taking the largest
+// action or, if Vote is true, by weighted votes.
+type Combination struct {
+	// Order lists the names of the conditions in evaluation order. Conditions not listed are
+	// evaluated afterwards in the order they were added.
+	Order []string
+	Rules []Rule
+	Vote  bool
+	// Weights are the votes of the conditions by name, conditions not listed have a weight of 1.
+	Weights map[string]int
+}
+
+// combine combines the given condition results. Conditions resulting in NoAction abstain from voting,
+// a tie between idle and un-idle results in un-idle, so that Jenkins is rather kept running.
+func (c Combination) combine(results map[string]Action) Action {
+	result := NoAction
+	if !c.Vote {
+		// overall result is the max of all conditions
+		for _, action := range results {
+			if action > result {
+				result = action
+			}
+		}
+		return result
+	}
+
+	votes := make(map[Action]int)
+	for name, action := range results {
+		if action == NoAction {
+			continue
+		}
+		weight, ok := c.Weights[name]
+		if !ok {
+			weight = 1
+		}
+		votes[action] += weight
+	}
+
+	switch {
+	case votes[Idle] > votes[UnIdle]:
+		return Idle
+	case votes[UnIdle] > 0 && votes[UnIdle] >= votes[Idle]:
+		return UnIdle
+	}
+	return NoAction
+}
@@ -0,0 +1,106 @@
+package condition
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingCondition struct {
+	action Action
+	count  int
+}
+
+func (c *countingCondition) Eval(object interface{}) (Verdict, error) {
+	c.count++
+	return NewVerdict(c.action, "counted"), nil
+}
+
+func Test_parse_action(t *testing.T) {
+	for s, want := range map[string]Action{"idle": Idle, "unidle": UnIdle, "none": NoAction} {
+		action, err := ParseAction(s)
+		assert.NoError(t, err)
+		assert.Equal(t, want, action)
+	}
+
+	_, err := ParseAction("hibernate")
+	assert.Error(t, err, "Unknown action should be rejected")
+}
+
+func Test_rule_short_circuits_evaluation(t *testing.T) {
+	build := &countingCondition{action: UnIdle}
+	user := &countingCondition{action: Idle}
+
+	conditions := NewConditions()
+	conditions.Add("build", build)
+	conditions.Add("user", user)
+	conditions.SetCombination(Combination{
+		Rules: []Rule{{Name: "active-build-vetoes-idle", When: Expr{Condition: "build", Is: UnIdle}, Then: NoAction}},
+	})
+
+	result, errors := conditions.Eval(model.NewUser("id", "name"))
+	assert.NoError(t, errors.ToError())
+	assert.Equal(t, NoAction, result.Action, "Matching rule should decide")
+	assert.Equal(t, 1, build.count)
+	assert.Equal(t, 0, user.count, "Conditions not needed by the matching rule should not be evaluated")
+
+	build.action = Idle
+	result, _ = conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, Idle, result.Action, "Without matching rule the results should be combined")
+	assert.Equal(t, 1, user.count)
+}
+
+func Test_rule_groups(t *testing.T) {
+	conditions := NewConditions()
+	conditions.Add("dc", &IdleCondition{})
+	conditions.Add("build", &IdleCondition{})
+	conditions.Add("user", &UnIdleCondition{})
+
+	all := Expr{All: []Expr{{Condition: "dc", Is: Idle}, {Condition: "build", Is: Idle}}}
+	any := Expr{Any: []Expr{{Condition: "user", Is: Idle}, {Condition: "prewarm", Is: UnIdle}}}
+
+	conditions.SetCombination(Combination{Rules: []Rule{
+		{Name: "any", When: any, Then: UnIdle},
+		{Name: "all", When: all, Then: Idle},
+	}})
+	result, _ := conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, Idle, result.Action, "All group should match, any group should not")
+
+	conditions.SetCombination(Combination{Rules: []Rule{
+		{Name: "all", When: Expr{All: []Expr{all, {Condition: "user", Is: Idle}}}, Then: Idle},
+	}})
+	result, _ = conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, UnIdle, result.Action, "No rule should match and max should be used")
+}
+
+func Test_weighted_votes(t *testing.T) {
+	conditions := NewConditions()
+	conditions.Add("dc", &IdleCondition{})
+	conditions.Add("build", &IdleCondition{})
+	conditions.Add("user", &UnIdleCondition{})
+
+	conditions.SetCombination(Combination{Vote: true})
+	result, _ := conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, Idle, result.Action, "Majority should win")
+
+	conditions.SetCombination(Combination{Vote: true, Weights: map[string]int{"user": 2}})
+	result, _ = conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, UnIdle, result.Action, "A tie should keep Jenkins running")
+
+	conditions.SetCombination(Combination{Vote: true, Weights: map[string]int{"user": 3}})
+	result, _ = conditions.Eval(model.NewUser("id", "name"))
+	assert.Equal(t, UnIdle, result.Action, "Weighted vote should win")
+}
+
+func Test_evaluation_order(t *testing.T) {
+	conditions := NewConditions()
+	conditions.Add("c", &IdleCondition{})
+	conditions.Add("a", &IdleCondition{})
+	conditions.Add("b", &IdleCondition{})
+
+	assert.Equal(t, []string{"c", "a", "b"}, conditions.order(), "Conditions should be evaluated in the order they were added")
+
+	conditions.SetCombination(Combination{Order: []string{"b", "unknown"}})
+	assert.Equal(t, []string{"b", "c", "a"}, conditions.order(), "Configured order should come first")
+}
@@ -3,6 +3,7 @@ package condition
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
@@ -37,15 +38,36 @@ func (a Action) String() string {
 	return actions[a]
 }
 
-// Condition defines a single Eval method which returns true or false.
+// MarshalText encodes the action by its name, e.g. in JSON responses.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Condition defines a single Eval method which returns the verdict of the condition.
 type Condition interface {
-	// Return true if the condition is true for a given object.
-	Eval(object interface{}) (Action, error)
+	// Eval returns the action for the given object together with the reasoning behind it.
+	Eval(object interface{}) (Verdict, error)
 }
 
-// Conditions defines map of Condition instances by their names
+// Override is implemented by conditions which do not contribute to the combined
+// result of all conditions, but may replace it, e.g. time based schedules.
+type Override interface {
+	// Override returns the verdict whose action replaces the given combined result.
+	Override(object interface{}, result Action) (Verdict, error)
+}
+
+type namedOverride struct {
+	name     string
+	override Override
+}
+
+// Conditions defines map of Condition instances by their names, the order they were added in,
+// how their results are combined as well as an ordered list of overrides applied to the combined result.
 type Conditions struct {
-	conditions map[string]Condition
+	conditions  map[string]Condition
+	names       []string
+	combination Combination
+	overrides   []namedOverride
 }
 
 // NewConditions create a new instance of Conditions.
@@ -55,15 +77,19 @@ func NewConditions() Conditions {
 	}
 }
 
-// Eval evaluates a list of Conditions for a given object. It returns false if
-// any of the conditions evaluates to false, otherwise true.
-func (c *Conditions) Eval(o interface{}) (Action, util.MultiError) {
+// Eval evaluates a list of Conditions for a given object and combines their results as defined by
+// the Combination of this Conditions instance, by default the max of all conditions.
+func (c *Conditions) Eval(o interface{}) (Evaluation, util.MultiError) {
 	errors := util.MultiError{}
+	evaluation := Evaluation{
+		EvaluatedAt: time.Now().UTC(),
+		Conditions:  make(map[string]Verdict),
+	}
 
 	u, ok := o.(model.User)
 	if !ok {
 		errors.Collect(fmt.Errorf("%T is not of type User", o))
-		return NoAction, errors
+		return evaluation, errors
 	}
 
 	log := logrus.WithFields(logrus.Fields{
@@ -73,39 +99,120 @@ func (c *Conditions) Eval(o interface{}) (Action, util.MultiError) {
 	})
 
 	condStates := make(map[string]Action)
+	evaluate := func(name string) Action {
+		if action, ok := condStates[name]; ok {
+			return action
+		}
 
-	result := NoAction
-	for name, ci := range c.conditions {
-		action, err := ci.Eval(o)
+		ci, ok := c.conditions[name]
+		if !ok {
+			// rules may refer to conditions which are not enabled
+			return NoAction
+		}
 
+		verdict, err := ci.Eval(o)
 		if err != nil {
 			log.Error(err)
 			errors.Collect(err)
+			if verdict.Reason == "" {
+				verdict.Reason = err.Error()
+			}
 		}
+		evaluation.Conditions[name] = verdict
+		condStates[name] = verdict.Action
+		return verdict.Action
+	}
 
-		condStates[name] = action
+	result := NoAction
+	matched := false
+	for _, rule := range c.combination.Rules {
+		if rule.When.matches(evaluate) {
+			log.Infof("rule %s matched", rule.Name)
+			result = rule.Then
+			evaluation.Reason = fmt.Sprintf("rule %s matched", rule.Name)
+			matched = true
+			break
+		}
+	}
 
-		// overall result is the max of all conditions
-		if action > result {
-			result = action
+	if !matched {
+		for _, name := range c.order() {
+			evaluate(name)
+		}
+		result = c.combination.combine(condStates)
+		if c.combination.Vote {
+			evaluation.Reason = "weighted vote of all conditions"
+		} else {
+			evaluation.Reason = "max of all conditions"
 		}
-		// TODO(sthaha): skip rest of the condition check is any of the
-		// condition results in UnIdle
 	}
 
 	log.Infof("conditions/result: %s | %s", result, c.conditionMapToString(condStates))
-	return result, errors
+
+	for _, ov := range c.overrides {
+		verdict, err := ov.override.Override(o, result)
+		if err != nil {
+			log.Error(err)
+			errors.Collect(err)
+			continue
+		}
+
+		if verdict.Action != result {
+			log.Infof("override %s changed result from %s to %s", ov.name, result, verdict.Action)
+			if evaluation.Overrides == nil {
+				evaluation.Overrides = make(map[string]Verdict)
+			}
+			evaluation.Overrides[ov.name] = verdict
+			evaluation.Reason = fmt.Sprintf("override %s: %s", ov.name, verdict.Reason)
+			result = verdict.Action
+		}
+	}
+
+	evaluation.Action = result
+	return evaluation, errors
+}
+
+// SetCombination sets how the results of the conditions are combined.
+func (c *Conditions) SetCombination(combination Combination) {
+	c.combination = combination
+}
+
+// order returns the names of all conditions in evaluation order.
+func (c *Conditions) order() []string {
+	var names []string
+	for _, name := range c.combination.Order {
+		if _, ok := c.conditions[name]; ok && !util.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	for _, name := range c.names {
+		if !util.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
 }
 
 // Add adds a condition with its name to the this Conditions instance.
 func (c *Conditions) Add(name string, condition Condition) {
+	if _, ok := c.conditions[name]; !ok {
+		c.names = append(c.names, name)
+	}
 	c.conditions[name] = condition
 }
 
+// AddOverride adds an override with its name to this Conditions instance.
+// Overrides are applied in the order they are added.
+func (c *Conditions) AddOverride(name string, override Override) {
+	c.overrides = append(c.overrides, namedOverride{name: name, override: override})
+}
+
 func (c *Conditions) conditionMapToString(conditions map[string]Action) string {
 	var result []string
-	for key, value := range conditions {
-		result = append(result, fmt.Sprintf("%s: %s", key, value))
+	for _, name := range c.order() {
+		if value, ok := conditions[name]; ok {
+			result = append(result, fmt.Sprintf("%s: %s", name, value))
+		}
 	}
 	return strings.Join(result, " | ")
 }
@@ -13,15 +13,15 @@ import (
 type IdleCondition struct {
 }
 
-func (c *IdleCondition) Eval(object interface{}) (Action, error) {
-	return Idle, nil
+func (c *IdleCondition) Eval(object interface{}) (Verdict, error) {
+	return NewVerdict(Idle, "idle"), nil
 }
 
 type UnIdleCondition struct {
 }
 
-func (c *UnIdleCondition) Eval(object interface{}) (Action, error) {
-	return UnIdle, nil
+func (c *UnIdleCondition) Eval(object interface{}) (Verdict, error) {
+	return NewVerdict(UnIdle, "unidle"), nil
 }
 
 type ErrorCondition struct {
@@ -33,8 +33,8 @@ func NewErrorCondition(msg string) Condition {
 	return b
 }
 
-func (c *ErrorCondition) Eval(object interface{}) (Action, error) {
-	return NoAction, errors.New(c.msg)
+func (c *ErrorCondition) Eval(object interface{}) (Verdict, error) {
+	return Verdict{}, errors.New(c.msg)
 }
 
 func Test_all_conditions_idle(t *testing.T) {
@@ -46,7 +46,7 @@ func Test_all_conditions_idle(t *testing.T) {
 	result, err := conditions.Eval(model.NewUser("id", "name"))
 
 	assert.NoError(t, err.ToError(), "No error expected.")
-	assert.Equal(t, Idle, result, "Should evaluate to Idle.")
+	assert.Equal(t, Idle, result.Action, "Should evaluate to Idle.")
 }
 
 func Test_all_conditions_unidle(t *testing.T) {
@@ -58,7 +58,7 @@ func Test_all_conditions_unidle(t *testing.T) {
 	result, err := conditions.Eval(model.NewUser("id", "name"))
 
 	assert.NoError(t, err.ToError(), "No error expected.")
-	assert.Equal(t, UnIdle, result, "Should evaluate to unidle")
+	assert.Equal(t, UnIdle, result.Action, "Should evaluate to unidle")
 }
 
 func Test_mixed_conditions(t *testing.T) {
@@ -70,7 +70,7 @@ func Test_mixed_conditions(t *testing.T) {
 	result, err := conditions.Eval(model.NewUser("id", "name"))
 
 	assert.NoError(t, err.ToError(), "No error expected.")
-	assert.Equal(t, UnIdle, result, "Should evaluate to UnIdle.")
+	assert.Equal(t, UnIdle, result.Action, "Should evaluate to UnIdle.")
 }
 
 func Test_error_conditions(t *testing.T) {
@@ -84,5 +84,23 @@ func Test_error_conditions(t *testing.T) {
 
 	assert.Error(t, err.ToError(), "No error expected.")
 	assert.Equal(t, "buh", err.ToError().Error(), "Unexpected error message.")
-	assert.Equal(t, Idle, result, "Should evaluate to false.")
+	assert.Equal(t, Idle, result.Action, "Should evaluate to false.")
+}
+
+type VetoOverride struct {
+}
+
+func (o *VetoOverride) Override(object interface{}, result Action) (Verdict, error) {
+	return NewVerdict(NoAction, "veto"), nil
+}
+
+func Test_overrides_replace_result(t *testing.T) {
+	conditions := NewConditions()
+	conditions.Add("unidle-1", &UnIdleCondition{})
+	conditions.AddOverride("veto", &VetoOverride{})
+
+	result, err := conditions.Eval(model.NewUser("id", "name"))
+
+	assert.NoError(t, err.ToError(), "No error expected.")
+	assert.Equal(t, NoAction, result.Action, "Should evaluate to NoAction.")
 }
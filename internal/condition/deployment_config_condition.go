@@ -21,10 +21,10 @@ func NewDCCondition(idleAfter time.Duration) Condition {
 }
 
 // Eval returns true if the last deployment config change occurred for more than the configured idle after interval.
-func (c *DeploymentConfigCondition) Eval(object interface{}) (Action, error) {
+func (c *DeploymentConfigCondition) Eval(object interface{}) (Verdict, error) {
 	u, ok := object.(model.User)
 	if !ok {
-		return NoAction, fmt.Errorf("%T is not of type User", object)
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
 	}
 
 	log := logrus.WithFields(logrus.Fields{
@@ -37,7 +37,7 @@ func (c *DeploymentConfigCondition) Eval(object interface{}) (Action, error) {
 	if lastUpdated.IsZero() {
 		log.WithField("action", "none").Info(
 			"could not find when jenkins was last updated by idler, so taking no action")
-		return NoAction, nil
+		return NewVerdict(NoAction, "jenkins last update unknown"), nil
 	}
 
 	now := time.Now().UTC()
@@ -46,11 +46,13 @@ func (c *DeploymentConfigCondition) Eval(object interface{}) (Action, error) {
 	if now.After(terminateTime) {
 		log.WithField("action", "idle").Infof("%v (%v) has elapsed after last update at %v",
 			c.idleAfter, terminateTime, lastUpdated)
-		return Idle, nil
+		return NewVerdict(Idle, fmt.Sprintf("%v elapsed after last jenkins update", c.idleAfter)).
+			At("jenkins_last_update", lastUpdated), nil
 	}
 
 	log.WithField("action", "unidle").Infof(
 		"%v (%v) has not elapsed after jenkins last update at %v",
 		c.idleAfter, terminateTime, lastUpdated)
-	return UnIdle, nil
+	return NewVerdict(UnIdle, fmt.Sprintf("%v not elapsed after last jenkins update", c.idleAfter)).
+		At("jenkins_last_update", lastUpdated).ChangesAt(terminateTime), nil
 }
@@ -0,0 +1,101 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+)
+
+// HungBuildAction is what happens to a build which waits on an input step resp. stalled in one stage.
+type HungBuildAction int
+
+const (
+	// KeepBuild keeps Jenkins running for the build, up to the long build timeout.
+	KeepBuild HungBuildAction = iota
+	// IgnoreBuild no longer keeps Jenkins running for the build, so that Jenkins can be idled.
+	IgnoreBuild
+	// CancelBuild cancels the build via the OpenShift API, so that Jenkins can be idled.
+	CancelBuild
+)
+
+func (a HungBuildAction) String() string {
+	return [...]string{"keep", "idle", "cancel"}[a]
+}
+
+// MarshalText marshals the action as its name.
+func (a HungBuildAction) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// ParseHungBuildAction parses the name of a hung build action as used in the configuration,
+// i.e. "keep", "idle" or "cancel".
+func ParseHungBuildAction(s string) (HungBuildAction, error) {
+	switch s {
+	case "keep":
+		return KeepBuild, nil
+	case "idle":
+		return IgnoreBuild, nil
+	case "cancel":
+		return CancelBuild, nil
+	}
+	return KeepBuild, fmt.Errorf("unknown hung build action %q", s)
+}
+
+const (
+	// AwaitingInput is the state of a build waiting on an input step.
+	AwaitingInput = "awaiting-input"
+	// Stalled is the state of a build which has been running the same stage for too long.
+	Stalled = "stalled"
+)
+
+// HungBuild is a build which waits on an input step resp. stalled in one stage for longer than allowed.
+type HungBuild struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Stage is the name of the stage the build is waiting in.
+	Stage  string          `json:"stage,omitempty"`
+	Since  time.Time       `json:"since"`
+	Action HungBuildAction `json:"action"`
+}
+
+// HungBuildPolicy decides what happens to builds which wait on an input step resp. stalled in one stage,
+// based on the pipeline status reported by the Jenkins sync plugin. A timeout of 0 disables the detection.
+type HungBuildPolicy struct {
+	InputTimeout time.Duration
+	InputAction  HungBuildAction
+	StallTimeout time.Duration
+	StallAction  HungBuildAction
+}
+
+// Classify checks whether the given build waits on an input step resp. stalled in its current stage for
+// longer than the respective timeout. The second return value is false if the build is not hung.
+func (p HungBuildPolicy) Classify(build model.Build, now time.Time) (HungBuild, bool) {
+	status, ok := build.PipelineStatus()
+	if !ok || status.Finished() {
+		return HungBuild{}, false
+	}
+
+	stage, ok := status.CurrentStage()
+	if !ok || stage.StartTimeMillis == 0 {
+		return HungBuild{}, false
+	}
+
+	hung := HungBuild{Name: build.Metadata.Name, Stage: stage.Name, Since: stage.StartTime()}
+	waiting := now.Sub(hung.Since)
+	switch {
+	case status.AwaitingInput():
+		if p.InputTimeout <= 0 || waiting < p.InputTimeout {
+			return HungBuild{}, false
+		}
+		hung.State = AwaitingInput
+		hung.Action = p.InputAction
+	default:
+		if p.StallTimeout <= 0 || waiting < p.StallTimeout {
+			return HungBuild{}, false
+		}
+		hung.State = Stalled
+		hung.Action = p.StallAction
+	}
+	return hung, true
+}
@@ -0,0 +1,82 @@
+package condition
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func pipelineBuild(name string, status string, stageStatus string, stageStart time.Time) model.Build {
+	build := model.Build{
+		Metadata: model.Metadata{Name: name},
+		Status:   model.Status{Phase: "Running", StartTimestamp: model.BuildTime{Time: stageStart.Add(-time.Minute)}},
+	}
+	build.Metadata.Annotations.JenkinsStatus = fmt.Sprintf(
+		`{"status": %q, "stages": [{"name": "Build", "status": "SUCCESS", "startTimeMillis": 1},`+
+			` {"name": "Approve", "status": %q, "startTimeMillis": %d}]}`,
+		status, stageStatus, stageStart.UnixNano()/int64(time.Millisecond))
+	return build
+}
+
+func Test_hung_builds_are_classified(t *testing.T) {
+	now := time.Now()
+	policy := HungBuildPolicy{InputTimeout: time.Hour, InputAction: CancelBuild, StallTimeout: 2 * time.Hour, StallAction: IgnoreBuild}
+
+	var tests = []struct {
+		name  string
+		build model.Build
+		hung  bool
+		state string
+	}{
+		{"no pipeline status", model.Build{Metadata: model.Metadata{Name: "foo-1"}}, false, ""},
+		{"recent input", pipelineBuild("foo-1", "PAUSED_PENDING_INPUT", "PAUSED_PENDING_INPUT", now.Add(-time.Minute)), false, ""},
+		{"old input", pipelineBuild("foo-1", "PAUSED_PENDING_INPUT", "PAUSED_PENDING_INPUT", now.Add(-90*time.Minute)), true, AwaitingInput},
+		{"busy stage", pipelineBuild("foo-1", "IN_PROGRESS", "IN_PROGRESS", now.Add(-90*time.Minute)), false, ""},
+		{"stalled stage", pipelineBuild("foo-1", "IN_PROGRESS", "IN_PROGRESS", now.Add(-3*time.Hour)), true, Stalled},
+		{"finished", pipelineBuild("foo-1", "SUCCESS", "SUCCESS", now.Add(-3*time.Hour)), false, ""},
+	}
+
+	for _, test := range tests {
+		hung, ok := policy.Classify(test.build, now)
+		assert.Equal(t, test.hung, ok, "Unexpected classification of %s", test.name)
+		assert.Equal(t, test.state, hung.State, "Unexpected state of %s", test.name)
+	}
+
+	hung, _ := policy.Classify(pipelineBuild("foo-1", "PAUSED_PENDING_INPUT", "PAUSED_PENDING_INPUT", now.Add(-90*time.Minute)), now)
+	assert.Equal(t, CancelBuild, hung.Action)
+	assert.Equal(t, "Approve", hung.Stage)
+
+	_, ok := HungBuildPolicy{}.Classify(pipelineBuild("foo-1", "IN_PROGRESS", "IN_PROGRESS", now.Add(-30*time.Hour)), now)
+	assert.False(t, ok, "Detection should be disabled without timeouts")
+}
+
+func Test_eval_hung_builds(t *testing.T) {
+	now := time.Now()
+	user := model.NewUser("123", "foo")
+	user.StartBuild(pipelineBuild("foo-1", "PAUSED_PENDING_INPUT", "PAUSED_PENDING_INPUT", now.Add(-90*time.Minute)))
+
+	var tests = []struct {
+		action HungBuildAction
+		want   Action
+	}{
+		{KeepBuild, UnIdle},
+		{IgnoreBuild, Idle},
+		{CancelBuild, Idle},
+	}
+
+	for _, test := range tests {
+		condition := NewHungBuildCondition(5*time.Minute, 10*time.Hour, HungBuildPolicy{InputTimeout: time.Hour, InputAction: test.action})
+		result, err := condition.Eval(user)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, result.Action, "Unexpected action for policy %s", test.action)
+	}
+
+	action, err := ParseHungBuildAction("cancel")
+	assert.NoError(t, err)
+	assert.Equal(t, CancelBuild, action)
+	_, err = ParseHungBuildAction("abort")
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// JenkinsActivity is the activity of a Jenkins instance as reported by its REST API.
+type JenkinsActivity struct {
+	QueuedItems   int
+	BusyExecutors int
+}
+
+// JenkinsClient gets the activity of the Jenkins instance of a namespace.
+type JenkinsClient interface {
+	Activity(namespace string) (JenkinsActivity, error)
+}
+
+// URLResolver returns the base URL of the Jenkins instance of the given namespace.
+type URLResolver func(namespace string) (string, error)
+
+type jenkinsClient struct {
+	resolve     URLResolver
+	bearerToken string
+	client      *http.Client
+}
+
+// NewJenkinsClient creates a JenkinsClient which queries the Jenkins REST API at the URL returned by resolve,
+// authenticating with the given bearer token. Each request times out after the given timeout.
+func NewJenkinsClient(resolve URLResolver, bearerToken string, timeout time.Duration) JenkinsClient {
+	return &jenkinsClient{
+		resolve:     resolve,
+		bearerToken: bearerToken,
+		client:      &http.Client{Timeout: timeout},
+	}
+}
+
+// Activity returns the number of items in the build queue and the number of busy executors.
+func (j *jenkinsClient) Activity(namespace string) (JenkinsActivity, error) {
+	baseURL, err := j.resolve(namespace)
+	if err != nil {
+		return JenkinsActivity{}, err
+	}
+
+	queue := struct {
+		Items []struct {
+			ID int `json:"id"`
+		} `json:"items"`
+	}{}
+	if err := j.get(baseURL+"/queue/api/json?tree=items[id]", &queue); err != nil {
+		return JenkinsActivity{}, err
+	}
+
+	computer := struct {
+		BusyExecutors int `json:"busyExecutors"`
+	}{}
+	if err := j.get(baseURL+"/computer/api/json?tree=busyExecutors", &computer); err != nil {
+		return JenkinsActivity{}, err
+	}
+
+	return JenkinsActivity{QueuedItems: len(queue.Items), BusyExecutors: computer.BusyExecutors}, nil
+}
+
+func (j *jenkinsClient) get(url string, v interface{}) error {
+	logger.WithField("url", url).Debug("Accessing Jenkins API.")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+j.bearerToken)
+
+	resp, err := j.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got status %s from %s", resp.Status, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,50 @@
+package condition
+
+import (
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// JenkinsCondition covers activity only visible within Jenkins itself, i.e. queued
+// jobs and busy executors, e.g. of builds started directly in Jenkins.
+type JenkinsCondition struct {
+	client JenkinsClient
+}
+
+// NewJenkinsCondition creates a new instance of JenkinsCondition given a JenkinsClient.
+func NewJenkinsCondition(client JenkinsClient) Condition {
+	return &JenkinsCondition{client: client}
+}
+
+// Eval returns UnIdle while the build queue is not empty or any executor is busy, NoAction otherwise.
+// An unreachable Jenkins, e.g. because it is idled, results in NoAction as well.
+func (c *JenkinsCondition) Eval(object interface{}) (Verdict, error) {
+	u, ok := object.(model.User)
+	if !ok {
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"id":        u.ID,
+		"name":      u.Name,
+		"component": "jenkins-condition",
+	})
+
+	activity, err := c.client.Activity(u.Name + "-jenkins")
+	if err != nil {
+		log.WithField("action", "none").Warnf("jenkins not reachable: %s", err)
+		return NewVerdict(NoAction, "jenkins not reachable"), nil
+	}
+
+	if activity.QueuedItems > 0 || activity.BusyExecutors > 0 {
+		log.WithField("action", "unidle").Infof("jenkins has %d queued items and %d busy executors",
+			activity.QueuedItems, activity.BusyExecutors)
+		return NewVerdict(UnIdle, fmt.Sprintf("jenkins has %d queued items and %d busy executors",
+			activity.QueuedItems, activity.BusyExecutors)), nil
+	}
+
+	log.WithField("action", "none").Info("jenkins has neither queued items nor busy executors")
+	return NewVerdict(NoAction, "jenkins has neither queued items nor busy executors"), nil
+}
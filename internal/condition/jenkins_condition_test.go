@@ -0,0 +1,71 @@
+package condition
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_jenkins_client_reports_activity(t *testing.T) {
+	jenkins := common.NewFakeJenkins("secret")
+	defer jenkins.Close()
+	jenkins.QueuedItems = 2
+	jenkins.BusyExecutors = 1
+
+	var resolved string
+	resolve := func(namespace string) (string, error) {
+		resolved = namespace
+		return jenkins.URL, nil
+	}
+
+	activity, err := NewJenkinsClient(resolve, "secret", time.Second).Activity("foo-jenkins")
+	assert.NoError(t, err)
+	assert.Equal(t, "foo-jenkins", resolved)
+	assert.Equal(t, JenkinsActivity{QueuedItems: 2, BusyExecutors: 1}, activity)
+
+	_, err = NewJenkinsClient(resolve, "wrong", time.Second).Activity("foo-jenkins")
+	assert.Error(t, err, "Unauthorized requests should fail")
+}
+
+func Test_jenkins_condition(t *testing.T) {
+	jenkins := common.NewFakeJenkins("secret")
+	defer jenkins.Close()
+	resolve := func(namespace string) (string, error) {
+		return jenkins.URL, nil
+	}
+	condition := NewJenkinsCondition(NewJenkinsClient(resolve, "secret", time.Second))
+	user := model.User{ID: "42", Name: "foo"}
+
+	var tests = []struct {
+		queued int
+		busy   int
+		want   Action
+	}{
+		{0, 0, NoAction},
+		{1, 0, UnIdle},
+		{0, 2, UnIdle},
+	}
+
+	for _, test := range tests {
+		jenkins.QueuedItems = test.queued
+		jenkins.BusyExecutors = test.busy
+		verdict, err := condition.Eval(user)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, verdict.Action, "Unexpected action for %d queued items and %d busy executors", test.queued, test.busy)
+	}
+}
+
+func Test_unreachable_jenkins_is_ignored(t *testing.T) {
+	resolve := func(namespace string) (string, error) {
+		return "", errors.New("route not found")
+	}
+	condition := NewJenkinsCondition(NewJenkinsClient(resolve, "secret", time.Second))
+
+	verdict, err := condition.Eval(model.User{ID: "42", Name: "foo"})
+	assert.NoError(t, err, "Unreachable Jenkins should not fail the evaluation")
+	assert.Equal(t, NoAction, verdict.Action)
+}
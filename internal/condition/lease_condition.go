@@ -0,0 +1,36 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+)
+
+// Leases provides the keep-alive lease of a Jenkins instance.
+type Leases interface {
+	// ActiveLease returns the expiry and the reason of the lease active at the given point in time,
+	// false if there is none.
+	ActiveLease(now time.Time) (time.Time, string, bool)
+}
+
+// LeaseCondition is an override which keeps Jenkins running while a keep-alive lease is active.
+type LeaseCondition struct {
+	leases Leases
+	now    func() time.Time
+}
+
+// NewLeaseCondition creates a new instance of LeaseCondition given the leases.
+func NewLeaseCondition(leases Leases) Override {
+	return &LeaseCondition{
+		leases: leases,
+		now:    time.Now,
+	}
+}
+
+// Override returns UnIdle while a lease is active, the given result otherwise.
+func (c *LeaseCondition) Override(object interface{}, result Action) (Verdict, error) {
+	expires, reason, ok := c.leases.ActiveLease(c.now())
+	if !ok {
+		return NewVerdict(result, "no active lease"), nil
+	}
+	return NewVerdict(UnIdle, fmt.Sprintf("kept alive: %s", reason)).At("lease_expires", expires).ChangesAt(expires), nil
+}
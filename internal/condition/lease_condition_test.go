@@ -0,0 +1,34 @@
+package condition
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedLease struct {
+	expires time.Time
+}
+
+func (l fixedLease) ActiveLease(now time.Time) (time.Time, string, bool) {
+	return l.expires, "release testing", now.Before(l.expires)
+}
+
+func Test_lease_condition_keeps_jenkins_running_until_expiry(t *testing.T) {
+	now := time.Date(2018, 4, 11, 9, 0, 0, 0, time.UTC)
+	expires := now.Add(time.Hour)
+	c := &LeaseCondition{leases: fixedLease{expires: expires}, now: func() time.Time { return now }}
+
+	verdict, err := c.Override(model.User{}, Idle)
+	assert.NoError(t, err)
+	assert.Equal(t, UnIdle, verdict.Action)
+	assert.Equal(t, "kept alive: release testing", verdict.Reason)
+	assert.Equal(t, expires, *verdict.NextChange)
+
+	now = expires
+	verdict, err = c.Override(model.User{}, Idle)
+	assert.NoError(t, err)
+	assert.Equal(t, Idle, verdict.Action, "Expired lease should not change the result")
+}
@@ -0,0 +1,148 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	metricsStep = time.Minute
+
+	cpuQuery     = `sum(rate(container_cpu_usage_seconds_total{namespace="%s",pod=~"jenkins-[0-9]+-.*",container!=""}[1m])) * 1000`
+	networkQuery = `sum(rate(container_network_receive_bytes_total{namespace="%s",pod=~"jenkins-[0-9]+-.*"}[1m])) + ` +
+		`sum(rate(container_network_transmit_bytes_total{namespace="%s",pod=~"jenkins-[0-9]+-.*"}[1m]))`
+)
+
+// UsageSample is the resource usage of a pod at a point in time.
+type UsageSample struct {
+	Timestamp time.Time
+	// CPU is the CPU usage in millicores.
+	CPU float64
+	// Network is the received and transmitted network traffic in bytes per second.
+	Network float64
+}
+
+// MetricsClient gets the resource usage of the Jenkins pod of a namespace.
+type MetricsClient interface {
+	// Usage returns the resource usage samples of the Jenkins pod of the given namespace over the
+	// given window, ordered by time. Samples are missing for the time the pod was not running.
+	Usage(namespace string, window time.Duration) ([]UsageSample, error)
+}
+
+type prometheusClient struct {
+	metricsURL  string
+	bearerToken string
+	client      *http.Client
+	now         func() time.Time
+}
+
+// NewMetricsClient creates a MetricsClient for the Prometheus compatible query API at the given metrics URL,
+// authenticating with the given bearer token. Each request times out after the given timeout.
+func NewMetricsClient(metricsURL string, bearerToken string, timeout time.Duration) MetricsClient {
+	return &prometheusClient{
+		metricsURL:  metricsURL,
+		bearerToken: bearerToken,
+		client:      &http.Client{Timeout: timeout},
+		now:         time.Now,
+	}
+}
+
+// Usage queries the CPU and network usage with a resolution of one minute.
+func (p *prometheusClient) Usage(namespace string, window time.Duration) ([]UsageSample, error) {
+	end := p.now()
+	start := end.Add(-window)
+
+	cpu, err := p.queryRange(fmt.Sprintf(cpuQuery, namespace), start, end)
+	if err != nil {
+		return nil, err
+	}
+	network, err := p.queryRange(fmt.Sprintf(networkQuery, namespace, namespace), start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make(map[int64]*UsageSample)
+	for ts, v := range cpu {
+		samples[ts] = &UsageSample{Timestamp: time.Unix(ts, 0).UTC(), CPU: v}
+	}
+	for ts, v := range network {
+		if s, ok := samples[ts]; ok {
+			s.Network = v
+		} else {
+			samples[ts] = &UsageSample{Timestamp: time.Unix(ts, 0).UTC(), Network: v}
+		}
+	}
+
+	var result []UsageSample
+	for _, s := range samples {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Timestamp.Before(result[j].Timestamp) })
+	return result, nil
+}
+
+// queryRange returns the values of the single series returned by the given query, by unix timestamp.
+func (p *prometheusClient) queryRange(query string, start time.Time, end time.Time) (map[int64]float64, error) {
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("start", strconv.FormatInt(start.Unix(), 10))
+	params.Set("end", strconv.FormatInt(end.Unix(), 10))
+	params.Set("step", strconv.Itoa(int(metricsStep.Seconds())))
+
+	req, err := http.NewRequest("GET", p.metricsURL+"/api/v1/query_range?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s from %s", resp.Status, p.metricsURL)
+	}
+
+	body := struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+		Data   struct {
+			Result []struct {
+				Values [][2]interface{} `json:"values"`
+			} `json:"result"`
+		} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	if body.Status != "success" {
+		return nil, fmt.Errorf("metrics query failed: %s", body.Error)
+	}
+
+	values := make(map[int64]float64)
+	for _, series := range body.Data.Result {
+		for _, v := range series.Values {
+			ts, ok := v[0].(float64)
+			if !ok {
+				return nil, fmt.Errorf("invalid timestamp %v", v[0])
+			}
+			s, ok := v[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid value %v", v[1])
+			}
+			f, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				return nil, err
+			}
+			values[int64(ts)] += f
+		}
+	}
+	return values, nil
+}
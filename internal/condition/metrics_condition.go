@@ -0,0 +1,84 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// MetricsCondition covers work of Jenkins which is not reported by any build event, based on the
+// resource usage of the Jenkins pod as reported by the cluster metrics.
+type MetricsCondition struct {
+	client           MetricsClient
+	window           time.Duration
+	cpuThreshold     float64
+	networkThreshold float64
+	now              func() time.Time
+}
+
+// NewMetricsCondition creates a new instance of MetricsCondition. Usage of at least cpuThreshold millicores
+// resp. networkThreshold bytes per second during the whole window is considered activity.
+func NewMetricsCondition(client MetricsClient, window time.Duration, cpuThreshold float64, networkThreshold float64) Condition {
+	return &MetricsCondition{
+		client:           client,
+		window:           window,
+		cpuThreshold:     cpuThreshold,
+		networkThreshold: networkThreshold,
+		now:              time.Now,
+	}
+}
+
+// Eval returns UnIdle if the CPU or the network usage of the Jenkins pod has been above the respective
+// threshold for every sample within the window, NoAction otherwise. Unavailable metrics, as well as samples which
+// do not reach back to the start of the window, e.g. of a pod started only recently, result in NoAction.
+func (c *MetricsCondition) Eval(object interface{}) (Verdict, error) {
+	u, ok := object.(model.User)
+	if !ok {
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"id":        u.ID,
+		"name":      u.Name,
+		"component": "metrics-condition",
+	})
+
+	samples, err := c.client.Usage(u.Name+"-jenkins", c.window)
+	if err != nil {
+		log.WithField("action", "none").Warnf("metrics unavailable: %s", err)
+		return NewVerdict(NoAction, "metrics unavailable"), nil
+	}
+
+	if len(samples) == 0 {
+		log.WithField("action", "none").Info("no metrics found")
+		return NewVerdict(NoAction, "no metrics found"), nil
+	}
+
+	first := samples[0].Timestamp
+	if first.After(c.now().Add(-c.window + metricsStep)) {
+		log.WithField("action", "none").Infof("metrics only available since %v", first)
+		return NewVerdict(NoAction, "metrics do not cover the window"), nil
+	}
+
+	cpu, network := true, true
+	for _, s := range samples {
+		cpu = cpu && s.CPU >= c.cpuThreshold
+		network = network && s.Network >= c.networkThreshold
+	}
+
+	switch {
+	case cpu:
+		log.WithField("action", "unidle").Infof("cpu usage above %.0fm since %v", c.cpuThreshold, first)
+		return NewVerdict(UnIdle, fmt.Sprintf("cpu usage above %.0fm for %v", c.cpuThreshold, c.window)).
+			At("usage_since", first), nil
+	case network:
+		log.WithField("action", "unidle").Infof("network usage above %.0fB/s since %v", c.networkThreshold, first)
+		return NewVerdict(UnIdle, fmt.Sprintf("network usage above %.0fB/s for %v", c.networkThreshold, c.window)).
+			At("usage_since", first), nil
+	}
+
+	log.WithField("action", "none").Info("no sustained resource usage")
+	return NewVerdict(NoAction, "no sustained resource usage"), nil
+}
@@ -0,0 +1,87 @@
+package condition
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetricsClient struct {
+	samples []UsageSample
+	err     error
+}
+
+func (f *fakeMetricsClient) Usage(namespace string, window time.Duration) ([]UsageSample, error) {
+	return f.samples, f.err
+}
+
+func Test_metrics_client_merges_cpu_and_network_usage(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query().Get("query")
+		assert.Contains(t, query, `namespace="foo-jenkins"`)
+		value := "300"
+		if strings.Contains(query, "network") {
+			value = "2048"
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[120,"%s"],[60,"%s"]]}]}}`, value, value)
+	}))
+	defer ts.Close()
+
+	samples, err := NewMetricsClient(ts.URL, "secret", time.Second).Usage("foo-jenkins", 10*time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, []UsageSample{
+		{Timestamp: time.Unix(60, 0).UTC(), CPU: 300, Network: 2048},
+		{Timestamp: time.Unix(120, 0).UTC(), CPU: 300, Network: 2048},
+	}, samples)
+
+	_, err = NewMetricsClient(ts.URL, "wrong", time.Second).Usage("foo-jenkins", 10*time.Minute)
+	assert.Error(t, err, "Unauthorized requests should fail")
+}
+
+func Test_metrics_condition(t *testing.T) {
+	user := model.User{ID: "42", Name: "foo"}
+	now := time.Now().UTC()
+	sample := func(ago time.Duration, cpu, network float64) UsageSample {
+		return UsageSample{Timestamp: now.Add(-ago), CPU: cpu, Network: network}
+	}
+
+	var tests = []struct {
+		name    string
+		samples []UsageSample
+		want    Action
+	}{
+		{"no samples", nil, NoAction},
+		{"idle pod", []UsageSample{sample(10*time.Minute, 10, 100), sample(0, 5, 50)}, NoAction},
+		{"sustained cpu", []UsageSample{sample(10*time.Minute, 250, 100), sample(0, 300, 50)}, UnIdle},
+		{"sustained network", []UsageSample{sample(9*time.Minute, 10, 20000), sample(0, 5, 10240)}, UnIdle},
+		{"cpu spike", []UsageSample{sample(10*time.Minute, 1000, 100), sample(0, 5, 50)}, NoAction},
+		{"partial series", []UsageSample{sample(2*time.Minute, 1000, 20000), sample(time.Minute, 1000, 20000)}, NoAction},
+	}
+
+	for _, test := range tests {
+		condition := NewMetricsCondition(&fakeMetricsClient{samples: test.samples}, 10*time.Minute, 200, 10240)
+		condition.(*MetricsCondition).now = func() time.Time { return now }
+		verdict, err := condition.Eval(user)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, verdict.Action, "Unexpected action for %s", test.name)
+	}
+}
+
+func Test_unavailable_metrics_are_ignored(t *testing.T) {
+	condition := NewMetricsCondition(&fakeMetricsClient{err: errors.New("connection refused")}, 10*time.Minute, 200, 10240)
+
+	verdict, err := condition.Eval(model.User{ID: "42", Name: "foo"})
+	assert.NoError(t, err, "Unavailable metrics should not fail the evaluation")
+	assert.Equal(t, NoAction, verdict.Action)
+}
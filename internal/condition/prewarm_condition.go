@@ -0,0 +1,49 @@
+package condition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// Predictor predicts whether there will be activity in the near future.
+type Predictor interface {
+	// Predict returns true if activity is likely shortly after the given point in time.
+	Predict(now time.Time) bool
+}
+
+// PrewarmCondition un-idles Jenkins ahead of time when activity is predicted
+// based on the activity history of the user.
+type PrewarmCondition struct {
+	predictor Predictor
+	now       func() time.Time
+}
+
+// NewPrewarmCondition creates a new instance of PrewarmCondition given a predictor.
+func NewPrewarmCondition(predictor Predictor) Condition {
+	return &PrewarmCondition{
+		predictor: predictor,
+		now:       time.Now,
+	}
+}
+
+// Eval returns UnIdle if activity is predicted, NoAction otherwise.
+func (c *PrewarmCondition) Eval(object interface{}) (Verdict, error) {
+	u, ok := object.(model.User)
+	if !ok {
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
+	}
+
+	if !c.predictor.Predict(c.now()) {
+		return NewVerdict(NoAction, "no activity predicted"), nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":        u.ID,
+		"name":      u.Name,
+		"component": "prewarm-condition",
+	}).Info("activity predicted, pre-warming jenkins")
+	return NewVerdict(UnIdle, "activity predicted"), nil
+}
@@ -0,0 +1,35 @@
+package condition
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedPredictor struct {
+	likely bool
+}
+
+func (p fixedPredictor) Predict(now time.Time) bool {
+	return p.likely
+}
+
+func Test_prewarm_condition_unidles_on_predicted_activity(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	user := model.NewUser("id", "name")
+
+	action, err := NewPrewarmCondition(fixedPredictor{likely: true}).Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, UnIdle, action.Action, "Predicted activity should unidle")
+
+	action, err = NewPrewarmCondition(fixedPredictor{likely: false}).Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, NoAction, action.Action, "No action expected without predicted activity")
+
+	_, err = NewPrewarmCondition(fixedPredictor{}).Eval("foo")
+	assert.Error(t, err, "Only users can be evaluated")
+}
@@ -0,0 +1,81 @@
+package condition
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// retryBackoff is the delay before the first retry, it doubles with each further retry.
+var retryBackoff = 500 * time.Millisecond
+
+// ProxyClient gets the information the Jenkins Proxy keeps about a Jenkins namespace.
+type ProxyClient interface {
+	// Info returns the buffered requests and last visit of the given namespace.
+	Info(namespace string) (*ProxyResponse, error)
+}
+
+type proxyClient struct {
+	proxyURL string
+	retries  int
+	client   *http.Client
+}
+
+// NewProxyClient creates a ProxyClient for the Jenkins Proxy API at the given URL. Each request
+// times out after the given timeout and failed requests are retried up to the given number of times.
+func NewProxyClient(proxyURL string, timeout time.Duration, retries int) ProxyClient {
+	return &proxyClient{
+		proxyURL: proxyURL,
+		retries:  retries,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+// Info queries /api/info/:namespace of the proxy. Network errors and 5xx responses are retried,
+// other responses are not.
+func (p *proxyClient) Info(namespace string) (*ProxyResponse, error) {
+	url := fmt.Sprintf("%s/api/info/%s", p.proxyURL, namespace)
+	log := logger.WithField("url", url)
+
+	var err error
+	backoff := retryBackoff
+	for attempt := 0; attempt <= p.retries; attempt++ {
+		if attempt > 0 {
+			log.Warnf("Retrying proxy request (%d/%d) after error: %s", attempt, p.retries, err)
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		var response *ProxyResponse
+		var retry bool
+		response, retry, err = p.get(url)
+		if err == nil {
+			return response, nil
+		}
+		if !retry {
+			break
+		}
+	}
+	return nil, err
+}
+
+// get performs a single request, the second return value is true if a failed request should be retried.
+func (p *proxyClient) get(url string) (*ProxyResponse, bool, error) {
+	logger.WithField("url", url).Debug("Accessing Proxy API.")
+	resp, err := p.client.Get(url)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, resp.StatusCode >= 500, fmt.Errorf("got status %s from %s", resp.Status, url)
+	}
+
+	proxyResponse := ProxyResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&proxyResponse); err != nil {
+		return nil, false, err
+	}
+	return &proxyResponse, false, nil
+}
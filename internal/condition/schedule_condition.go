@@ -0,0 +1,180 @@
+package condition
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ScheduleIdle forces Jenkins to be idled while the schedule is active.
+	ScheduleIdle = "idle"
+	// ScheduleUnIdle forces Jenkins to be running while the schedule is active.
+	ScheduleUnIdle = "unidle"
+	// ScheduleKeep vetoes idling while the schedule is active, but does not un-idle Jenkins.
+	ScheduleKeep = "keep"
+
+	day = 24 * time.Hour
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule is a recurring weekly time window together with the action to apply while it is active.
+type Schedule struct {
+	Name     string
+	Action   string
+	days     map[time.Weekday]bool
+	from     time.Duration
+	to       time.Duration
+	location *time.Location
+}
+
+// NewSchedule creates a Schedule. days are abbreviated week day names (mon, tue, ...), an
+// empty list means every day. from and to are times of the form HH:MM, empty values denote the
+// start resp. end of the day. If to is before from, the window spans midnight and belongs to the
+// day it starts on. The times are interpreted in the specified time zone.
+func NewSchedule(name string, action string, days []string, from string, to string, timeZone string) (Schedule, error) {
+	s := Schedule{Name: name, Action: action, days: make(map[time.Weekday]bool), to: day}
+
+	switch action {
+	case ScheduleIdle, ScheduleUnIdle, ScheduleKeep:
+	default:
+		return s, fmt.Errorf("schedule %q has unknown action %q", name, action)
+	}
+
+	for _, d := range days {
+		weekday, ok := weekdays[strings.ToLower(d)]
+		if !ok {
+			return s, fmt.Errorf("schedule %q has unknown day %q", name, d)
+		}
+		s.days[weekday] = true
+	}
+
+	var err error
+	if from != "" {
+		if s.from, err = parseTimeOfDay(from); err != nil {
+			return s, err
+		}
+	}
+	if to != "" {
+		if s.to, err = parseTimeOfDay(to); err != nil {
+			return s, err
+		}
+	}
+
+	if s.location, err = time.LoadLocation(timeZone); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// IsActive returns true if the given point in time lies within the schedule.
+func (s Schedule) IsActive(t time.Time) bool {
+	local := t.In(s.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+
+	if s.from <= s.to {
+		return s.hasDay(local.Weekday()) && offset >= s.from && offset < s.to
+	}
+
+	// the window spans midnight
+	if offset >= s.from {
+		return s.hasDay(local.Weekday())
+	}
+	if offset < s.to {
+		return s.hasDay((local.Weekday() + 6) % 7)
+	}
+	return false
+}
+
+// end returns the end of the window the given point in time lies within. It must only be
+// called if the schedule is active at t.
+func (s Schedule) end(t time.Time) time.Time {
+	local := t.In(s.location)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+
+	if s.from > s.to && offset >= s.from {
+		// the window spans midnight and ends tomorrow
+		return midnight.AddDate(0, 0, 1).Add(s.to)
+	}
+	return midnight.Add(s.to)
+}
+
+func (s Schedule) hasDay(d time.Weekday) bool {
+	return len(s.days) == 0 || s.days[d]
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time %q needs to be of the form HH:MM", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// ScheduleCondition overrides the combined result of the activity based conditions
+// while one of its schedules is active. The first active schedule wins.
+type ScheduleCondition struct {
+	schedules []Schedule
+	now       func() time.Time
+}
+
+// NewScheduleCondition creates a new instance of ScheduleCondition given a list of schedules.
+func NewScheduleCondition(schedules []Schedule) Override {
+	return &ScheduleCondition{
+		schedules: schedules,
+		now:       time.Now,
+	}
+}
+
+// Override returns the action of the first active schedule. A "keep" schedule turns an Idle
+// result into NoAction and leaves any other result untouched. If no schedule is active the
+// given result is returned.
+func (c *ScheduleCondition) Override(object interface{}, result Action) (Verdict, error) {
+	u, ok := object.(model.User)
+	if !ok {
+		return NewVerdict(result, "invalid object"), fmt.Errorf("%T is not of type User", object)
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"id":        u.ID,
+		"name":      u.Name,
+		"component": "schedule-condition",
+	})
+
+	now := c.now()
+	for _, s := range c.schedules {
+		if !s.IsActive(now) {
+			continue
+		}
+
+		end := s.end(now)
+		switch s.Action {
+		case ScheduleIdle:
+			log.WithField("action", "idle").Infof("schedule %q forces idle", s.Name)
+			return NewVerdict(Idle, fmt.Sprintf("schedule %q forces idle", s.Name)).ChangesAt(end), nil
+		case ScheduleUnIdle:
+			log.WithField("action", "unidle").Infof("schedule %q forces unidle", s.Name)
+			return NewVerdict(UnIdle, fmt.Sprintf("schedule %q forces unidle", s.Name)).ChangesAt(end), nil
+		case ScheduleKeep:
+			if result == Idle {
+				log.WithField("action", "none").Infof("schedule %q vetoes idle", s.Name)
+				return NewVerdict(NoAction, fmt.Sprintf("schedule %q vetoes idle", s.Name)).ChangesAt(end), nil
+			}
+			return NewVerdict(result, fmt.Sprintf("schedule %q keeps jenkins", s.Name)).ChangesAt(end), nil
+		}
+	}
+	return NewVerdict(result, "no active schedule"), nil
+}
@@ -0,0 +1,114 @@
+package condition
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_new_schedule_rejects_invalid_input(t *testing.T) {
+	_, err := NewSchedule("bad-action", "sleep", nil, "", "", "UTC")
+	assert.Error(t, err, "Unknown action should be rejected")
+
+	_, err = NewSchedule("bad-day", ScheduleIdle, []string{"someday"}, "", "", "UTC")
+	assert.Error(t, err, "Unknown day should be rejected")
+
+	_, err = NewSchedule("bad-time", ScheduleIdle, nil, "25:00", "", "UTC")
+	assert.Error(t, err, "Invalid time should be rejected")
+
+	_, err = NewSchedule("bad-zone", ScheduleIdle, nil, "", "", "Nowhere/Special")
+	assert.Error(t, err, "Invalid time zone should be rejected")
+}
+
+func Test_schedule_is_active(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	nightly, err := NewSchedule("nightly", ScheduleIdle, nil, "22:00", "06:00", "Europe/Berlin")
+	require.NoError(t, err)
+
+	workingHours, err := NewSchedule("working-hours", ScheduleKeep,
+		[]string{"mon", "tue", "wed", "thu", "fri"}, "09:00", "18:00", "Europe/Berlin")
+	require.NoError(t, err)
+
+	weekend, err := NewSchedule("weekend", ScheduleIdle, []string{"sat", "sun"}, "", "", "UTC")
+	require.NoError(t, err)
+
+	// Friday nights spanning into Saturday morning belong to Friday
+	fridayLateNight := time.Date(2018, time.June, 1, 23, 30, 0, 0, berlin)
+	saturdayEarly := time.Date(2018, time.June, 2, 5, 59, 0, 0, berlin)
+	mondayMorning := time.Date(2018, time.June, 4, 9, 0, 0, 0, berlin)
+	mondayEvening := time.Date(2018, time.June, 4, 18, 0, 0, 0, berlin)
+	sundayNoon := time.Date(2018, time.June, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		schedule Schedule
+		t        time.Time
+		active   bool
+	}{
+		{nightly, fridayLateNight, true},
+		{nightly, saturdayEarly, true},
+		{nightly, mondayMorning, false},
+		{nightly, fridayLateNight.UTC(), true},
+		{workingHours, mondayMorning, true},
+		{workingHours, mondayEvening, false},
+		{workingHours, sundayNoon, false},
+		{weekend, sundayNoon, true},
+		{weekend, mondayMorning, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.active, test.schedule.IsActive(test.t),
+			"Unexpected result for schedule %s at %v", test.schedule.Name, test.t)
+	}
+}
+
+func Test_schedule_condition_overrides_result(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	forceIdle, _ := NewSchedule("force-idle", ScheduleIdle, nil, "00:00", "12:00", "UTC")
+	forceUnIdle, _ := NewSchedule("force-unidle", ScheduleUnIdle, nil, "12:00", "18:00", "UTC")
+	keep, _ := NewSchedule("keep", ScheduleKeep, nil, "18:00", "", "UTC")
+
+	condition := &ScheduleCondition{schedules: []Schedule{forceIdle, forceUnIdle, keep}}
+	user := model.NewUser("id", "name")
+
+	tests := []struct {
+		hour     int
+		result   Action
+		expected Action
+		end      time.Time
+	}{
+		{8, UnIdle, Idle, time.Date(2018, time.June, 4, 12, 0, 0, 0, time.UTC)},
+		{14, Idle, UnIdle, time.Date(2018, time.June, 4, 18, 0, 0, 0, time.UTC)},
+		{20, Idle, NoAction, time.Date(2018, time.June, 5, 0, 0, 0, 0, time.UTC)},
+		{20, UnIdle, UnIdle, time.Date(2018, time.June, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		condition.now = func() time.Time {
+			return time.Date(2018, time.June, 4, test.hour, 0, 0, 0, time.UTC)
+		}
+		verdict, err := condition.Override(user, test.result)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, verdict.Action, "Unexpected action at %d:00 for result %s", test.hour, test.result)
+		assert.Equal(t, test.end, *verdict.NextChange, "Unexpected end of schedule at %d:00", test.hour)
+	}
+}
+
+func Test_no_active_schedule_keeps_result(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	conditions := NewConditions()
+	conditions.Add("idle", &IdleCondition{})
+	conditions.AddOverride("schedule", NewScheduleCondition([]Schedule{}))
+
+	result, err := conditions.Eval(model.NewUser("id", "name"))
+	assert.NoError(t, err.ToError(), "No error expected.")
+	assert.Equal(t, Idle, result.Action, "Should evaluate to Idle.")
+}
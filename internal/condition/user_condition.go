@@ -1,11 +1,7 @@
 package condition
 
 import (
-	"encoding/json"
-	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
@@ -25,24 +21,27 @@ type ProxyResponse struct {
 // UserCondition covers information about User as provided by the Jenkins Proxy.
 type UserCondition struct {
 	idleAfter time.Duration
-	proxyURL  string
+	client    ProxyClient
+	veto      bool
 }
 
-// NewUserCondition creates a new instance of Condition given a proxyURL and idleAfter.
-func NewUserCondition(proxyURL string, idleAfter time.Duration) Condition {
+// NewUserCondition creates a new instance of Condition given a ProxyClient and idleAfter. If veto is true
+// an unavailable proxy prevents any decision, otherwise the condition is ignored in this case.
+func NewUserCondition(client ProxyClient, idleAfter time.Duration, veto bool) Condition {
 	b := &UserCondition{
-		proxyURL:  proxyURL,
+		client:    client,
 		idleAfter: idleAfter,
+		veto:      veto,
 	}
 	return b
 }
 
 // Eval returns true if there are no buffered request, the last forwarded request occurred more than UserCondition.idleAfter
 // minutes ago and the user accessed the Jenkins UI more than UserCondition.idleAfter minutes ago.
-func (c *UserCondition) Eval(object interface{}) (Action, error) {
+func (c *UserCondition) Eval(object interface{}) (Verdict, error) {
 	u, ok := object.(model.User)
 	if !ok {
-		return NoAction, fmt.Errorf("%T is not of type User", object)
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
 	}
 
 	log := logger.WithFields(logrus.Fields{
@@ -51,16 +50,19 @@ func (c *UserCondition) Eval(object interface{}) (Action, error) {
 		"component": "user-condition",
 	})
 
-	proxyResponse, err := c.getProxyResponse(u.Name)
+	proxyResponse, err := c.client.Info(u.Name + "-jenkins")
 	if err != nil {
-		log.WithField("action", "none").Errorf("proxy returned error: %s", err)
-		return NoAction, err
+		if c.veto {
+			log.WithField("action", "none").Errorf("proxy unavailable, vetoing decision: %s", err)
+			return NewVerdict(NoAction, "jenkins proxy unavailable"), fmt.Errorf("jenkins proxy unavailable: %s", err)
+		}
+		log.WithField("action", "none").Warnf("proxy unavailable, ignoring: %s", err)
+		return NewVerdict(NoAction, "jenkins proxy unavailable, ignored"), nil
 	}
-
 	if proxyResponse.Requests > 0 {
 		log.WithField("action", "unidle").Infof(
 			"proxy is still serving requests %d", proxyResponse.Requests)
-		return UnIdle, nil
+		return NewVerdict(UnIdle, fmt.Sprintf("proxy is buffering %d requests", proxyResponse.Requests)), nil
 	}
 
 	lv := time.Unix(proxyResponse.LastVisit, 0)
@@ -79,39 +81,17 @@ func (c *UserCondition) Eval(object interface{}) (Action, error) {
 		log.WithField("action", "idle").Infof(
 			"%v (%v) has elapsed after last visit: %v last request: %v",
 			c.idleAfter, now, lv, lr)
-		return Idle, nil
+		return NewVerdict(Idle, fmt.Sprintf("%v elapsed after last visit and request", c.idleAfter)).
+			At("last_visit", lv).At("last_request", lr), nil
 	}
 
 	log.WithField("action", "idle").Infof(
 		"%v (%v) has not elapsed after last visit: %v last request: %v",
 		c.idleAfter, now, lv, lr)
-	return UnIdle, nil
-}
-
-func (c *UserCondition) getProxyResponse(userName string) (*ProxyResponse, error) {
-	url := fmt.Sprintf("%s/api/info/%s-jenkins", c.proxyURL, userName)
-	logger.WithField("url", url).Debug("Accessing Proxy API.")
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		logger.Error(fmt.Sprintf("Got status %s fom %s", resp.Status, url))
-		return nil, errors.New(resp.Status)
-	}
-
-	proxyResponse := ProxyResponse{}
-	err = json.Unmarshal(body, &proxyResponse)
-	if err != nil {
-		return nil, err
+	next := visitIdleTime
+	if reqIdleTime.After(next) {
+		next = reqIdleTime
 	}
-	return &proxyResponse, err
+	return NewVerdict(UnIdle, fmt.Sprintf("%v not elapsed after last visit or request", c.idleAfter)).
+		At("last_visit", lv).At("last_request", lr).ChangesAt(next), nil
 }
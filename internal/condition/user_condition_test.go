@@ -1,13 +1,27 @@
 package condition
 
 import (
+	"errors"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/common"
 	"github.com/stretchr/testify/assert"
 )
 
+type fakeProxyClient struct {
+	response *ProxyResponse
+	err      error
+}
+
+func (f *fakeProxyClient) Info(namespace string) (*ProxyResponse, error) {
+	return f.response, f.err
+}
+
 func Test_get_proxy_response(t *testing.T) {
 	// TODO(chmouel): Need to get the actual response from the proxy, built this
 	// json from reading proxy code.
@@ -18,13 +32,85 @@ func Test_get_proxy_response(t *testing.T) {
 	srv := common.MockServer(tenantData)
 	defer srv.Close()
 
-	uc := UserCondition{
-		proxyURL: srv.URL,
-	}
+	client := NewProxyClient(srv.URL, time.Second, 0)
 
-	response, err := uc.getProxyResponse("test")
+	response, err := client.Info("test-jenkins")
 	if err != nil {
 		assert.NoError(t, err)
 	}
 	assert.Equal(t, "test", response.Namespace)
 }
+
+func Test_proxy_client_retries_server_errors(t *testing.T) {
+	retryBackoff = time.Millisecond
+	defer func() { retryBackoff = 500 * time.Millisecond }()
+
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"namespace": "test-jenkins"}`))
+	}))
+	defer srv.Close()
+
+	response, err := NewProxyClient(srv.URL, time.Second, 2).Info("test-jenkins")
+	assert.NoError(t, err)
+	assert.Equal(t, "test-jenkins", response.Namespace)
+	assert.Equal(t, 3, attempts, "Unexpected number of attempts")
+
+	attempts = 0
+	_, err = NewProxyClient(srv.URL, time.Second, 1).Info("test-jenkins")
+	assert.Error(t, err, "Retries should be exhausted")
+	assert.Equal(t, 2, attempts, "Unexpected number of attempts")
+}
+
+func Test_proxy_client_does_not_retry_client_errors(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	_, err := NewProxyClient(srv.URL, time.Second, 3).Info("test-jenkins")
+	assert.Error(t, err)
+	assert.Equal(t, 1, attempts, "Client errors should not be retried")
+}
+
+func Test_user_condition(t *testing.T) {
+	now := time.Now().Unix()
+	longAgo := time.Now().Add(-2 * time.Hour).Unix()
+	user := model.User{ID: "100", Name: "test"}
+
+	var tests = []struct {
+		response *ProxyResponse
+		want     Action
+	}{
+		{&ProxyResponse{Requests: 1, LastVisit: longAgo, LastRequest: longAgo}, UnIdle},
+		{&ProxyResponse{LastVisit: now, LastRequest: longAgo}, UnIdle},
+		{&ProxyResponse{LastVisit: longAgo, LastRequest: longAgo}, Idle},
+	}
+
+	for _, test := range tests {
+		c := NewUserCondition(&fakeProxyClient{response: test.response}, time.Hour, false)
+		action, err := c.Eval(user)
+		assert.NoError(t, err)
+		assert.Equal(t, test.want, action.Action, "Unexpected action for %+v", test.response)
+	}
+}
+
+func Test_user_condition_proxy_unavailable(t *testing.T) {
+	client := &fakeProxyClient{err: errors.New("connection refused")}
+	user := model.User{ID: "100", Name: "test"}
+
+	action, err := NewUserCondition(client, time.Hour, false).Eval(user)
+	assert.NoError(t, err, "Unavailable proxy should be ignored")
+	assert.Equal(t, NoAction, action.Action)
+
+	action, err = NewUserCondition(client, time.Hour, true).Eval(user)
+	assert.Error(t, err, "Unavailable proxy should veto the decision")
+	assert.Equal(t, NoAction, action.Action)
+}
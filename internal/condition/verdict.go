@@ -0,0 +1,90 @@
+package condition
+
+import (
+	"time"
+)
+
+// Verdict is the result of a single condition together with the reasoning behind it.
+type Verdict struct {
+	Action Action `json:"action"`
+	// Reason explains the action in human readable form.
+	Reason string `json:"reason"`
+	// Timestamps are the points in time the verdict is based on, by name.
+	Timestamps map[string]time.Time `json:"timestamps,omitempty"`
+	// NextChange is the earliest point in time the verdict changes without any new activity,
+	// nil if unknown resp. if it only changes due to new activity.
+	NextChange *time.Time `json:"next_change,omitempty"`
+}
+
+// NewVerdict creates a Verdict with the given action and reason.
+func NewVerdict(action Action, reason string) Verdict {
+	return Verdict{Action: action, Reason: reason}
+}
+
+// At returns a copy of the verdict which records the given point in time under the given name.
+// Zero times are skipped.
+func (v Verdict) At(name string, t time.Time) Verdict {
+	if t.IsZero() {
+		return v
+	}
+
+	timestamps := make(map[string]time.Time, len(v.Timestamps)+1)
+	for k, ts := range v.Timestamps {
+		timestamps[k] = ts
+	}
+	timestamps[name] = t.UTC()
+	v.Timestamps = timestamps
+	return v
+}
+
+// ChangesAt returns a copy of the verdict which changes at the given point in time.
+func (v Verdict) ChangesAt(t time.Time) Verdict {
+	t = t.UTC()
+	v.NextChange = &t
+	return v
+}
+
+// Evaluation is the combined result of all conditions of a Conditions instance.
+type Evaluation struct {
+	Action Action `json:"action"`
+	// Reason explains how the verdicts of the conditions were combined.
+	Reason      string             `json:"reason"`
+	EvaluatedAt time.Time          `json:"evaluated_at"`
+	Conditions  map[string]Verdict `json:"conditions"`
+	// Overrides contains the verdicts of the overrides which changed the combined result.
+	Overrides map[string]Verdict `json:"overrides,omitempty"`
+}
+
+// NextIdle forecasts when Jenkins will be idled if there is no new activity, assuming that Jenkins
+// is idled as soon as no condition asks for it to be running any longer. The second return value is
+// false if no forecast is possible, e.g. because a condition only changes its verdict on new activity.
+func (e Evaluation) NextIdle() (time.Time, bool) {
+	if e.Action == Idle {
+		return e.EvaluatedAt, true
+	}
+
+	var next time.Time
+	blocked := false
+	for _, verdicts := range []map[string]Verdict{e.Conditions, e.Overrides} {
+		for _, v := range verdicts {
+			if v.Action == Idle {
+				continue
+			}
+			if v.Action == UnIdle || v.NextChange != nil {
+				blocked = true
+			}
+			if v.Action == UnIdle && v.NextChange == nil {
+				return time.Time{}, false
+			}
+			if v.NextChange != nil && v.NextChange.After(next) {
+				next = *v.NextChange
+			}
+		}
+	}
+
+	if !blocked {
+		// nothing keeps Jenkins running, but nothing asks for it to be idled either
+		return time.Time{}, false
+	}
+	return next, true
+}
@@ -0,0 +1,66 @@
+package condition
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_verdict_builder_does_not_share_timestamps(t *testing.T) {
+	now := time.Now()
+	base := NewVerdict(UnIdle, "reason").At("first", now)
+	extended := base.At("second", now).ChangesAt(now.Add(time.Hour))
+
+	assert.Len(t, base.Timestamps, 1, "Original verdict should not be modified")
+	assert.Nil(t, base.NextChange)
+	assert.Len(t, extended.Timestamps, 2)
+	assert.Len(t, NewVerdict(Idle, "reason").At("zero", time.Time{}).Timestamps, 0, "Zero times should be skipped")
+}
+
+func Test_verdict_json(t *testing.T) {
+	b, err := json.Marshal(NewVerdict(UnIdle, "build in progress"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"action": "unidle", "reason": "build in progress"}`, string(b))
+}
+
+func Test_next_idle_forecast(t *testing.T) {
+	now := time.Now().UTC()
+	soon := now.Add(10 * time.Minute)
+	later := now.Add(time.Hour)
+
+	var tests = []struct {
+		name       string
+		evaluation Evaluation
+		want       *time.Time
+	}{
+		{"idle now", Evaluation{Action: Idle, EvaluatedAt: now}, &now},
+		{"latest unidle verdict wins", Evaluation{Action: UnIdle, EvaluatedAt: now, Conditions: map[string]Verdict{
+			"dc":    NewVerdict(UnIdle, "").ChangesAt(soon),
+			"build": NewVerdict(UnIdle, "").ChangesAt(later),
+			"user":  NewVerdict(Idle, ""),
+		}}, &later},
+		{"override delays idle", Evaluation{Action: NoAction, EvaluatedAt: now,
+			Conditions: map[string]Verdict{"dc": NewVerdict(Idle, "")},
+			Overrides:  map[string]Verdict{"schedule": NewVerdict(NoAction, "").ChangesAt(later)},
+		}, &later},
+		{"unknown end of activity", Evaluation{Action: UnIdle, EvaluatedAt: now, Conditions: map[string]Verdict{
+			"dc":   NewVerdict(UnIdle, "").ChangesAt(soon),
+			"user": NewVerdict(UnIdle, ""),
+		}}, nil},
+		{"nothing to decide", Evaluation{Action: NoAction, EvaluatedAt: now, Conditions: map[string]Verdict{
+			"dc": NewVerdict(NoAction, ""),
+		}}, nil},
+	}
+
+	for _, test := range tests {
+		next, ok := test.evaluation.NextIdle()
+		if test.want == nil {
+			assert.False(t, ok, "No forecast expected for %s", test.name)
+			continue
+		}
+		assert.True(t, ok, "Forecast expected for %s", test.name)
+		assert.Equal(t, *test.want, next, "Unexpected forecast for %s", test.name)
+	}
+}
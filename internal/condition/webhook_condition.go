@@ -0,0 +1,127 @@
+package condition
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/sirupsen/logrus"
+)
+
+// WebhookRequest is posted to the decision webhook.
+type WebhookRequest struct {
+	User model.User `json:"user"`
+	// Cluster is the API URL of the OpenShift cluster the user's Jenkins runs on.
+	Cluster string `json:"cluster"`
+}
+
+// WebhookResponse is expected from the decision webhook. Action is one of "idle", "unidle" or "none".
+type WebhookResponse struct {
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+}
+
+type cachedVerdict struct {
+	verdict   Verdict
+	decidedAt time.Time
+}
+
+// WebhookCondition delegates the decision to an external HTTP endpoint, e.g. to take billing status or
+// on-call rotations into account.
+type WebhookCondition struct {
+	url      string
+	cluster  string
+	client   *http.Client
+	cacheTTL time.Duration
+	failure  Action
+	now      func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]cachedVerdict
+}
+
+// NewWebhookCondition creates a new instance of WebhookCondition which posts to the given URL. Requests time out
+// after the given timeout, decisions are cached per user for cacheTTL. If the webhook fails, the condition
+// results in the given failure action.
+func NewWebhookCondition(url string, cluster string, timeout time.Duration, cacheTTL time.Duration, failure Action) Condition {
+	return &WebhookCondition{
+		url:      url,
+		cluster:  cluster,
+		client:   &http.Client{Timeout: timeout},
+		cacheTTL: cacheTTL,
+		failure:  failure,
+		now:      time.Now,
+		cache:    make(map[string]cachedVerdict),
+	}
+}
+
+// Eval returns the action decided by the webhook for the passed User, resp. the failure action if the
+// webhook is unavailable or returns an invalid response.
+func (c *WebhookCondition) Eval(object interface{}) (Verdict, error) {
+	u, ok := object.(model.User)
+	if !ok {
+		return NewVerdict(NoAction, "invalid object"), fmt.Errorf("%T is not of type User", object)
+	}
+
+	log := logrus.WithFields(logrus.Fields{
+		"id":        u.ID,
+		"name":      u.Name,
+		"component": "webhook-condition",
+	})
+
+	now := c.now()
+	c.lock.Lock()
+	cached, ok := c.cache[u.ID]
+	c.lock.Unlock()
+	if ok && now.Sub(cached.decidedAt) < c.cacheTTL {
+		log.WithField("action", cached.verdict.Action).Debug("using cached decision")
+		return cached.verdict, nil
+	}
+
+	response, err := c.post(u)
+	if err != nil {
+		log.WithField("action", c.failure).Warnf("decision webhook failed: %s", err)
+		return NewVerdict(c.failure, "decision webhook failed"), nil
+	}
+
+	action, err := ParseAction(response.Action)
+	if err != nil {
+		log.WithField("action", c.failure).Warnf("invalid response of decision webhook: %s", err)
+		return NewVerdict(c.failure, "invalid response of decision webhook"), nil
+	}
+
+	verdict := NewVerdict(action, response.Reason).At("decided_at", now)
+	c.lock.Lock()
+	c.cache[u.ID] = cachedVerdict{verdict: verdict, decidedAt: now}
+	c.lock.Unlock()
+
+	log.WithField("action", action).Infof("decision webhook decided: %s", response.Reason)
+	return verdict, nil
+}
+
+func (c *WebhookCondition) post(u model.User) (*WebhookResponse, error) {
+	body, err := json.Marshal(WebhookRequest{User: u, Cluster: c.cluster})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s from %s", resp.Status, c.url)
+	}
+
+	response := WebhookResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,64 @@
+package condition
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_webhook_condition(t *testing.T) {
+	requests := 0
+	response := `{"action": "unidle", "reason": "demo in progress"}`
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		request := WebhookRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "foo", request.User.Name)
+		assert.Equal(t, "https://api.cluster/", request.Cluster)
+		w.Write([]byte(response))
+	}))
+	defer webhook.Close()
+
+	now := time.Now()
+	condition := NewWebhookCondition(webhook.URL, "https://api.cluster/", time.Second, time.Minute, NoAction)
+	condition.(*WebhookCondition).now = func() time.Time { return now }
+	user := model.User{ID: "42", Name: "foo"}
+
+	verdict, err := condition.Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, UnIdle, verdict.Action)
+	assert.Equal(t, "demo in progress", verdict.Reason)
+
+	response = `{"action": "idle", "reason": "demo over"}`
+	verdict, _ = condition.Eval(user)
+	assert.Equal(t, UnIdle, verdict.Action, "Decision should have been cached")
+	assert.Equal(t, 1, requests)
+
+	now = now.Add(2 * time.Minute)
+	verdict, _ = condition.Eval(user)
+	assert.Equal(t, Idle, verdict.Action, "Cached decision should have expired")
+	assert.Equal(t, 2, requests)
+}
+
+func Test_webhook_failure_results_in_failure_action(t *testing.T) {
+	response := `{"action": "maybe"}`
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(response))
+	}))
+	user := model.User{ID: "42", Name: "foo"}
+
+	condition := NewWebhookCondition(webhook.URL, "", time.Second, 0, UnIdle)
+	verdict, err := condition.Eval(user)
+	assert.NoError(t, err, "Failing webhook should not fail the evaluation")
+	assert.Equal(t, UnIdle, verdict.Action, "Invalid response should result in the failure action")
+
+	webhook.Close()
+	verdict, err = condition.Eval(user)
+	assert.NoError(t, err)
+	assert.Equal(t, UnIdle, verdict.Action, "Unavailable webhook should result in the failure action")
+}
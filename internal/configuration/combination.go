@@ -0,0 +1,88 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+)
+
+var (
+	combineModes      = []string{"max", "vote"}
+	combinationResult = []string{"idle", "unidle", "none"}
+)
+
+// Combination defines how the results of the idle conditions ("dc", "build", "user", "prewarm", ...)
+// are combined. The rules are tried in order and the first matching rule decides. If no rule matches,
+// the results are combined according to Combine, either "max" (any un-idle wins) or "vote" (weighted
+// majority, ties keep Jenkins running).
+type Combination struct {
+	Order   []string          `mapstructure:"order"`
+	Combine string            `mapstructure:"combine"`
+	Weights map[string]int    `mapstructure:"weights"`
+	Rules   []CombinationRule `mapstructure:"rules"`
+}
+
+// CombinationRule results in the action Then, one of "idle", "unidle" or "none", if When matches.
+type CombinationRule struct {
+	Name string          `mapstructure:"name"`
+	When CombinationExpr `mapstructure:"when"`
+	Then string          `mapstructure:"then"`
+}
+
+// CombinationExpr either compares the result of a single condition with Is or groups
+// expressions via All (and) resp. Any (or).
+type CombinationExpr struct {
+	Condition string            `mapstructure:"condition"`
+	Is        string            `mapstructure:"is"`
+	All       []CombinationExpr `mapstructure:"all"`
+	Any       []CombinationExpr `mapstructure:"any"`
+}
+
+// Validate checks the combine mode, the weights and all rules.
+func (c Combination) Validate() error {
+	if c.Combine != "" && !util.Contains(combineModes, c.Combine) {
+		return fmt.Errorf("combine needs to be one of %s", strings.Join(combineModes, ", "))
+	}
+
+	for name, weight := range c.Weights {
+		if weight < 0 {
+			return fmt.Errorf("weight of condition %q must not be negative", name)
+		}
+	}
+
+	for _, rule := range c.Rules {
+		if !util.Contains(combinationResult, rule.Then) {
+			return fmt.Errorf("rule %q: then needs to be one of %s", rule.Name, strings.Join(combinationResult, ", "))
+		}
+		if err := rule.When.Validate(); err != nil {
+			return fmt.Errorf("rule %q: %s", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that exactly one of condition, all or any is set and that conditions
+// are compared with a known action.
+func (e CombinationExpr) Validate() error {
+	set := 0
+	for _, ok := range []bool{e.Condition != "", len(e.All) > 0, len(e.Any) > 0} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("expression needs exactly one of condition, all or any")
+	}
+
+	if e.Condition != "" && !util.Contains(combinationResult, e.Is) {
+		return fmt.Errorf("condition %q: is needs to be one of %s", e.Condition, strings.Join(combinationResult, ", "))
+	}
+
+	for _, sub := range append(e.All, e.Any...) {
+		if err := sub.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
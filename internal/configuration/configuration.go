@@ -4,6 +4,25 @@ import (
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
 )
 
+const (
+	// ProxyUnavailableIgnore ignores the Jenkins Proxy if it is unavailable.
+	ProxyUnavailableIgnore = "ignore"
+	// ProxyUnavailableVeto skips the idle check if the Jenkins Proxy is unavailable.
+	ProxyUnavailableVeto = "veto"
+
+	// HungBuildKeep keeps Jenkins running for hung builds, up to the long build timeout.
+	HungBuildKeep = "keep"
+	// HungBuildIdle lets Jenkins be idled while builds are hung.
+	HungBuildIdle = "idle"
+	// HungBuildCancel cancels hung builds, so that Jenkins can be idled.
+	HungBuildCancel = "cancel"
+)
+
+var (
+	hungBuildActions = []string{HungBuildKeep, HungBuildIdle, HungBuildCancel}
+	apiScopes        = []string{"read", "operate", "admin"}
+)
+
 // Configuration defines the configuration options of the Idler.
 type Configuration interface {
 	// GetProxyURL returns the Jenkins Proxy API URL.
@@ -33,6 +52,170 @@ type Configuration interface {
 	// GetDebugMode returns if debug mode should be enabled.
 	GetDebugMode() bool
 
+	// GetDryRun returns true if idling decisions should only be recorded, but never executed.
+	GetDryRun() bool
+
+	// GetDryRunUsers returns a slice of user names for which idling decisions are only recorded, but never executed.
+	GetDryRunUsers() []string
+
+	// GetSchedules returns the time based schedules which override the activity based idling decisions.
+	GetSchedules() []Schedule
+
+	// GetTeams returns a map of team names to the names of the users belonging to the team.
+	GetTeams() map[string][]string
+
+	// GetPrewarm returns true if Jenkins should be un-idled ahead of time when activity is predicted.
+	GetPrewarm() bool
+
+	// GetPrewarmWindow returns the number of minutes ahead of predicted activity Jenkins is un-idled.
+	GetPrewarmWindow() int
+
+	// GetPrewarmThreshold returns the probability of activity above which Jenkins is pre-warmed.
+	GetPrewarmThreshold() float64
+
+	// GetPrewarmMinWeeks returns the number of weeks of activity history needed before any activity is predicted.
+	GetPrewarmMinWeeks() int
+
+	// GetPreIdleWebhook returns the URL notifications are posted to before Jenkins is idled.
+	// If empty, Jenkins is idled without notification and grace period.
+	GetPreIdleWebhook() string
+
+	// GetPreIdleGracePeriod returns the number of minutes between the pre-idle notification and the actual idling.
+	GetPreIdleGracePeriod() int
+
+	// GetSoftIdle returns true if Jenkins should be soft-idled, i.e. shrunk to the soft-idle resources instead of
+	// being scaled down.
+	GetSoftIdle() bool
+
+	// GetSoftIdleUsers returns a slice of user names for which Jenkins is soft-idled.
+	GetSoftIdleUsers() []string
+
+	// GetSoftIdleCPU returns the CPU request and limit of a soft-idled Jenkins.
+	GetSoftIdleCPU() string
+
+	// GetSoftIdleMemory returns the memory request and limit of a soft-idled Jenkins.
+	GetSoftIdleMemory() string
+
+	// GetTiers returns the named idle policies users can be assigned to.
+	GetTiers() []Tier
+
+	// GetDefaultTier returns the name of the tier of users which are not assigned to any tier.
+	GetDefaultTier() string
+
+	// GetUserCondition returns true if the activity reported by the Jenkins Proxy is taken into account.
+	GetUserCondition() bool
+
+	// GetUserConditionIdleAfter returns the number of minutes after the last activity reported by the
+	// Jenkins Proxy before Jenkins is idled.
+	GetUserConditionIdleAfter() int
+
+	// GetProxyTimeout returns the number of seconds after which a request to the Jenkins Proxy times out.
+	GetProxyTimeout() int
+
+	// GetProxyRetries returns the number of times a failed request to the Jenkins Proxy is retried.
+	GetProxyRetries() int
+
+	// GetProxyUnavailable returns how an unavailable Jenkins Proxy is treated, either "ignore" or "veto".
+	GetProxyUnavailable() string
+
+	// GetJenkinsCondition returns true if queued jobs and busy executors reported by the Jenkins REST API
+	// keep Jenkins from being idled.
+	GetJenkinsCondition() bool
+
+	// GetJenkinsTimeout returns the number of seconds after which a request to the Jenkins REST API times out.
+	GetJenkinsTimeout() int
+
+	// GetMetricsCondition returns true if sustained resource usage of the Jenkins pod keeps Jenkins from being idled.
+	GetMetricsCondition() bool
+
+	// GetMetricsWindow returns the number of minutes the resource usage needs to be above the thresholds.
+	GetMetricsWindow() int
+
+	// GetMetricsCPUThreshold returns the CPU usage in millicores above which Jenkins is considered active.
+	GetMetricsCPUThreshold() float64
+
+	// GetMetricsNetworkThreshold returns the network traffic in bytes per second above which Jenkins is considered active.
+	GetMetricsNetworkThreshold() float64
+
+	// GetInputWaitTimeout returns the number of minutes a build may wait on an input step before it is
+	// handled according to GetInputWaitAction. 0 disables the detection.
+	GetInputWaitTimeout() int
+
+	// GetInputWaitAction returns what happens to builds waiting on an input step for too long,
+	// one of "keep", "idle" or "cancel".
+	GetInputWaitAction() string
+
+	// GetStalledStageTimeout returns the number of minutes a build may run the same stage before it is
+	// handled according to GetStalledBuildAction. 0 disables the detection.
+	GetStalledStageTimeout() int
+
+	// GetStalledBuildAction returns what happens to stalled builds, one of "keep", "idle" or "cancel".
+	GetStalledBuildAction() string
+
+	// GetDecisionWebhook returns the URL of an external endpoint deciding about idling, empty if disabled.
+	GetDecisionWebhook() string
+
+	// GetDecisionWebhookTimeout returns the timeout in seconds for requests to the decision webhook.
+	GetDecisionWebhookTimeout() int
+
+	// GetDecisionWebhookCache returns the number of seconds a decision of the webhook is cached.
+	GetDecisionWebhookCache() int
+
+	// GetDecisionWebhookFailure returns the action taken if the decision webhook fails, one of "idle", "unidle" or "none"
+	// (default). Other values are rejected by Verify.
+	GetDecisionWebhookFailure() string
+
+	// GetAPIAuth returns true if the REST API requires bearer tokens.
+	GetAPIAuth() bool
+
+	// GetAPIServiceAccounts returns the fabric8-auth service accounts allowed to use the REST API
+	// together with their scope, as name:scope.
+	GetAPIServiceAccounts() []string
+
+	// GetAPITokens returns static tokens allowed to use the REST API together with their scope, as token:scope.
+	GetAPITokens() []string
+
+	// GetAPITokenIssuer returns the issuer service account tokens need to be issued by, empty if it is not checked.
+	GetAPITokenIssuer() string
+
+	// GetAPITokenAudience returns the audience service account tokens need to be issued for, empty if it is not checked.
+	GetAPITokenAudience() string
+
+	// GetBulkConcurrency returns how many Jenkins instances per cluster a bulk operation idles resp. un-idles concurrently.
+	GetBulkConcurrency() int
+
+	// GetUnIdleTimeout returns the time in minutes Jenkins may take to become ready after an un-idle request,
+	// before the un-idle operation is reported as failed.
+	GetUnIdleTimeout() int
+
+	// GetMaxKeepAlive returns the maximal duration in minutes of a keep-alive lease.
+	GetMaxKeepAlive() int
+
+	// GetDisabledUsersConfigMap returns the config map the disabled users are persisted to as namespace/name.
+	// If empty, the disabled users are only kept in memory.
+	GetDisabledUsersConfigMap() string
+
+	// GetDisabledUsersCluster returns the API URL of the OpenShift cluster of the disabled users config map.
+	GetDisabledUsersCluster() string
+
+	// GetDisabledUsersRefresh returns the interval in seconds in which the disabled users are reloaded from the
+	// config map, picking up the changes of other replicas.
+	GetDisabledUsersRefresh() int
+
+	// GetLeasesConfigMap returns the config map the keep-alive leases are persisted to as namespace/name.
+	// If empty, the leases are only kept in memory.
+	GetLeasesConfigMap() string
+
+	// GetLeasesCluster returns the API URL of the OpenShift cluster of the leases config map.
+	GetLeasesCluster() string
+
+	// GetLeasesRefresh returns the interval in seconds in which the keep-alive leases are reloaded from the
+	// config map, picking up the leases taken and revoked by other replicas.
+	GetLeasesRefresh() int
+
+	// GetCombination returns how the results of the idle conditions are combined.
+	GetCombination() Combination
+
 	// GetFixedUuids returns a slice of fixed user uuids. If set, a custom Features implementation is instantiated
 	// which only enabled the idler feature for the specified list of users. This is mainly used for local dev only.
 	GetFixedUuids() []string
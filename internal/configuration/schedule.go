@@ -0,0 +1,90 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+)
+
+var (
+	scheduleActions = []string{"idle", "unidle", "keep"}
+	scheduleDays    = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// Schedule defines a recurring time window during which the activity based idling
+// decisions are overridden. Action is one of "idle" (force idle), "unidle" (force running)
+// or "keep" (never idle). A schedule applies to all users unless it is restricted
+// to specific users, teams or clusters.
+type Schedule struct {
+	Name     string   `mapstructure:"name"`
+	Action   string   `mapstructure:"action"`
+	Days     []string `mapstructure:"days"`
+	From     string   `mapstructure:"from"`
+	To       string   `mapstructure:"to"`
+	TimeZone string   `mapstructure:"timezone"`
+	Users    []string `mapstructure:"users"`
+	Teams    []string `mapstructure:"teams"`
+	Clusters []string `mapstructure:"clusters"`
+}
+
+// Validate checks that the schedule has a known action, known days, valid
+// times of the form HH:MM and a valid time zone.
+func (s Schedule) Validate() error {
+	if !util.Contains(scheduleActions, s.Action) {
+		return fmt.Errorf("schedule %q: action needs to be one of %s", s.Name, strings.Join(scheduleActions, ", "))
+	}
+
+	for _, day := range s.Days {
+		if !util.Contains(scheduleDays, strings.ToLower(day)) {
+			return fmt.Errorf("schedule %q: unknown day %q", s.Name, day)
+		}
+	}
+
+	for _, t := range []string{s.From, s.To} {
+		if t == "" {
+			continue
+		}
+		if _, err := time.Parse("15:04", t); err != nil {
+			return fmt.Errorf("schedule %q: time %q needs to be of the form HH:MM", s.Name, t)
+		}
+	}
+
+	if _, err := time.LoadLocation(s.TimeZone); err != nil {
+		return fmt.Errorf("schedule %q: invalid time zone %q: %s", s.Name, s.TimeZone, err)
+	}
+	return nil
+}
+
+// AppliesTo returns true if the schedule applies to the user with the specified name
+// on the cluster with the specified API URL. teams maps team names to the names of their members.
+func (s Schedule) AppliesTo(user string, clusterURL string, teams map[string][]string) bool {
+	if len(s.Clusters) > 0 && !containsURL(s.Clusters, clusterURL) {
+		return false
+	}
+
+	if len(s.Users) == 0 && len(s.Teams) == 0 {
+		return true
+	}
+
+	if util.Contains(s.Users, user) {
+		return true
+	}
+
+	for _, team := range s.Teams {
+		if util.Contains(teams[team], user) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsURL(urls []string, url string) bool {
+	for _, u := range urls {
+		if strings.TrimSuffix(u, "/") == strings.TrimSuffix(url, "/") {
+			return true
+		}
+	}
+	return false
+}
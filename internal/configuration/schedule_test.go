@@ -0,0 +1,54 @@
+package configuration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedule_Validate(t *testing.T) {
+	tests := []struct {
+		schedule Schedule
+		valid    bool
+	}{
+		{Schedule{Name: "nightly", Action: "idle", From: "22:00", To: "06:00", TimeZone: "Europe/Berlin"}, true},
+		{Schedule{Name: "weekend", Action: "idle", Days: []string{"Sat", "sun"}}, true},
+		{Schedule{Name: "bad-action", Action: "sleep"}, false},
+		{Schedule{Name: "bad-day", Action: "keep", Days: []string{"monday"}}, false},
+		{Schedule{Name: "bad-time", Action: "keep", From: "9am"}, false},
+		{Schedule{Name: "bad-zone", Action: "keep", TimeZone: "Nowhere/Special"}, false},
+	}
+
+	for _, test := range tests {
+		err := test.schedule.Validate()
+		if test.valid {
+			assert.NoError(t, err, "Schedule %s should be valid", test.schedule.Name)
+		} else {
+			assert.Error(t, err, "Schedule %s should be invalid", test.schedule.Name)
+		}
+	}
+}
+
+func TestSchedule_AppliesTo(t *testing.T) {
+	teams := map[string][]string{"build-team": {"alice", "bob"}}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		user     string
+		cluster  string
+		applies  bool
+	}{
+		{"everyone", Schedule{}, "carol", "https://api.cluster/", true},
+		{"user", Schedule{Users: []string{"carol"}}, "carol", "https://api.cluster/", true},
+		{"other user", Schedule{Users: []string{"carol"}}, "alice", "https://api.cluster/", false},
+		{"team", Schedule{Teams: []string{"build-team"}}, "alice", "https://api.cluster/", true},
+		{"other team", Schedule{Teams: []string{"build-team"}}, "carol", "https://api.cluster/", false},
+		{"cluster", Schedule{Clusters: []string{"https://api.cluster"}}, "carol", "https://api.cluster/", true},
+		{"other cluster", Schedule{Clusters: []string{"https://api.other/"}}, "carol", "https://api.cluster/", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.applies, test.schedule.AppliesTo(test.user, test.cluster, teams), test.name)
+	}
+}
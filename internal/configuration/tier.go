@@ -0,0 +1,47 @@
+package configuration
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+)
+
+const (
+	// IdleActionScale idles Jenkins by scaling it down to 0 replicas.
+	IdleActionScale = "scale"
+	// IdleActionSoft idles Jenkins by shrinking its resources.
+	IdleActionSoft = "soft"
+)
+
+var idleActions = []string{IdleActionScale, IdleActionSoft}
+
+// Tier is a named idle policy, e.g. for free, team or enterprise accounts. Zero values resp. an empty
+// idle action leave the corresponding global setting unchanged.
+type Tier struct {
+	Name string `mapstructure:"name"`
+	// IdleAfter is the number of minutes before Jenkins is idled.
+	IdleAfter int `mapstructure:"idle_after"`
+	// IdleLongBuild is the number of hours a long running build keeps Jenkins from being idled.
+	IdleLongBuild int `mapstructure:"idle_long_build"`
+	// MaxRetries is the maximum number of retries to idle resp. un-idle Jenkins.
+	MaxRetries int `mapstructure:"max_retries"`
+	// IdleAction is either "scale" or "soft".
+	IdleAction string `mapstructure:"idle_action"`
+}
+
+// Validate checks that the tier has a name, no negative values and a known idle action.
+func (t Tier) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("tier needs a name")
+	}
+
+	if t.IdleAfter < 0 || t.IdleLongBuild < 0 || t.MaxRetries < 0 {
+		return fmt.Errorf("tier %q: idle_after, idle_long_build and max_retries must not be negative", t.Name)
+	}
+
+	if t.IdleAction != "" && !util.Contains(idleActions, t.IdleAction) {
+		return fmt.Errorf("tier %q: idle_action needs to be one of %s", t.Name, strings.Join(idleActions, ", "))
+	}
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 
 	errs "github.com/pkg/errors"
 	"github.com/spf13/viper"
+	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
 )
@@ -28,12 +29,83 @@ const (
 	checkInterval           = "JC_CHECK_INTERVAL"
 	debugMode               = "JC_DEBUG_MODE"
 	fixedUuids              = "JC_FIXED_UUIDS"
+	dryRun                  = "JC_DRY_RUN"
+	dryRunUsers             = "JC_DRY_RUN_USERS"
+	idleSchedules           = "JC_IDLE_SCHEDULES"
+	teams                   = "JC_TEAMS"
+	prewarm                 = "JC_PREWARM"
+	prewarmWindow           = "JC_PREWARM_WINDOW"
+	prewarmThreshold        = "JC_PREWARM_THRESHOLD"
+	prewarmMinWeeks         = "JC_PREWARM_MIN_WEEKS"
+	preIdleWebhook          = "JC_PRE_IDLE_WEBHOOK"
+	preIdleGracePeriod      = "JC_PRE_IDLE_GRACE_PERIOD"
+	softIdle                = "JC_SOFT_IDLE"
+	softIdleUsers           = "JC_SOFT_IDLE_USERS"
+	softIdleCPU             = "JC_SOFT_IDLE_CPU"
+	softIdleMemory          = "JC_SOFT_IDLE_MEMORY"
+	tiers                   = "JC_TIERS"
+	defaultTier             = "JC_DEFAULT_TIER"
+	userCondition           = "JC_USER_CONDITION"
+	userConditionIdleAfter  = "JC_USER_CONDITION_IDLE_AFTER"
+	proxyTimeout            = "JC_PROXY_TIMEOUT"
+	proxyRetries            = "JC_PROXY_RETRIES"
+	proxyUnavailable        = "JC_PROXY_UNAVAILABLE"
+	conditionCombination    = "JC_CONDITION_COMBINATION"
+	jenkinsCondition        = "JC_JENKINS_CONDITION"
+	jenkinsTimeout          = "JC_JENKINS_TIMEOUT"
+	metricsCondition        = "JC_METRICS_CONDITION"
+	metricsWindow           = "JC_METRICS_WINDOW"
+	metricsCPUThreshold     = "JC_METRICS_CPU_THRESHOLD"
+	metricsNetworkThreshold = "JC_METRICS_NETWORK_THRESHOLD"
+	inputWaitTimeout        = "JC_INPUT_WAIT_TIMEOUT"
+	inputWaitAction         = "JC_INPUT_WAIT_ACTION"
+	stalledStageTimeout     = "JC_STALLED_STAGE_TIMEOUT"
+	stalledBuildAction      = "JC_STALLED_BUILD_ACTION"
+	decisionWebhook         = "JC_DECISION_WEBHOOK"
+	decisionWebhookTimeout  = "JC_DECISION_WEBHOOK_TIMEOUT"
+	decisionWebhookCache    = "JC_DECISION_WEBHOOK_CACHE"
+	decisionWebhookFailure  = "JC_DECISION_WEBHOOK_FAILURE"
+	apiAuth                 = "JC_API_AUTH"
+	apiServiceAccounts      = "JC_API_SERVICE_ACCOUNTS"
+	apiTokens               = "JC_API_TOKENS"
+	apiTokenIssuer          = "JC_API_TOKEN_ISSUER"
+	apiTokenAudience        = "JC_API_TOKEN_AUDIENCE"
+	bulkConcurrency         = "JC_BULK_CONCURRENCY"
+	unIdleTimeout           = "JC_UNIDLE_TIMEOUT"
+	maxKeepAlive            = "JC_MAX_KEEPALIVE"
+	disabledUsersConfigMap  = "JC_DISABLED_USERS_CONFIGMAP"
+	disabledUsersCluster    = "JC_DISABLED_USERS_CLUSTER"
+	disabledUsersRefresh    = "JC_DISABLED_USERS_REFRESH"
+	leasesConfigMap         = "JC_LEASES_CONFIGMAP"
+	leasesCluster           = "JC_LEASES_CLUSTER"
+	leasesRefresh           = "JC_LEASES_REFRESH"
 
 	defaultIdleLongBuild           = 3
 	defaultIdleAfter               = 45
 	defaultMaxRetries              = 10
 	defaultMaxRetriesQuietInterval = 30
 	defaultCheckInterval           = 15
+	defaultPrewarmWindow           = 30
+	defaultPrewarmThreshold        = 0.5
+	defaultPrewarmMinWeeks         = 2
+	defaultPreIdleGracePeriod      = 10
+	defaultSoftIdleCPU             = "100m"
+	defaultSoftIdleMemory          = "512Mi"
+	defaultProxyTimeout            = 5
+	defaultProxyRetries            = 2
+	defaultJenkinsTimeout          = 5
+	defaultMetricsWindow           = 10
+	defaultMetricsCPUThreshold     = 200
+	defaultMetricsNetworkThreshold = 10240
+	defaultInputWaitTimeout        = 60
+	defaultStalledStageTimeout     = 120
+	defaultDecisionWebhookTimeout  = 5
+	defaultDecisionWebhookCache    = 60
+	defaultBulkConcurrency         = 5
+	defaultUnIdleTimeout           = 10
+	defaultMaxKeepAlive            = 1440
+	defaultDisabledUsersRefresh    = 30
+	defaultLeasesRefresh           = 30
 )
 
 // New creates a configuration reader object using a configurable configuration
@@ -82,6 +154,52 @@ func (c *Config) setConfigDefaults() {
 
 	c.v.SetDefault(debugMode, false)
 	c.v.SetDefault(fixedUuids, []string{})
+	c.v.SetDefault(dryRun, false)
+	c.v.SetDefault(dryRunUsers, []string{})
+	c.v.SetDefault(prewarm, false)
+	c.v.SetDefault(prewarmWindow, defaultPrewarmWindow)
+	c.v.SetDefault(prewarmThreshold, defaultPrewarmThreshold)
+	c.v.SetDefault(prewarmMinWeeks, defaultPrewarmMinWeeks)
+	c.v.SetDefault(preIdleWebhook, "")
+	c.v.SetDefault(preIdleGracePeriod, defaultPreIdleGracePeriod)
+	c.v.SetDefault(softIdle, false)
+	c.v.SetDefault(softIdleUsers, []string{})
+	c.v.SetDefault(softIdleCPU, defaultSoftIdleCPU)
+	c.v.SetDefault(softIdleMemory, defaultSoftIdleMemory)
+	c.v.SetDefault(defaultTier, "")
+	c.v.SetDefault(userCondition, false)
+	c.v.SetDefault(userConditionIdleAfter, defaultIdleAfter)
+	c.v.SetDefault(proxyTimeout, defaultProxyTimeout)
+	c.v.SetDefault(proxyRetries, defaultProxyRetries)
+	c.v.SetDefault(proxyUnavailable, ProxyUnavailableIgnore)
+	c.v.SetDefault(jenkinsCondition, false)
+	c.v.SetDefault(jenkinsTimeout, defaultJenkinsTimeout)
+	c.v.SetDefault(metricsCondition, false)
+	c.v.SetDefault(metricsWindow, defaultMetricsWindow)
+	c.v.SetDefault(metricsCPUThreshold, defaultMetricsCPUThreshold)
+	c.v.SetDefault(metricsNetworkThreshold, defaultMetricsNetworkThreshold)
+	c.v.SetDefault(inputWaitTimeout, defaultInputWaitTimeout)
+	c.v.SetDefault(inputWaitAction, HungBuildKeep)
+	c.v.SetDefault(stalledStageTimeout, defaultStalledStageTimeout)
+	c.v.SetDefault(stalledBuildAction, HungBuildKeep)
+	c.v.SetDefault(decisionWebhook, "")
+	c.v.SetDefault(decisionWebhookTimeout, defaultDecisionWebhookTimeout)
+	c.v.SetDefault(decisionWebhookCache, defaultDecisionWebhookCache)
+	c.v.SetDefault(decisionWebhookFailure, "none")
+	c.v.SetDefault(apiAuth, false)
+	c.v.SetDefault(apiServiceAccounts, []string{})
+	c.v.SetDefault(apiTokens, []string{})
+	c.v.SetDefault(apiTokenIssuer, "")
+	c.v.SetDefault(apiTokenAudience, "")
+	c.v.SetDefault(bulkConcurrency, defaultBulkConcurrency)
+	c.v.SetDefault(unIdleTimeout, defaultUnIdleTimeout)
+	c.v.SetDefault(maxKeepAlive, defaultMaxKeepAlive)
+	c.v.SetDefault(disabledUsersConfigMap, "")
+	c.v.SetDefault(disabledUsersCluster, "")
+	c.v.SetDefault(disabledUsersRefresh, defaultDisabledUsersRefresh)
+	c.v.SetDefault(leasesConfigMap, "")
+	c.v.SetDefault(leasesCluster, "")
+	c.v.SetDefault(leasesRefresh, defaultLeasesRefresh)
 }
 
 // GetDebugMode returns `true` if development related features (as set via default, config file, or environment variable),
@@ -163,6 +281,294 @@ func (c *Config) GetFixedUuids() []string {
 	return c.v.GetStringSlice(fixedUuids)
 }
 
+// GetDryRun returns true if idling decisions should only be recorded, but never executed.
+func (c *Config) GetDryRun() bool {
+	return c.v.GetBool(dryRun)
+}
+
+// GetDryRunUsers returns a slice of user names for which idling decisions are only recorded.
+// The names are whitespace separated in the environment variable JC_DRY_RUN_USERS.
+func (c *Config) GetDryRunUsers() []string {
+	return c.v.GetStringSlice(dryRunUsers)
+}
+
+// GetSchedules returns the time based schedules which override the activity based idling decisions.
+// Schedules can only be set via the config file.
+func (c *Config) GetSchedules() []Schedule {
+	var schedules []Schedule
+	if err := c.v.UnmarshalKey(idleSchedules, &schedules); err != nil {
+		return nil
+	}
+	return schedules
+}
+
+// GetTeams returns a map of team names to the names of the users belonging to the team.
+// Teams can only be set via the config file.
+func (c *Config) GetTeams() map[string][]string {
+	return c.v.GetStringMapStringSlice(teams)
+}
+
+// GetPrewarm returns true if Jenkins should be un-idled ahead of time when activity is predicted.
+func (c *Config) GetPrewarm() bool {
+	return c.v.GetBool(prewarm)
+}
+
+// GetPrewarmWindow returns the number of minutes ahead of predicted activity Jenkins is un-idled.
+func (c *Config) GetPrewarmWindow() int {
+	return c.v.GetInt(prewarmWindow)
+}
+
+// GetPrewarmThreshold returns the probability (0 < p <= 1) of activity above which Jenkins is pre-warmed.
+func (c *Config) GetPrewarmThreshold() float64 {
+	return c.v.GetFloat64(prewarmThreshold)
+}
+
+// GetPrewarmMinWeeks returns the number of weeks of activity history needed before any activity is predicted.
+func (c *Config) GetPrewarmMinWeeks() int {
+	return c.v.GetInt(prewarmMinWeeks)
+}
+
+// GetPreIdleWebhook returns the URL notifications are posted to before Jenkins is idled.
+// If empty, Jenkins is idled without notification and grace period.
+func (c *Config) GetPreIdleWebhook() string {
+	return c.v.GetString(preIdleWebhook)
+}
+
+// GetPreIdleGracePeriod returns the number of minutes between the pre-idle notification and the actual idling.
+func (c *Config) GetPreIdleGracePeriod() int {
+	return c.v.GetInt(preIdleGracePeriod)
+}
+
+// GetSoftIdle returns true if Jenkins should be soft-idled, i.e. shrunk to the soft-idle resources instead of
+// being scaled down.
+func (c *Config) GetSoftIdle() bool {
+	return c.v.GetBool(softIdle)
+}
+
+// GetSoftIdleUsers returns a slice of user names for which Jenkins is soft-idled.
+// The names are whitespace separated in the environment variable JC_SOFT_IDLE_USERS.
+func (c *Config) GetSoftIdleUsers() []string {
+	return c.v.GetStringSlice(softIdleUsers)
+}
+
+// GetSoftIdleCPU returns the CPU request and limit of a soft-idled Jenkins, e.g. 100m.
+func (c *Config) GetSoftIdleCPU() string {
+	return c.v.GetString(softIdleCPU)
+}
+
+// GetSoftIdleMemory returns the memory request and limit of a soft-idled Jenkins, e.g. 512Mi.
+func (c *Config) GetSoftIdleMemory() string {
+	return c.v.GetString(softIdleMemory)
+}
+
+// GetTiers returns the named idle policies users can be assigned to.
+// Tiers can only be set via the config file.
+func (c *Config) GetTiers() []Tier {
+	var t []Tier
+	if err := c.v.UnmarshalKey(tiers, &t); err != nil {
+		return nil
+	}
+	return t
+}
+
+// GetDefaultTier returns the name of the tier of users which are not assigned to any tier.
+// If empty, the global settings apply to those users.
+func (c *Config) GetDefaultTier() string {
+	return c.v.GetString(defaultTier)
+}
+
+// GetUserCondition returns true if the activity reported by the Jenkins Proxy, i.e. buffered requests
+// and UI visits, is taken into account when deciding whether to idle Jenkins.
+func (c *Config) GetUserCondition() bool {
+	return c.v.GetBool(userCondition)
+}
+
+// GetUserConditionIdleAfter returns the number of minutes after the last request resp. UI visit
+// reported by the Jenkins Proxy before Jenkins is idled.
+func (c *Config) GetUserConditionIdleAfter() int {
+	return c.v.GetInt(userConditionIdleAfter)
+}
+
+// GetProxyTimeout returns the number of seconds after which a request to the Jenkins Proxy times out.
+func (c *Config) GetProxyTimeout() int {
+	return c.v.GetInt(proxyTimeout)
+}
+
+// GetProxyRetries returns the number of times a failed request to the Jenkins Proxy is retried.
+func (c *Config) GetProxyRetries() int {
+	return c.v.GetInt(proxyRetries)
+}
+
+// GetProxyUnavailable returns how an unavailable Jenkins Proxy is treated, either "ignore" or "veto".
+func (c *Config) GetProxyUnavailable() string {
+	return c.v.GetString(proxyUnavailable)
+}
+
+// GetJenkinsCondition returns true if queued jobs and busy executors reported by the Jenkins REST API
+// keep Jenkins from being idled.
+func (c *Config) GetJenkinsCondition() bool {
+	return c.v.GetBool(jenkinsCondition)
+}
+
+// GetJenkinsTimeout returns the number of seconds after which a request to the Jenkins REST API times out.
+func (c *Config) GetJenkinsTimeout() int {
+	return c.v.GetInt(jenkinsTimeout)
+}
+
+// GetMetricsCondition returns true if sustained resource usage of the Jenkins pod, as reported by the
+// cluster metrics, keeps Jenkins from being idled.
+func (c *Config) GetMetricsCondition() bool {
+	return c.v.GetBool(metricsCondition)
+}
+
+// GetMetricsWindow returns the number of minutes the resource usage needs to be above the thresholds.
+func (c *Config) GetMetricsWindow() int {
+	return c.v.GetInt(metricsWindow)
+}
+
+// GetMetricsCPUThreshold returns the CPU usage in millicores above which Jenkins is considered active.
+func (c *Config) GetMetricsCPUThreshold() float64 {
+	return c.v.GetFloat64(metricsCPUThreshold)
+}
+
+// GetMetricsNetworkThreshold returns the network traffic in bytes per second above which Jenkins is considered active.
+func (c *Config) GetMetricsNetworkThreshold() float64 {
+	return c.v.GetFloat64(metricsNetworkThreshold)
+}
+
+// GetInputWaitTimeout returns the number of minutes a build may wait on an input step before it is
+// handled according to GetInputWaitAction. 0 disables the detection.
+func (c *Config) GetInputWaitTimeout() int {
+	return c.v.GetInt(inputWaitTimeout)
+}
+
+// GetInputWaitAction returns what happens to builds waiting on an input step for too long,
+// one of "keep", "idle" or "cancel".
+func (c *Config) GetInputWaitAction() string {
+	return c.v.GetString(inputWaitAction)
+}
+
+// GetStalledStageTimeout returns the number of minutes a build may run the same stage before it is
+// handled according to GetStalledBuildAction. 0 disables the detection.
+func (c *Config) GetStalledStageTimeout() int {
+	return c.v.GetInt(stalledStageTimeout)
+}
+
+// GetStalledBuildAction returns what happens to stalled builds, one of "keep", "idle" or "cancel".
+func (c *Config) GetStalledBuildAction() string {
+	return c.v.GetString(stalledBuildAction)
+}
+
+// GetDecisionWebhook returns the URL of an external endpoint deciding about idling, empty if disabled.
+func (c *Config) GetDecisionWebhook() string {
+	return c.v.GetString(decisionWebhook)
+}
+
+// GetDecisionWebhookTimeout returns the timeout in seconds for requests to the decision webhook.
+func (c *Config) GetDecisionWebhookTimeout() int {
+	return c.v.GetInt(decisionWebhookTimeout)
+}
+
+// GetDecisionWebhookCache returns the number of seconds a decision of the webhook is cached.
+func (c *Config) GetDecisionWebhookCache() int {
+	return c.v.GetInt(decisionWebhookCache)
+}
+
+// GetDecisionWebhookFailure returns the action taken if the decision webhook fails, one of "idle", "unidle" or "none"
+// (default). Other values are rejected by Verify.
+func (c *Config) GetDecisionWebhookFailure() string {
+	return c.v.GetString(decisionWebhookFailure)
+}
+
+// GetAPIAuth returns true if the REST API requires bearer tokens.
+func (c *Config) GetAPIAuth() bool {
+	return c.v.GetBool(apiAuth)
+}
+
+// GetAPIServiceAccounts returns the fabric8-auth service accounts allowed to use the REST API together with
+// their scope, as name:scope. The entries are whitespace separated in the environment variable JC_API_SERVICE_ACCOUNTS.
+func (c *Config) GetAPIServiceAccounts() []string {
+	return c.v.GetStringSlice(apiServiceAccounts)
+}
+
+// GetAPITokens returns static tokens allowed to use the REST API together with their scope, as token:scope.
+// The entries are whitespace separated in the environment variable JC_API_TOKENS.
+func (c *Config) GetAPITokens() []string {
+	return c.v.GetStringSlice(apiTokens)
+}
+
+// GetAPITokenIssuer returns the issuer service account tokens need to be issued by, e.g. https://auth.openshift.io.
+// Empty if the issuer is not checked.
+func (c *Config) GetAPITokenIssuer() string {
+	return c.v.GetString(apiTokenIssuer)
+}
+
+// GetAPITokenAudience returns the audience service account tokens need to be issued for. Empty if the audience
+// is not checked.
+func (c *Config) GetAPITokenAudience() string {
+	return c.v.GetString(apiTokenAudience)
+}
+
+// GetBulkConcurrency returns how many Jenkins instances per cluster a bulk operation idles resp. un-idles concurrently.
+func (c *Config) GetBulkConcurrency() int {
+	return c.v.GetInt(bulkConcurrency)
+}
+
+// GetUnIdleTimeout returns the time in minutes Jenkins may take to become ready after an un-idle request,
+// before the un-idle operation is reported as failed.
+func (c *Config) GetUnIdleTimeout() int {
+	return c.v.GetInt(unIdleTimeout)
+}
+
+// GetMaxKeepAlive returns the maximal duration in minutes of a keep-alive lease.
+func (c *Config) GetMaxKeepAlive() int {
+	return c.v.GetInt(maxKeepAlive)
+}
+
+// GetDisabledUsersConfigMap returns the config map the disabled users are persisted to as namespace/name.
+// If empty, the disabled users are only kept in memory.
+func (c *Config) GetDisabledUsersConfigMap() string {
+	return c.v.GetString(disabledUsersConfigMap)
+}
+
+// GetDisabledUsersCluster returns the API URL of the OpenShift cluster of the disabled users config map.
+func (c *Config) GetDisabledUsersCluster() string {
+	return c.v.GetString(disabledUsersCluster)
+}
+
+// GetDisabledUsersRefresh returns the interval in seconds in which the disabled users are reloaded from the
+// config map, picking up the changes of other replicas.
+func (c *Config) GetDisabledUsersRefresh() int {
+	return c.v.GetInt(disabledUsersRefresh)
+}
+
+// GetLeasesConfigMap returns the config map the keep-alive leases are persisted to as namespace/name.
+// If empty, the leases are only kept in memory.
+func (c *Config) GetLeasesConfigMap() string {
+	return c.v.GetString(leasesConfigMap)
+}
+
+// GetLeasesCluster returns the API URL of the OpenShift cluster of the leases config map.
+func (c *Config) GetLeasesCluster() string {
+	return c.v.GetString(leasesCluster)
+}
+
+// GetLeasesRefresh returns the interval in seconds in which the keep-alive leases are reloaded from the
+// config map, picking up the leases taken and revoked by other replicas.
+func (c *Config) GetLeasesRefresh() int {
+	return c.v.GetInt(leasesRefresh)
+}
+
+// GetCombination returns how the results of the idle conditions are combined.
+// The combination can only be set via the config file.
+func (c *Config) GetCombination() Combination {
+	var combination Combination
+	if err := c.v.UnmarshalKey(conditionCombination, &combination); err != nil {
+		return Combination{}
+	}
+	return combination
+}
+
 // String returns string representation of configuration
 func (c *Config) String() string {
 	all := c.v.AllSettings()
@@ -203,7 +609,153 @@ func (c *Config) Verify() util.MultiError {
 			continue
 		case authGrantType:
 			errors.Collect(util.IsNotEmpty(v, k))
+		case idleSchedules:
+			errors.Collect(c.verifySchedules())
+		case preIdleWebhook:
+			if c.GetPreIdleWebhook() != "" {
+				errors.Collect(util.IsURL(v, k))
+			}
+		case decisionWebhook:
+			if c.GetDecisionWebhook() != "" {
+				errors.Collect(util.IsURL(v, k))
+			}
+		case disabledUsersConfigMap:
+			if cm := c.GetDisabledUsersConfigMap(); cm != "" {
+				if parts := strings.Split(cm, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+					errors.Collect(fmt.Errorf("value for %s needs to be namespace/name", k))
+				}
+				errors.Collect(util.IsURL(c.GetDisabledUsersCluster(), strings.ToLower(disabledUsersCluster)))
+			}
+		case leasesConfigMap:
+			if cm := c.GetLeasesConfigMap(); cm != "" {
+				if parts := strings.Split(cm, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+					errors.Collect(fmt.Errorf("value for %s needs to be namespace/name", k))
+				}
+				errors.Collect(util.IsURL(c.GetLeasesCluster(), strings.ToLower(leasesCluster)))
+			}
+		case apiServiceAccounts, apiTokens:
+			errors.Collect(c.verifyScopedEntries(k))
+		case apiTokenIssuer:
+			if c.GetAPITokenIssuer() != "" {
+				errors.Collect(util.IsURL(v, k))
+			}
+		case decisionWebhookFailure:
+			if !util.Contains(combinationResult, c.GetDecisionWebhookFailure()) {
+				errors.Collect(fmt.Errorf("value for %s needs to be one of %s", k, strings.Join(combinationResult, ", ")))
+			}
+		case decisionWebhookCache:
+			if c.GetDecisionWebhookCache() < 0 {
+				errors.Collect(fmt.Errorf("value for %s must not be negative", k))
+			}
+		case conditionCombination:
+			errors.Collect(c.verifyCombination())
+		case tiers:
+			errors.Collect(c.verifyTiers())
+		case defaultTier:
+			errors.Collect(c.verifyDefaultTier())
+		case softIdleCPU, softIdleMemory:
+			if _, err := resource.ParseQuantity(fmt.Sprintf("%v", v)); err != nil {
+				errors.Collect(fmt.Errorf("value for %s needs to be a resource quantity: %s", k, err))
+			}
+		case proxyUnavailable:
+			if p := c.GetProxyUnavailable(); p != ProxyUnavailableIgnore && p != ProxyUnavailableVeto {
+				errors.Collect(fmt.Errorf("value for %s needs to be %s or %s", k, ProxyUnavailableIgnore, ProxyUnavailableVeto))
+			}
+		case userConditionIdleAfter, proxyTimeout, jenkinsTimeout, metricsWindow, decisionWebhookTimeout, bulkConcurrency, unIdleTimeout, maxKeepAlive, disabledUsersRefresh, leasesRefresh:
+			if c.v.GetInt(k) <= 0 {
+				errors.Collect(fmt.Errorf("value for %s needs to be a positive number", k))
+			}
+		case metricsCPUThreshold, metricsNetworkThreshold:
+			if c.v.GetFloat64(k) <= 0 {
+				errors.Collect(fmt.Errorf("value for %s needs to be a positive number", k))
+			}
+		case inputWaitAction, stalledBuildAction:
+			if a := c.v.GetString(k); !util.Contains(hungBuildActions, a) {
+				errors.Collect(fmt.Errorf("value for %s needs to be one of %s", k, strings.Join(hungBuildActions, ", ")))
+			}
+		case inputWaitTimeout, stalledStageTimeout:
+			if c.v.GetInt(k) < 0 {
+				errors.Collect(fmt.Errorf("value for %s must not be negative", k))
+			}
+		case proxyRetries:
+			if c.GetProxyRetries() < 0 {
+				errors.Collect(fmt.Errorf("value for %s must not be negative", k))
+			}
+		case prewarmThreshold:
+			if t := c.GetPrewarmThreshold(); t <= 0 || t > 1 {
+				errors.Collect(fmt.Errorf("value for %s needs to be larger than 0 and at most 1", k))
+			}
 		}
 	}
 	return errors
 }
+
+func (c *Config) verifyScopedEntries(key string) error {
+	for _, entry := range c.v.GetStringSlice(key) {
+		i := strings.LastIndex(entry, ":")
+		if i <= 0 || !util.Contains(apiScopes, entry[i+1:]) {
+			// the entry itself is not reported as it might be a secret
+			return fmt.Errorf("value for %s needs to be a list of name:scope with scope one of %s", key, strings.Join(apiScopes, ", "))
+		}
+	}
+	return nil
+}
+
+func (c *Config) verifySchedules() error {
+	var schedules []Schedule
+	if err := c.v.UnmarshalKey(idleSchedules, &schedules); err != nil {
+		return fmt.Errorf("value for %s needs to be a list of schedules: %s", idleSchedules, err)
+	}
+
+	for _, s := range schedules {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) verifyTiers() error {
+	var t []Tier
+	if err := c.v.UnmarshalKey(tiers, &t); err != nil {
+		return fmt.Errorf("value for %s needs to be a list of tiers: %s", tiers, err)
+	}
+
+	names := make(map[string]bool)
+	for _, tier := range t {
+		if err := tier.Validate(); err != nil {
+			return err
+		}
+		if names[tier.Name] {
+			return fmt.Errorf("tier %q is defined more than once", tier.Name)
+		}
+		names[tier.Name] = true
+	}
+	return nil
+}
+
+func (c *Config) verifyDefaultTier() error {
+	name := c.GetDefaultTier()
+	if name == "" {
+		return nil
+	}
+
+	for _, tier := range c.GetTiers() {
+		if tier.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("value for %s needs to be the name of a tier", defaultTier)
+}
+
+func (c *Config) verifyCombination() error {
+	var combination Combination
+	if err := c.v.UnmarshalKey(conditionCombination, &combination); err != nil {
+		return fmt.Errorf("value for %s needs to be a condition combination: %s", conditionCombination, err)
+	}
+
+	if err := combination.Validate(); err != nil {
+		return fmt.Errorf("invalid %s: %s", conditionCombination, err)
+	}
+	return nil
+}
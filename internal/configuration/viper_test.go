@@ -1,6 +1,7 @@
 package configuration
 
 import (
+	"io/ioutil"
 	"os"
 	"strconv"
 	"strings"
@@ -119,6 +120,337 @@ func TestConfig_GetFixedUuids(t *testing.T) {
 	assert.Equal(t, c.GetFixedUuids(), want, "FixedUUids Mismatch")
 }
 
+func TestConfig_GetDryRun(t *testing.T) {
+	os.Setenv(dryRun, "true")
+	c, _ := New("")
+	assert.True(t, c.GetDryRun(), "Dry Run should be true")
+	os.Unsetenv(dryRun)
+}
+
+func TestConfig_GetDryRunUsers(t *testing.T) {
+	os.Setenv(dryRunUsers, "foo bar")
+	want := []string{"foo", "bar"}
+	c, _ := New("")
+	assert.Equal(t, want, c.GetDryRunUsers(), "Dry Run Users Mismatch")
+	os.Unsetenv(dryRunUsers)
+}
+
+func TestConfig_GetPrewarm(t *testing.T) {
+	c, _ := New("")
+	assert.False(t, c.GetPrewarm(), "Prewarm should be disabled by default")
+	assert.Equal(t, defaultPrewarmWindow, c.GetPrewarmWindow(), "Prewarm Window Mismatch")
+	assert.Equal(t, defaultPrewarmThreshold, c.GetPrewarmThreshold(), "Prewarm Threshold Mismatch")
+	assert.Equal(t, defaultPrewarmMinWeeks, c.GetPrewarmMinWeeks(), "Prewarm Min Weeks Mismatch")
+
+	os.Setenv(prewarm, "true")
+	os.Setenv(prewarmThreshold, "0.8")
+	c, _ = New("")
+	assert.True(t, c.GetPrewarm(), "Prewarm should be true")
+	assert.Equal(t, 0.8, c.GetPrewarmThreshold(), "Prewarm Threshold Mismatch")
+	os.Unsetenv(prewarm)
+	os.Unsetenv(prewarmThreshold)
+}
+
+func TestConfig_Verify_PrewarmThreshold(t *testing.T) {
+	os.Setenv(prewarmThreshold, "1.5")
+	c, _ := New("")
+	errors := c.Verify()
+	assert.False(t, errors.Empty(), "Threshold larger than 1 should be invalid")
+	os.Unsetenv(prewarmThreshold)
+}
+
+func TestConfig_GetPreIdleWebhook(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, "", c.GetPreIdleWebhook(), "Pre-idle webhook should be disabled by default")
+	assert.Equal(t, defaultPreIdleGracePeriod, c.GetPreIdleGracePeriod(), "Pre-idle Grace Period Mismatch")
+
+	os.Setenv(preIdleWebhook, "not a url")
+	c, _ = New("")
+	errors := c.Verify()
+	assert.False(t, errors.Empty(), "Invalid webhook URL should be rejected")
+
+	os.Setenv(preIdleWebhook, "https://hooks.example.com/idler")
+	c, _ = New("")
+	assert.Equal(t, "https://hooks.example.com/idler", c.GetPreIdleWebhook(), "Pre-idle Webhook Mismatch")
+	os.Unsetenv(preIdleWebhook)
+}
+
+func TestConfig_GetSoftIdle(t *testing.T) {
+	c, _ := New("")
+	assert.False(t, c.GetSoftIdle(), "Soft idle should be disabled by default")
+	assert.Equal(t, defaultSoftIdleCPU, c.GetSoftIdleCPU(), "Soft Idle CPU Mismatch")
+	assert.Equal(t, defaultSoftIdleMemory, c.GetSoftIdleMemory(), "Soft Idle Memory Mismatch")
+
+	os.Setenv(softIdleUsers, "foo bar")
+	os.Setenv(softIdleMemory, "lots")
+	c, _ = New("")
+	assert.Equal(t, []string{"foo", "bar"}, c.GetSoftIdleUsers(), "Soft Idle Users Mismatch")
+	errors := c.Verify()
+	assert.False(t, errors.Empty(), "Invalid memory quantity should be rejected")
+	os.Unsetenv(softIdleUsers)
+	os.Unsetenv(softIdleMemory)
+}
+
+func TestConfig_GetSchedules(t *testing.T) {
+	f, err := ioutil.TempFile("", "idler-config")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString(`
+jc_teams:
+  build-team: [alice, bob]
+jc_idle_schedules:
+- name: nightly
+  action: idle
+  from: "22:00"
+  to: "06:00"
+  timezone: Europe/Berlin
+  teams: [build-team]
+- name: working-hours
+  action: keep
+  days: [mon, tue, wed, thu, fri]
+  from: "09:00"
+  to: "18:00"
+`)
+	f.Close()
+
+	c, err := New(f.Name())
+	assert.NoError(t, err)
+
+	schedules := c.GetSchedules()
+	assert.Len(t, schedules, 2, "Unexpected number of schedules")
+	assert.Equal(t, Schedule{
+		Name: "nightly", Action: "idle", From: "22:00", To: "06:00",
+		TimeZone: "Europe/Berlin", Teams: []string{"build-team"},
+	}, schedules[0])
+	assert.Equal(t, []string{"mon", "tue", "wed", "thu", "fri"}, schedules[1].Days)
+	assert.Equal(t, map[string][]string{"build-team": {"alice", "bob"}}, c.GetTeams())
+	assert.Equal(t, util.MultiError{}, c.Verify(), "Schedules should be valid")
+}
+
+func TestConfig_GetTiers(t *testing.T) {
+	f, err := ioutil.TempFile("", "idler-config")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString(`
+jc_default_tier: free
+jc_tiers:
+- name: free
+  idle_after: 15
+  idle_action: scale
+- name: enterprise
+  idle_after: 240
+  idle_long_build: 8
+  max_retries: 10
+  idle_action: soft
+`)
+	f.Close()
+
+	c, err := New(f.Name())
+	assert.NoError(t, err)
+
+	tiers := c.GetTiers()
+	assert.Len(t, tiers, 2, "Unexpected number of tiers")
+	assert.Equal(t, Tier{Name: "free", IdleAfter: 15, IdleAction: IdleActionScale}, tiers[0])
+	assert.Equal(t, Tier{Name: "enterprise", IdleAfter: 240, IdleLongBuild: 8, MaxRetries: 10, IdleAction: IdleActionSoft}, tiers[1])
+	assert.Equal(t, "free", c.GetDefaultTier())
+	assert.Equal(t, util.MultiError{}, c.Verify(), "Tiers should be valid")
+}
+
+func TestConfig_Verify_Tiers(t *testing.T) {
+	f, err := ioutil.TempFile("", "idler-config")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString(`
+jc_default_tier: team
+jc_tiers:
+- name: free
+  idle_action: hibernate
+`)
+	f.Close()
+
+	c, err := New(f.Name())
+	assert.NoError(t, err)
+
+	errors := c.Verify()
+	assert.Len(t, errors.Errors, 2, "Invalid idle action and unknown default tier should be reported")
+}
+
+func TestConfig_GetUserCondition(t *testing.T) {
+	c, _ := New("")
+	assert.False(t, c.GetUserCondition(), "User condition should be disabled by default")
+	assert.Equal(t, defaultIdleAfter, c.GetUserConditionIdleAfter())
+	assert.Equal(t, defaultProxyTimeout, c.GetProxyTimeout())
+	assert.Equal(t, defaultProxyRetries, c.GetProxyRetries())
+	assert.Equal(t, ProxyUnavailableIgnore, c.GetProxyUnavailable())
+
+	os.Setenv(userCondition, "true")
+	os.Setenv(proxyUnavailable, "panic")
+	c, _ = New("")
+	assert.True(t, c.GetUserCondition())
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(proxyUnavailable), "Unknown policy should be reported")
+	os.Unsetenv(userCondition)
+	os.Unsetenv(proxyUnavailable)
+}
+
+func TestConfig_GetMetricsCondition(t *testing.T) {
+	c, _ := New("")
+	assert.False(t, c.GetMetricsCondition(), "Metrics condition should be disabled by default")
+	assert.Equal(t, defaultMetricsWindow, c.GetMetricsWindow())
+	assert.Equal(t, float64(defaultMetricsCPUThreshold), c.GetMetricsCPUThreshold())
+	assert.Equal(t, float64(defaultMetricsNetworkThreshold), c.GetMetricsNetworkThreshold())
+
+	os.Setenv(metricsCondition, "true")
+	os.Setenv(metricsCPUThreshold, "0")
+	c, _ = New("")
+	assert.True(t, c.GetMetricsCondition())
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(metricsCPUThreshold), "Invalid threshold should be reported")
+	os.Unsetenv(metricsCondition)
+	os.Unsetenv(metricsCPUThreshold)
+}
+
+func TestConfig_GetHungBuildPolicy(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, defaultInputWaitTimeout, c.GetInputWaitTimeout())
+	assert.Equal(t, HungBuildKeep, c.GetInputWaitAction(), "Hung builds should be kept by default")
+	assert.Equal(t, defaultStalledStageTimeout, c.GetStalledStageTimeout())
+	assert.Equal(t, HungBuildKeep, c.GetStalledBuildAction())
+
+	os.Setenv(stalledBuildAction, "abort")
+	c, _ = New("")
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(stalledBuildAction), "Unknown action should be reported")
+	os.Unsetenv(stalledBuildAction)
+}
+
+func TestConfig_GetDecisionWebhook(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, "", c.GetDecisionWebhook(), "Decision webhook should be disabled by default")
+	assert.Equal(t, defaultDecisionWebhookTimeout, c.GetDecisionWebhookTimeout())
+	assert.Equal(t, defaultDecisionWebhookCache, c.GetDecisionWebhookCache())
+	assert.Equal(t, "none", c.GetDecisionWebhookFailure())
+
+	os.Setenv(decisionWebhook, "https://policy.example.com/decide")
+	os.Setenv(decisionWebhookFailure, "panic")
+	c, _ = New("")
+	assert.Equal(t, "https://policy.example.com/decide", c.GetDecisionWebhook())
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(decisionWebhookFailure), "Unknown failure action should be reported")
+	os.Unsetenv(decisionWebhook)
+	os.Unsetenv(decisionWebhookFailure)
+}
+
+func TestConfig_GetAPIAuth(t *testing.T) {
+	c, _ := New("")
+	assert.False(t, c.GetAPIAuth(), "API authentication should be disabled by default")
+	assert.Empty(t, c.GetAPIServiceAccounts())
+	assert.Empty(t, c.GetAPITokens())
+	assert.Empty(t, c.GetAPITokenIssuer(), "Token issuer should not be checked by default")
+	assert.Empty(t, c.GetAPITokenAudience(), "Token audience should not be checked by default")
+
+	os.Setenv(apiAuth, "true")
+	os.Setenv(apiServiceAccounts, "fabric8-jenkins-proxy:operate fabric8-tenant:admin")
+	os.Setenv(apiTokens, "s3cr3t:root")
+	c, _ = New("")
+	assert.True(t, c.GetAPIAuth())
+	assert.Equal(t, []string{"fabric8-jenkins-proxy:operate", "fabric8-tenant:admin"}, c.GetAPIServiceAccounts())
+	errors := c.Verify()
+	assert.Len(t, errors.Errors, 1, "Only the invalid token scope should be reported")
+	assert.NotContains(t, errors.ToError().Error(), "s3cr3t", "Tokens must not be reported")
+	os.Unsetenv(apiAuth)
+	os.Unsetenv(apiServiceAccounts)
+	os.Unsetenv(apiTokens)
+
+	os.Setenv(apiTokenIssuer, "https://auth.openshift.io")
+	os.Setenv(apiTokenAudience, "fabric8-jenkins-idler")
+	c, _ = New("")
+	assert.Equal(t, "https://auth.openshift.io", c.GetAPITokenIssuer())
+	assert.Equal(t, "fabric8-jenkins-idler", c.GetAPITokenAudience())
+	errors = c.Verify()
+	assert.True(t, errors.Empty())
+
+	os.Setenv(apiTokenIssuer, "auth")
+	c, _ = New("")
+	errors = c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(apiTokenIssuer), "Invalid issuer should be reported")
+	os.Unsetenv(apiTokenIssuer)
+	os.Unsetenv(apiTokenAudience)
+}
+
+func TestConfig_GetCombination(t *testing.T) {
+	f, err := ioutil.TempFile("", "idler-config")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+
+	f.WriteString(`
+jc_condition_combination:
+  order: [build, dc]
+  combine: vote
+  weights:
+    build: 2
+  rules:
+  - name: active-build-vetoes-idle
+    when:
+      condition: build
+      is: unidle
+    then: none
+  - name: idle-if-dc-and-user-idle
+    when:
+      all:
+      - condition: dc
+        is: idle
+      - any:
+        - condition: user
+          is: idle
+        - condition: user
+          is: none
+    then: idle
+`)
+	f.Close()
+
+	c, err := New(f.Name())
+	assert.NoError(t, err)
+
+	combination := c.GetCombination()
+	assert.Equal(t, []string{"build", "dc"}, combination.Order)
+	assert.Equal(t, "vote", combination.Combine)
+	assert.Equal(t, 2, combination.Weights["build"])
+	assert.Len(t, combination.Rules, 2, "Unexpected number of rules")
+	assert.Equal(t, CombinationExpr{Condition: "build", Is: "unidle"}, combination.Rules[0].When)
+	assert.Len(t, combination.Rules[1].When.All[1].Any, 2)
+	assert.Equal(t, util.MultiError{}, c.Verify(), "Combination should be valid")
+}
+
+func TestCombination_Validate(t *testing.T) {
+	var tests = []struct {
+		combination Combination
+		valid       bool
+	}{
+		{Combination{}, true},
+		{Combination{Combine: "min"}, false},
+		{Combination{Weights: map[string]int{"dc": -1}}, false},
+		{Combination{Rules: []CombinationRule{{Name: "r", When: CombinationExpr{Condition: "dc", Is: "idle"}, Then: "maybe"}}}, false},
+		{Combination{Rules: []CombinationRule{{Name: "r", When: CombinationExpr{Condition: "dc", Is: "sleeping"}, Then: "idle"}}}, false},
+		{Combination{Rules: []CombinationRule{{Name: "r", When: CombinationExpr{}, Then: "idle"}}}, false},
+		{Combination{Rules: []CombinationRule{{Name: "r", When: CombinationExpr{
+			Condition: "dc", Is: "idle", All: []CombinationExpr{{Condition: "build", Is: "idle"}},
+		}, Then: "idle"}}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.combination.Validate()
+		if test.valid {
+			assert.NoError(t, err, "Combination %+v should be valid", test.combination)
+		} else {
+			assert.Error(t, err, "Combination %+v should be invalid", test.combination)
+		}
+	}
+}
+
 func TestConfig_Verify(t *testing.T) {
 	os.Clearenv()
 	os.Setenv(authTokenKey, "tokenkey")
@@ -163,3 +495,92 @@ func TestConfig_String(t *testing.T) {
 		"jc_service_account_secret:***"),
 		"Service Account Secret isn't ***")
 }
+
+func TestConfig_GetBulkConcurrency(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, defaultBulkConcurrency, c.GetBulkConcurrency())
+
+	os.Setenv(bulkConcurrency, "0")
+	c, _ = New("")
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(bulkConcurrency), "Non-positive concurrency should be reported")
+	os.Unsetenv(bulkConcurrency)
+}
+
+func TestConfig_GetUnIdleTimeout(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, defaultUnIdleTimeout, c.GetUnIdleTimeout())
+
+	os.Setenv(unIdleTimeout, "-1")
+	c, _ = New("")
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(unIdleTimeout), "Non-positive timeout should be reported")
+	os.Unsetenv(unIdleTimeout)
+}
+
+func TestConfig_GetMaxKeepAlive(t *testing.T) {
+	c, _ := New("")
+	assert.Equal(t, defaultMaxKeepAlive, c.GetMaxKeepAlive())
+
+	os.Setenv(maxKeepAlive, "0")
+	c, _ = New("")
+	errors := c.Verify()
+	assert.Contains(t, errors.ToError().Error(), strings.ToLower(maxKeepAlive), "Non-positive maximum should be reported")
+	os.Unsetenv(maxKeepAlive)
+}
+
+func TestConfig_GetDisabledUsersConfigMap(t *testing.T) {
+	c, _ := New("")
+	assert.Empty(t, c.GetDisabledUsersConfigMap())
+	assert.Equal(t, defaultDisabledUsersRefresh, c.GetDisabledUsersRefresh())
+
+	os.Setenv(disabledUsersConfigMap, "jenkins-idler")
+	c, _ = New("")
+	errors := c.Verify().ToError().Error()
+	assert.Contains(t, errors, strings.ToLower(disabledUsersConfigMap), "Config map without namespace should be reported")
+	assert.Contains(t, errors, strings.ToLower(disabledUsersCluster), "Missing cluster should be reported")
+
+	os.Setenv(disabledUsersConfigMap, "dsaas-production/jenkins-idler-disabled-users")
+	os.Setenv(disabledUsersCluster, "https://api.starter-us-east-2.openshift.com")
+	c, _ = New("")
+	assert.Equal(t, "dsaas-production/jenkins-idler-disabled-users", c.GetDisabledUsersConfigMap())
+	assert.Equal(t, "https://api.starter-us-east-2.openshift.com", c.GetDisabledUsersCluster())
+	if errors := c.Verify().ToError(); errors != nil {
+		assert.NotContains(t, errors.Error(), "disabled_users", "Valid config map should not be reported")
+	}
+	os.Unsetenv(disabledUsersConfigMap)
+	os.Unsetenv(disabledUsersCluster)
+}
+
+func TestConfig_GetLeasesConfigMap(t *testing.T) {
+	c, _ := New("")
+	assert.Empty(t, c.GetLeasesConfigMap())
+	assert.Equal(t, defaultLeasesRefresh, c.GetLeasesRefresh())
+
+	os.Setenv(leasesConfigMap, "jenkins-idler")
+	c, _ = New("")
+	errors := c.Verify().ToError().Error()
+	assert.Contains(t, errors, strings.ToLower(leasesConfigMap), "Config map without namespace should be reported")
+	assert.Contains(t, errors, strings.ToLower(leasesCluster), "Missing cluster should be reported")
+
+	os.Setenv(leasesConfigMap, "dsaas-production/jenkins-idler-leases")
+	os.Setenv(leasesCluster, "https://api.starter-us-east-2.openshift.com")
+	c, _ = New("")
+	assert.Equal(t, "dsaas-production/jenkins-idler-leases", c.GetLeasesConfigMap())
+	assert.Equal(t, "https://api.starter-us-east-2.openshift.com", c.GetLeasesCluster())
+	if errors := c.Verify().ToError(); errors != nil {
+		assert.NotContains(t, errors.Error(), "leases", "Valid config map should not be reported")
+	}
+	os.Unsetenv(leasesConfigMap)
+	os.Unsetenv(leasesCluster)
+}
+
+func TestConfig_GetLeasesRefresh(t *testing.T) {
+	for _, value := range []string{"0", "-1"} {
+		os.Setenv(leasesRefresh, value)
+		c, _ := New("")
+		errors := c.Verify()
+		assert.Contains(t, errors.ToError().Error(), strings.ToLower(leasesRefresh), "Non-positive refresh interval should be reported")
+	}
+	os.Unsetenv(leasesRefresh)
+}
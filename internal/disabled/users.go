@@ -0,0 +1,144 @@
+package disabled
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+)
+
+// ConfigMapKey is the key of the config map data under which the entries are stored as JSON.
+const ConfigMapKey = "users.json"
+
+// Entry is a user idling is disabled for.
+type Entry struct {
+	User string `json:"user"`
+	// Reason explains why idling is disabled for the user.
+	Reason string `json:"reason,omitempty"`
+	// Actor is who disabled idling for the user.
+	Actor   string    `json:"actor,omitempty"`
+	Created time.Time `json:"created"`
+	// Expires is when idling is enabled again, nil if never.
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// Expired returns true if the entry has expired at the given point in time.
+func (e Entry) Expired(now time.Time) bool {
+	return e.Expires != nil && !now.Before(*e.Expires)
+}
+
+// Users is the list of users idling is disabled for. The list is cached in memory and kept in sync with
+// the store, so that changes made by other replicas are picked up by Refresh.
+type Users struct {
+	lock    sync.RWMutex
+	entries map[string]Entry
+	store   store.Store
+}
+
+// NewUsers creates the list of disabled users persisted to the given store.
+func NewUsers(s store.Store) *Users {
+	return &Users{entries: make(map[string]Entry), store: s}
+}
+
+// Has returns true if idling is disabled for the given user.
+func (u *Users) Has(user string) bool {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+
+	entry, ok := u.entries[user]
+	return ok && !entry.Expired(time.Now())
+}
+
+// Keys returns the names of the users idling is disabled for in alphabetical order.
+func (u *Users) Keys() []string {
+	var users []string
+	for _, entry := range u.Entries() {
+		users = append(users, entry.User)
+	}
+	return users
+}
+
+// Entries returns the entries of the users idling is disabled for, ordered by user.
+func (u *Users) Entries() []Entry {
+	u.lock.RLock()
+	defer u.lock.RUnlock()
+	return active(u.entries, time.Now())
+}
+
+// Update disables idling for the users of the given entries and enables it for the given users. Enabled
+// users take precedence over disabled ones. The update is retried if the store was changed concurrently.
+func (u *Users) Update(disable []Entry, enable []string) error {
+	var result []Entry
+	err := store.Update(u.store, func(data []byte) (interface{}, error) {
+		entries := []Entry{}
+		if err := store.Decode(data, &entries); err != nil {
+			return nil, err
+		}
+
+		updated := make(map[string]Entry, len(entries)+len(disable))
+		for _, entry := range entries {
+			updated[entry.User] = entry
+		}
+		for _, entry := range disable {
+			updated[entry.User] = entry
+		}
+		for _, user := range enable {
+			delete(updated, user)
+		}
+
+		result = active(updated, time.Now())
+		return result, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	u.set(result)
+	return nil
+}
+
+// Refresh reloads the entries from the store.
+func (u *Users) Refresh() error {
+	data, _, err := u.store.Load()
+	if err != nil {
+		return err
+	}
+	entries := []Entry{}
+	if err := store.Decode(data, &entries); err != nil {
+		return err
+	}
+	u.set(entries)
+	return nil
+}
+
+// Watch refreshes the entries from the store in the given interval until the context is done.
+func (u *Users) Watch(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	store.Watch(ctx, wg, interval, u.Refresh, "disabled users")
+}
+
+func (u *Users) set(entries []Entry) {
+	byUser := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		byUser[entry.User] = entry
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.entries = byUser
+}
+
+// active returns the entries which have not expired at the given point in time, ordered by user.
+func active(entries map[string]Entry, now time.Time) []Entry {
+	result := []Entry{}
+	for _, entry := range entries {
+		if !entry.Expired(now) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].User < result[j].User
+	})
+	return result
+}
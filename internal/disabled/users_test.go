@@ -0,0 +1,49 @@
+package disabled
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_update_disables_and_enables_users(t *testing.T) {
+	users := NewUsers(store.NewMemoryStore())
+
+	err := users.Update([]Entry{{User: "bob", Reason: "debugging"}, {User: "alice"}}, nil)
+	require.NoError(t, err)
+	assert.True(t, users.Has("alice"))
+	assert.Equal(t, []string{"alice", "bob"}, users.Keys())
+	assert.Equal(t, "debugging", users.Entries()[1].Reason)
+
+	err = users.Update([]Entry{{User: "alice"}}, []string{"alice"})
+	require.NoError(t, err)
+	assert.False(t, users.Has("alice"), "Enabled users should take precedence over disabled")
+	assert.Equal(t, []string{"bob"}, users.Keys())
+}
+
+func Test_expired_entries_are_dropped(t *testing.T) {
+	users := NewUsers(store.NewMemoryStore())
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	err := users.Update([]Entry{{User: "alice", Expires: &past}, {User: "bob", Expires: &future}}, nil)
+	require.NoError(t, err)
+	assert.False(t, users.Has("alice"), "Expired entry should not disable idling")
+	assert.True(t, users.Has("bob"))
+	assert.Equal(t, []string{"bob"}, users.Keys())
+}
+
+func Test_refresh_picks_up_changes_of_other_replicas(t *testing.T) {
+	shared := store.NewMemoryStore()
+	replica1 := NewUsers(shared)
+	replica2 := NewUsers(shared)
+
+	require.NoError(t, replica1.Update([]Entry{{User: "alice"}}, nil))
+	assert.False(t, replica2.Has("alice"))
+
+	require.NoError(t, replica2.Refresh())
+	assert.True(t, replica2.Has("alice"))
+}
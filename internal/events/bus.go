@@ -0,0 +1,115 @@
+package events
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// Types of the events published on the Bus.
+const (
+	// Evaluation is published when a UserIdler has evaluated its conditions.
+	Evaluation = "evaluation"
+	// IdleStarted is published when a UserIdler starts to idle Jenkins.
+	IdleStarted = "idle_started"
+	// IdleFinished is published when a UserIdler has idled Jenkins, resp. failed to do so.
+	IdleFinished = "idle_finished"
+	// UnIdleStarted is published when a UserIdler starts to un-idle Jenkins.
+	UnIdleStarted = "unidle_started"
+	// UnIdleFinished is published when a UserIdler has un-idled Jenkins, resp. failed to do so.
+	UnIdleFinished = "unidle_finished"
+	// StateChanged is published when the watch of deployment configs sees Jenkins in a new state.
+	StateChanged = "state_changed"
+)
+
+// subscriberBuffer is the number of events buffered for a subscriber. Events for subscribers
+// with a full buffer are dropped.
+const subscriberBuffer = 100
+
+// Event is a decision or state change of the idler concerning the Jenkins of a namespace.
+type Event struct {
+	Type      string      `json:"type"`
+	Namespace string      `json:"namespace"`
+	Time      time.Time   `json:"time"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// Result is the data of the IdleFinished and UnIdleFinished events.
+type Result struct {
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewResult creates the Result of an action which returned the given error.
+func NewResult(err error) Result {
+	if err != nil {
+		return Result{Error: err.Error()}
+	}
+	return Result{Success: true}
+}
+
+// StateChange is the data of the StateChanged event.
+type StateChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// NewStateChange creates the StateChange from one pod state to another.
+func NewStateChange(from model.PodState, to model.PodState) StateChange {
+	return StateChange{From: from.String(), To: to.String()}
+}
+
+// Bus fans out the published events to all subscribers. Publishing never blocks, so that the
+// idler is not slowed down by slow subscribers.
+type Bus struct {
+	lock        sync.RWMutex
+	subscribers map[chan Event]struct{}
+}
+
+// NewBus creates a new instance of Bus.
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[chan Event]struct{})}
+}
+
+// Publish sends the event to all subscribers. Publishing on a nil Bus does nothing.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	for subscriber := range b.subscribers {
+		select {
+		case subscriber <- event:
+		default:
+			log.WithFields(log.Fields{"component": "events", "type": event.Type, "ns": event.Namespace}).
+				Warn("Dropping event for slow subscriber")
+		}
+	}
+}
+
+// Subscribe returns a channel receiving all events published from now on, and a function
+// to be called to unsubscribe again, which closes the channel.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	subscriber := make(chan Event, subscriberBuffer)
+
+	b.lock.Lock()
+	b.subscribers[subscriber] = struct{}{}
+	b.lock.Unlock()
+
+	var once sync.Once
+	return subscriber, func() {
+		once.Do(func() {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			delete(b.subscribers, subscriber)
+			close(subscriber)
+		})
+	}
+}
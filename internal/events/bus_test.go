@@ -0,0 +1,60 @@
+package events
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bus_fans_out_to_all_subscribers(t *testing.T) {
+	bus := NewBus()
+	first, unsubscribeFirst := bus.Subscribe()
+	defer unsubscribeFirst()
+	second, unsubscribeSecond := bus.Subscribe()
+	defer unsubscribeSecond()
+
+	bus.Publish(Event{Type: IdleStarted, Namespace: "foo-jenkins"})
+
+	for _, subscriber := range []<-chan Event{first, second} {
+		event := <-subscriber
+		assert.Equal(t, IdleStarted, event.Type)
+		assert.Equal(t, "foo-jenkins", event.Namespace)
+		assert.False(t, event.Time.IsZero(), "Publish should set the time")
+	}
+}
+
+func Test_unsubscribe_closes_the_channel(t *testing.T) {
+	bus := NewBus()
+	subscriber, unsubscribe := bus.Subscribe()
+	unsubscribe()
+	unsubscribe()
+
+	bus.Publish(Event{Type: IdleStarted})
+	_, ok := <-subscriber
+	assert.False(t, ok, "channel should be closed")
+}
+
+func Test_publish_does_not_block_on_slow_subscribers(t *testing.T) {
+	bus := NewBus()
+	subscriber, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer+10; i++ {
+		bus.Publish(Event{Type: Evaluation})
+	}
+	require.Len(t, subscriber, subscriberBuffer)
+}
+
+func Test_publish_on_nil_bus(t *testing.T) {
+	var bus *Bus
+	bus.Publish(Event{Type: Evaluation})
+}
+
+func Test_event_data(t *testing.T) {
+	assert.Equal(t, Result{Success: true}, NewResult(nil))
+	assert.Equal(t, Result{Error: "boom"}, NewResult(errors.New("boom")))
+	assert.Equal(t, StateChange{From: "idled", To: "starting"}, NewStateChange(model.PodIdled, model.PodStarting))
+}
@@ -0,0 +1,84 @@
+package idler
+
+import (
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// IdleAction is the strategy used to idle resp. un-idle a Jenkins service.
+type IdleAction interface {
+	Idle(apiURL string, bearerToken string, namespace string, service string) error
+	UnIdle(apiURL string, bearerToken string, namespace string, service string) error
+}
+
+// scaleAction idles a service by scaling its DeploymentConfig to 0 replicas.
+type scaleAction struct {
+	client client.OpenShiftClient
+}
+
+// NewScaleAction creates an IdleAction which scales the service down to 0 resp. up to 1 replica.
+func NewScaleAction(c client.OpenShiftClient) IdleAction {
+	return &scaleAction{client: c}
+}
+
+func (a *scaleAction) Idle(apiURL string, bearerToken string, namespace string, service string) error {
+	return a.client.Idle(apiURL, bearerToken, namespace, service)
+}
+
+func (a *scaleAction) UnIdle(apiURL string, bearerToken string, namespace string, service string) error {
+	return a.client.UnIdle(apiURL, bearerToken, namespace, service)
+}
+
+// softAction idles a service by shrinking the resources of its DeploymentConfig, so that
+// un-idling does not require a full cold start.
+type softAction struct {
+	client    client.OpenShiftClient
+	resources v1.ResourceRequirements
+}
+
+// NewSoftAction creates an IdleAction which reduces the resources of the service to the given
+// resources when idling and restores the original resources when un-idling.
+func NewSoftAction(c client.OpenShiftClient, resources v1.ResourceRequirements) IdleAction {
+	return &softAction{client: c, resources: resources}
+}
+
+func (a *softAction) Idle(apiURL string, bearerToken string, namespace string, service string) error {
+	return a.client.SoftIdle(apiURL, bearerToken, namespace, service, a.resources)
+}
+
+func (a *softAction) UnIdle(apiURL string, bearerToken string, namespace string, service string) error {
+	state, err := a.client.State(apiURL, bearerToken, namespace, service)
+	if err != nil {
+		return err
+	}
+
+	// the service might have been scaled down nevertheless, e.g. via the API
+	if state == model.PodIdled {
+		if err := a.client.UnIdle(apiURL, bearerToken, namespace, service); err != nil {
+			return err
+		}
+	}
+	return a.client.SoftUnIdle(apiURL, bearerToken, namespace, service)
+}
+
+// newSoftIdleResources returns the resource requirements of a soft-idled service,
+// using the given CPU and memory quantities for both, requests and limits.
+func newSoftIdleResources(cpu string, memory string) (v1.ResourceRequirements, error) {
+	list := v1.ResourceList{}
+
+	q, err := resource.ParseQuantity(cpu)
+	if err != nil {
+		return v1.ResourceRequirements{}, err
+	}
+	list[v1.ResourceCPU] = q
+
+	q, err = resource.ParseQuantity(memory)
+	if err != nil {
+		return v1.ResourceRequirements{}, err
+	}
+	list[v1.ResourceMemory] = q
+
+	return v1.ResourceRequirements{Requests: list, Limits: list}, nil
+}
@@ -0,0 +1,132 @@
+package idler
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+)
+
+// CheckReport is the outcome of an idle check forced via Check.
+type CheckReport struct {
+	// DryRun is true if the action has only been determined, but not taken.
+	DryRun     bool                  `json:"dry_run"`
+	Evaluation *condition.Evaluation `json:"evaluation,omitempty"`
+	// Decision is the action taken resp. the action which would be taken, nil if the check was skipped,
+	// e.g. because idling is disabled for the user.
+	Decision       *Decision  `json:"decision,omitempty"`
+	Error          string     `json:"error,omitempty"`
+	IdleAttempts   int        `json:"idle_attempts"`
+	UnIdleAttempts int        `json:"unidle_attempts"`
+	MaxRetries     int        `json:"max_retries"`
+	User           model.User `json:"user"`
+}
+
+// checkRequest asks the goroutine of a UserIdler to run an idle check right away.
+type checkRequest struct {
+	dryRun bool
+	report chan CheckReport
+}
+
+// Check runs an idle check right away instead of waiting for the next time based check and reports the
+// user state, the condition verdicts, the retry counters and the action taken. With dryRun the action is
+// only determined, neither taken nor recorded in the history. The conditions are evaluated as in any other
+// check though: a prewarm prediction is recorded and counted in the prediction metrics, a webhook verdict is
+// cached and reused by the next check, and the observed Jenkins state is tracked in the summary. The check
+// runs in the goroutine of this UserIdler, the given context bounds how long to wait for it.
+func (idler *UserIdler) Check(ctx context.Context, dryRun bool) (CheckReport, error) {
+	request := checkRequest{dryRun: dryRun, report: make(chan CheckReport, 1)}
+	select {
+	case idler.checkChan <- request:
+	case <-ctx.Done():
+		return CheckReport{}, ctx.Err()
+	}
+
+	select {
+	case report := <-request.report:
+		return report, nil
+	case <-ctx.Done():
+		return CheckReport{}, ctx.Err()
+	}
+}
+
+// forceCheck runs the idle check requested via Check.
+func (idler *UserIdler) forceCheck(dryRun bool) CheckReport {
+	idler.logger.WithField("state", idler.user.StateDump()).Infof("Forced idle check (dry-run: %t).", dryRun)
+
+	report := CheckReport{DryRun: dryRun || idler.dryRun}
+	var err error
+	if dryRun {
+		err = idler.previewCheck(&report)
+	} else {
+		err = idler.recordedCheck(&report)
+	}
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	report.IdleAttempts = idler.idleAttempts
+	report.UnIdleAttempts = idler.unIdleAttempts
+	report.MaxRetries = idler.policy.MaxRetries
+	report.User = idler.user
+	return report
+}
+
+// recordedCheck runs checkIdle and reports the evaluation and the decision it took.
+func (idler *UserIdler) recordedCheck(report *CheckReport) error {
+	started := time.Now().UTC()
+	err := idler.checkIdle()
+
+	if decision, ok := idler.history.Last(); ok && !decision.Timestamp.Before(started) {
+		report.Decision = &decision
+	}
+
+	idler.evaluationLock.RLock()
+	defer idler.evaluationLock.RUnlock()
+	if idler.evaluation != nil && !idler.evaluation.EvaluatedAt.Before(started) {
+		evaluation := *idler.evaluation
+		report.Evaluation = &evaluation
+	}
+	return err
+}
+
+// previewCheck determines the action checkIdle would take without taking it. Unlike checkIdle it neither
+// refreshes the policy, handles hung builds, cancels a pending idle nor records the decision. The side effects
+// of evaluating the conditions and getting the Jenkins state are not undone, see Check.
+func (idler *UserIdler) previewCheck(report *CheckReport) error {
+	now := time.Now().UTC()
+
+	enabled, err := idler.isIdlerEnabled()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		report.Evaluation = &condition.Evaluation{EvaluatedAt: now, Reason: "idler disabled for user"}
+		return nil
+	}
+	if idler.policy.Disabled {
+		report.Evaluation = &condition.Evaluation{EvaluatedAt: now, Reason: "idler disabled via annotation"}
+		return nil
+	}
+
+	evaluation, errors := idler.Conditions.Eval(idler.user)
+	report.Evaluation = &evaluation
+	if !errors.Empty() {
+		return errors.ToError()
+	}
+
+	decision := Decision{Timestamp: now, Action: evaluation.Action.String(), DryRun: true}
+	report.Decision = &decision
+	if evaluation.Action == condition.NoAction {
+		return nil
+	}
+
+	state, err := idler.getJenkinsState()
+	if err != nil {
+		decision.Error = err.Error()
+		return err
+	}
+	decision.State = state.String()
+	return nil
+}
@@ -0,0 +1,63 @@
+package idler
+
+import (
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+)
+
+// Explanation explains the most recent idling decision of a UserIdler.
+type Explanation struct {
+	Evaluation condition.Evaluation `json:"evaluation"`
+	// NextIdle is the earliest point in time Jenkins will be idled without new activity,
+	// nil if it cannot be forecast.
+	NextIdle    *time.Time   `json:"next_idle,omitempty"`
+	PendingIdle *PendingIdle `json:"pending_idle,omitempty"`
+	// HungBuilds are the active builds which wait on an input step resp. stalled in one stage.
+	HungBuilds []HungBuildOutcome `json:"hung_builds,omitempty"`
+}
+
+// Explain returns the explanation of the most recent evaluation of the conditions of this UserIdler.
+// The second return value is false if the conditions have not been evaluated yet.
+func (idler *UserIdler) Explain() (Explanation, bool) {
+	idler.evaluationLock.RLock()
+	defer idler.evaluationLock.RUnlock()
+
+	if idler.evaluation == nil {
+		return Explanation{}, false
+	}
+
+	explanation := Explanation{Evaluation: *idler.evaluation, HungBuilds: idler.hungBuildOutcomes()}
+	if pending, ok := idler.grace.get(); ok {
+		explanation.PendingIdle = &pending
+		explanation.NextIdle = &pending.IdleAt
+		return explanation, true
+	}
+
+	if idler.dryRun {
+		return explanation, true
+	}
+
+	next, ok := idler.evaluation.NextIdle()
+	if !ok {
+		return explanation, true
+	}
+	if next.Before(idler.evaluation.EvaluatedAt) {
+		next = idler.evaluation.EvaluatedAt
+	}
+	if idler.notifier != nil {
+		// the idle is announced first and executed after the grace period
+		next = next.Add(idler.grace.duration)
+	}
+	explanation.NextIdle = &next
+	return explanation, true
+}
+
+func (idler *UserIdler) setEvaluation(evaluation condition.Evaluation) {
+	idler.evaluationLock.Lock()
+	defer idler.evaluationLock.Unlock()
+
+	idler.evaluation = &evaluation
+	idler.publish(events.Evaluation, evaluation)
+}
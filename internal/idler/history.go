@@ -0,0 +1,80 @@
+package idler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+)
+
+const defaultHistorySize = 20
+
+// Decision records the outcome of a single idle check of a UserIdler.
+type Decision struct {
+	Timestamp time.Time `json:"timestamp"`
+	Action    string    `json:"action"`
+	DryRun    bool      `json:"dry_run"`
+	State     string    `json:"state,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// WouldIdle returns true if this is a dry-run decision which would have idled a running or starting Jenkins.
+func (d Decision) WouldIdle() bool {
+	return d.DryRun && d.Action == condition.Idle.String() &&
+		(d.State == model.PodState(model.PodRunning).String() || d.State == model.PodState(model.PodStarting).String())
+}
+
+// WouldUnIdle returns true if this is a dry-run decision which would have un-idled an idled Jenkins.
+func (d Decision) WouldUnIdle() bool {
+	return d.DryRun && d.Action == condition.UnIdle.String() &&
+		d.State == model.PodState(model.PodIdled).String()
+}
+
+// History is a bounded and concurrency safe list of the most recent decisions of a UserIdler.
+type History struct {
+	sync.RWMutex
+	size      int
+	decisions []Decision
+}
+
+// NewHistory creates a new History keeping at most size decisions.
+func NewHistory(size int) *History {
+	return &History{
+		size:      size,
+		decisions: make([]Decision, 0, size),
+	}
+}
+
+// Add appends the given decision, discarding the oldest one if the history is full.
+func (h *History) Add(d Decision) {
+	h.Lock()
+	defer h.Unlock()
+
+	if len(h.decisions) >= h.size {
+		h.decisions = h.decisions[1:]
+	}
+	h.decisions = append(h.decisions, d)
+}
+
+// Decisions returns a copy of the recorded decisions, oldest first.
+func (h *History) Decisions() []Decision {
+	h.RLock()
+	defer h.RUnlock()
+
+	result := make([]Decision, len(h.decisions))
+	copy(result, h.decisions)
+	return result
+}
+
+// Last returns the most recent decision. The second return value is false
+// if no decision has been recorded yet.
+func (h *History) Last() (Decision, bool) {
+	h.RLock()
+	defer h.RUnlock()
+
+	if len(h.decisions) == 0 {
+		return Decision{}, false
+	}
+	return h.decisions[len(h.decisions)-1], true
+}
@@ -0,0 +1,48 @@
+package idler
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_history_is_bounded(t *testing.T) {
+	h := NewHistory(3)
+
+	_, ok := h.Last()
+	assert.False(t, ok, "Empty history should not have a last decision")
+
+	for i := 0; i < 5; i++ {
+		h.Add(Decision{Action: strconv.Itoa(i)})
+	}
+
+	decisions := h.Decisions()
+	assert.Len(t, decisions, 3, "History should keep at most 3 decisions")
+	assert.Equal(t, "2", decisions[0].Action, "Oldest decisions should be discarded")
+
+	last, ok := h.Last()
+	assert.True(t, ok, "History should have a last decision")
+	assert.Equal(t, "4", last.Action, "Unexpected last decision")
+}
+
+func Test_would_idle_and_unidle(t *testing.T) {
+	tests := []struct {
+		decision    Decision
+		wouldIdle   bool
+		wouldUnIdle bool
+	}{
+		{Decision{Action: "idle", DryRun: true, State: "running"}, true, false},
+		{Decision{Action: "idle", DryRun: true, State: "starting"}, true, false},
+		{Decision{Action: "idle", DryRun: true, State: "idled"}, false, false},
+		{Decision{Action: "idle", DryRun: false, State: "running"}, false, false},
+		{Decision{Action: "unidle", DryRun: true, State: "idled"}, false, true},
+		{Decision{Action: "unidle", DryRun: true, State: "running"}, false, false},
+		{Decision{Action: "no action", DryRun: true}, false, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.wouldIdle, test.decision.WouldIdle(), "Unexpected WouldIdle for %+v", test.decision)
+		assert.Equal(t, test.wouldUnIdle, test.decision.WouldUnIdle(), "Unexpected WouldUnIdle for %+v", test.decision)
+	}
+}
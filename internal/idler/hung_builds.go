@@ -0,0 +1,132 @@
+package idler
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	logrus "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HungBuildOutcome records how a build which waits on an input step resp. stalled in one stage was handled.
+type HungBuildOutcome struct {
+	condition.HungBuild
+	DetectedAt time.Time `json:"detected_at"`
+	// Cancelled is true if the build has been cancelled via the OpenShift API.
+	Cancelled bool `json:"cancelled,omitempty"`
+	// DryRun is true if the build would have been cancelled, but the UserIdler is in dry-run mode.
+	DryRun bool   `json:"dry_run,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// handleHungBuilds detects the active builds of the user which are hung and cancels them if the policy
+// says so. Cancellations which failed are retried on the next check. The outcomes are reported by Explain.
+func (idler *UserIdler) handleHungBuilds() {
+	now := time.Now().UTC()
+	outcomes := make(map[string]HungBuildOutcome)
+	for name, build := range idler.user.ActiveBuilds {
+		hung, ok := idler.hungBuildPolicy.Classify(build, now)
+		if !ok {
+			continue
+		}
+
+		outcome, known := idler.hungBuilds[name]
+		if known && outcome.State == hung.State && outcome.Action == hung.Action &&
+			(hung.Action != condition.CancelBuild || outcome.Error == "") {
+			outcomes[name] = outcome
+			continue
+		}
+
+		outcome = HungBuildOutcome{HungBuild: hung, DetectedAt: now}
+		log := idler.logger.WithFields(logrus.Fields{
+			"build":  name,
+			"state":  hung.State,
+			"action": hung.Action,
+		})
+		log.Infof("Build is %s in stage %q since %v", hung.State, hung.Stage, hung.Since)
+
+		if hung.Action == condition.CancelBuild {
+			if idler.dryRun {
+				log.Info("dry-run: not cancelling build")
+				outcome.DryRun = true
+			} else if err := idler.cancelBuild(build.Metadata.Namespace, hung); err != nil {
+				log.Errorf("Cancelling build failed: %s", err)
+				outcome.Error = err.Error()
+			} else {
+				outcome.Cancelled = true
+			}
+		}
+		outcomes[name] = outcome
+	}
+
+	idler.evaluationLock.Lock()
+	defer idler.evaluationLock.Unlock()
+	idler.hungBuilds = outcomes
+}
+
+// cancelBuild cancels the given build and creates an event for it, so that the user learns why it was cancelled.
+func (idler *UserIdler) cancelBuild(ns string, hung condition.HungBuild) error {
+	if ns == "" {
+		ns = idler.user.Name
+	}
+	if err := idler.openShiftClient.CancelBuild(idler.openShiftAPI, idler.openShiftBearerToken, ns, hung.Name); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	event := v1.Event{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: hung.Name + ".", Namespace: ns},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "Build",
+			Namespace: ns,
+			Name:      hung.Name,
+		},
+		Reason:         "HungBuildCancelled",
+		Message:        fmt.Sprintf("Build cancelled by the Jenkins Idler, it was %s in stage %q since %s", hung.State, hung.Stage, hung.Since.Format(time.RFC3339)),
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: "jenkins-idler"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if err := idler.openShiftClient.CreateEvent(idler.openShiftAPI, idler.openShiftBearerToken, ns, event); err != nil {
+		idler.logger.Errorf("Failed to create event for cancelled build: %s", err)
+	}
+	return nil
+}
+
+// hungBuildOutcomes returns the outcomes of the hung builds ordered by build name. The caller needs to hold
+// the evaluationLock.
+func (idler *UserIdler) hungBuildOutcomes() []HungBuildOutcome {
+	var outcomes []HungBuildOutcome
+	for _, outcome := range idler.hungBuilds {
+		outcomes = append(outcomes, outcome)
+	}
+	sort.Slice(outcomes, func(i, j int) bool { return outcomes[i].Name < outcomes[j].Name })
+	return outcomes
+}
+
+// createHungBuildPolicy returns the configured hung build policy. Invalid actions are logged and keep the build.
+func createHungBuildPolicy(config configuration.Configuration, log *logrus.Entry) condition.HungBuildPolicy {
+	policy := condition.HungBuildPolicy{
+		InputTimeout: time.Duration(config.GetInputWaitTimeout()) * time.Minute,
+		StallTimeout: time.Duration(config.GetStalledStageTimeout()) * time.Minute,
+	}
+
+	var err error
+	if a := config.GetInputWaitAction(); a != "" {
+		if policy.InputAction, err = condition.ParseHungBuildAction(a); err != nil {
+			log.Errorf("Keeping builds waiting on input: %s", err)
+		}
+	}
+	if a := config.GetStalledBuildAction(); a != "" {
+		if policy.StallAction, err = condition.ParseHungBuildAction(a); err != nil {
+			log.Errorf("Keeping stalled builds: %s", err)
+		}
+	}
+	return policy
+}
@@ -0,0 +1,72 @@
+package idler
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
+)
+
+// pinCheckTimeout bounds how long the idle check triggered by Pin waits for the goroutine of the UserIdler.
+const pinCheckTimeout = time.Minute
+
+// Lease keeps Jenkins running until it expires, regardless of the idle conditions.
+type Lease struct {
+	Reason  string    `json:"reason"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires"`
+}
+
+// namespaceLeases provides the keep-alive lease of the namespace of a UserIdler out of the leases shared by all
+// replicas. The namespace is fixed on creation, so that it can be read by the REST API without locking.
+type namespaceLeases struct {
+	store     *lease.Leases
+	namespace string
+}
+
+// get returns the lease if it is active at the given point in time.
+func (l namespaceLeases) get(now time.Time) (Lease, bool) {
+	entry, ok := l.store.Get(l.namespace, now)
+	return Lease{Reason: entry.Reason, Created: entry.Created, Expires: entry.Expires}, ok
+}
+
+// ActiveLease implements condition.Leases.
+func (l namespaceLeases) ActiveLease(now time.Time) (time.Time, string, bool) {
+	active, ok := l.get(now)
+	return active.Expires, active.Reason, ok
+}
+
+// Pin keeps Jenkins running for the given duration, replacing any active lease. An idle check is triggered
+// right away, so that idled Jenkins is un-idled without waiting for the next time based check.
+func (idler *UserIdler) Pin(d time.Duration, reason string) (Lease, error) {
+	now := time.Now().UTC()
+	pinned := Lease{Reason: reason, Created: now, Expires: now.Add(d)}
+	entry := lease.Entry{Namespace: idler.leases.namespace, Reason: reason, Created: pinned.Created, Expires: pinned.Expires}
+	if err := idler.leases.store.Pin(entry); err != nil {
+		return Lease{}, err
+	}
+	idler.logger.Infof("Kept alive until %s: %s", pinned.Expires, reason)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), pinCheckTimeout)
+		defer cancel()
+		if _, err := idler.Check(ctx, false); err != nil {
+			idler.logger.Warnf("Idle check after keep-alive lease failed: %s", err)
+		}
+	}()
+	return pinned, nil
+}
+
+// Lease returns the active keep-alive lease, false if there is none.
+func (idler *UserIdler) Lease() (Lease, bool) {
+	return idler.leases.get(time.Now())
+}
+
+// Unpin revokes the keep-alive lease. It returns true if a lease was active.
+func (idler *UserIdler) Unpin() (bool, error) {
+	revoked, err := idler.leases.store.Unpin(idler.leases.namespace)
+	if revoked {
+		idler.logger.Info("Keep-alive lease revoked.")
+	}
+	return revoked, err
+}
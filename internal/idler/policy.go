@@ -0,0 +1,138 @@
+package idler
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/toggles"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+	"github.com/sirupsen/logrus"
+)
+
+// Policy defines how the Jenkins of a single namespace is idled. It is based on the global
+// configuration, which can be overridden by the tier of the user and per namespace via annotations.
+type Policy struct {
+	// Tier is the name of the tier of the user, empty if the global configuration applies.
+	Tier string
+	// IdleAfter is the number of minutes before Jenkins is idled.
+	IdleAfter int
+	// IdleLongBuild is the number of hours a long running build keeps Jenkins from being idled.
+	IdleLongBuild int
+	// MaxRetries is the maximum number of retries to idle resp. un-idle Jenkins.
+	MaxRetries int
+	// SoftIdle is true if Jenkins is idled by shrinking its resources instead of scaling it down.
+	SoftIdle bool
+	// Disabled is true if Jenkins should neither be idled nor un-idled.
+	Disabled bool
+}
+
+// newPolicy creates the Policy of the given user defined by the global configuration and the
+// given tier, which may be nil. Soft-idle users configured explicitly are always soft-idled.
+func newPolicy(config configuration.Configuration, userName string, tier *configuration.Tier) Policy {
+	p := Policy{
+		IdleAfter:     config.GetIdleAfter(),
+		IdleLongBuild: config.GetIdleLongBuild(),
+		MaxRetries:    config.GetMaxRetries(),
+		SoftIdle:      config.GetSoftIdle(),
+	}
+
+	if tier != nil {
+		p.Tier = tier.Name
+		if tier.IdleAfter > 0 {
+			p.IdleAfter = tier.IdleAfter
+		}
+		if tier.IdleLongBuild > 0 {
+			p.IdleLongBuild = tier.IdleLongBuild
+		}
+		if tier.MaxRetries > 0 {
+			p.MaxRetries = tier.MaxRetries
+		}
+		if tier.IdleAction != "" {
+			p.SoftIdle = tier.IdleAction == configuration.IdleActionSoft
+		}
+	}
+
+	if util.Contains(config.GetSoftIdleUsers(), userName) {
+		p.SoftIdle = true
+	}
+	return p
+}
+
+// resolveTier returns the tier of the given user. The tier assigned by the tenant service takes
+// precedence over the one enabled via feature toggle, which takes precedence over the default tier.
+// It returns nil if the user is not assigned to any configured tier.
+func resolveTier(config configuration.Configuration, features toggles.Features, user model.User, log *logrus.Entry) *configuration.Tier {
+	tiers := config.GetTiers()
+	if len(tiers) == 0 {
+		return nil
+	}
+
+	name := user.Tier
+	if name == "" && features != nil {
+		var names []string
+		for _, t := range tiers {
+			names = append(names, t.Name)
+		}
+		toggled, err := features.Tier(user.ID, names)
+		if err != nil {
+			log.Warnf("Failed to get tier from feature toggles: %s", err)
+		}
+		name = toggled
+	}
+	if name == "" {
+		name = config.GetDefaultTier()
+	}
+	if name == "" {
+		return nil
+	}
+
+	for i := range tiers {
+		if tiers[i].Name == name {
+			return &tiers[i]
+		}
+	}
+	log.Warnf("Unknown tier %q, using global configuration", name)
+	return nil
+}
+
+// apply returns the policy overridden by the given annotations. Invalid annotation values are
+// ignored, an error is returned for each of them.
+func (p Policy) apply(annotations model.PolicyAnnotations) (Policy, []error) {
+	var errors []error
+
+	if annotations.IdleAfter != "" {
+		if v, err := parsePositiveInt(annotations.IdleAfter); err != nil {
+			errors = append(errors, fmt.Errorf("invalid value %q for idler.fabric8.io/idle-after: %s", annotations.IdleAfter, err))
+		} else {
+			p.IdleAfter = v
+		}
+	}
+
+	if annotations.LongBuildTimeout != "" {
+		if v, err := parsePositiveInt(annotations.LongBuildTimeout); err != nil {
+			errors = append(errors, fmt.Errorf("invalid value %q for idler.fabric8.io/long-build-timeout: %s", annotations.LongBuildTimeout, err))
+		} else {
+			p.IdleLongBuild = v
+		}
+	}
+
+	if annotations.Disabled != "" {
+		if v, err := strconv.ParseBool(annotations.Disabled); err != nil {
+			errors = append(errors, fmt.Errorf("invalid value %q for idler.fabric8.io/disabled: needs to be true or false", annotations.Disabled))
+		} else {
+			p.Disabled = v
+		}
+	}
+
+	return p, errors
+}
+
+func parsePositiveInt(s string) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("needs to be a positive number")
+	}
+	return v, nil
+}
@@ -0,0 +1,78 @@
+package idler
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_policy_is_overridden_by_annotations(t *testing.T) {
+	policy := newPolicy(&mock.Config{IdleAfter: 45, IdleLongBuild: 3}, "foo", nil)
+	assert.Equal(t, Policy{IdleAfter: 45, IdleLongBuild: 3}, policy)
+
+	overridden, errors := policy.apply(model.PolicyAnnotations{IdleAfter: "120", LongBuildTimeout: "6", Disabled: "true"})
+	assert.Empty(t, errors)
+	assert.Equal(t, Policy{IdleAfter: 120, IdleLongBuild: 6, Disabled: true}, overridden)
+}
+
+func Test_invalid_annotations_are_ignored(t *testing.T) {
+	policy := newPolicy(&mock.Config{IdleAfter: 45, IdleLongBuild: 3}, "foo", nil)
+
+	overridden, errors := policy.apply(model.PolicyAnnotations{IdleAfter: "-5", LongBuildTimeout: "forever", Disabled: "maybe"})
+	assert.Len(t, errors, 3, "Each invalid value should be reported")
+	assert.Equal(t, policy, overridden, "Invalid values should not change the policy")
+}
+
+func Test_policy_is_overridden_by_tier(t *testing.T) {
+	config := &mock.Config{IdleAfter: 45, IdleLongBuild: 3, MaxRetries: 5}
+	tier := &configuration.Tier{Name: "enterprise", IdleAfter: 240, MaxRetries: 10, IdleAction: configuration.IdleActionSoft}
+
+	policy := newPolicy(config, "foo", tier)
+	assert.Equal(t, Policy{Tier: "enterprise", IdleAfter: 240, IdleLongBuild: 3, MaxRetries: 10, SoftIdle: true}, policy)
+
+	overridden, errors := policy.apply(model.PolicyAnnotations{IdleAfter: "60"})
+	assert.Empty(t, errors)
+	assert.Equal(t, 60, overridden.IdleAfter, "Annotations should take precedence over the tier")
+}
+
+func Test_soft_idle_users_are_soft_idled_regardless_of_tier(t *testing.T) {
+	config := &mock.Config{SoftIdleUsers: []string{"foo"}}
+	tier := &configuration.Tier{Name: "free", IdleAction: configuration.IdleActionScale}
+
+	assert.True(t, newPolicy(config, "foo", tier).SoftIdle)
+	assert.False(t, newPolicy(config, "bar", tier).SoftIdle)
+}
+
+func Test_resolve_tier(t *testing.T) {
+	config := &mock.Config{
+		Tiers:       []configuration.Tier{{Name: "free"}, {Name: "team"}, {Name: "enterprise"}},
+		DefaultTier: "free",
+	}
+	features := mock.NewMockFeatureToggleWithTiers(nil, map[string]string{"100": "team", "200": "team"})
+	log := logrus.WithField("component", "test")
+
+	var tests = []struct {
+		user model.User
+		want string
+	}{
+		{model.User{ID: "100", Tier: "enterprise"}, "enterprise"},
+		{model.User{ID: "200"}, "team"},
+		{model.User{ID: "300"}, "free"},
+		{model.User{ID: "400", Tier: "unknown"}, ""},
+	}
+
+	for _, test := range tests {
+		tier := resolveTier(config, features, test.user, log)
+		if test.want == "" {
+			assert.Nil(t, tier, "Unknown tiers should not be resolved")
+			continue
+		}
+		assert.Equal(t, test.want, tier.Name, "Unexpected tier for user %s", test.user.ID)
+	}
+
+	assert.Nil(t, resolveTier(&mock.Config{}, features, model.User{ID: "100"}, log), "No tier without configured tiers")
+}
@@ -0,0 +1,134 @@
+package idler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// PreIdleNotification is sent to the pre-idle webhook when a user becomes eligible for idling.
+type PreIdleNotification struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	IdleAt    time.Time `json:"idle_at"`
+}
+
+// Notifier notifies about an upcoming idle.
+type Notifier interface {
+	Notify(n PreIdleNotification) error
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a Notifier which POSTs the notification as JSON to the given URL.
+func NewWebhookNotifier(url string) Notifier {
+	return &webhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+// Notify posts the notification to the webhook. An error is returned if the webhook
+// cannot be reached or does not respond with a 2xx status code.
+func (n *webhookNotifier) Notify(notification PreIdleNotification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("pre-idle webhook %s responded with status %d", n.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// PendingIdle describes an idle which has been announced, but not executed yet.
+type PendingIdle struct {
+	Since  time.Time `json:"since"`
+	IdleAt time.Time `json:"idle_at"`
+}
+
+// gracePeriod keeps track of the pending idle of a UserIdler. It is accessed by the
+// UserIdler goroutine as well as by API requests, hence it is guarded by a mutex.
+type gracePeriod struct {
+	sync.Mutex
+	duration       time.Duration
+	pending        *PendingIdle
+	keptAliveUntil time.Time
+}
+
+// start starts a new grace period at the given time unless one is pending already or the
+// user asked to keep Jenkins alive. It returns the started pending idle and true if a new
+// grace period was started.
+func (g *gracePeriod) start(now time.Time) (PendingIdle, bool) {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.pending != nil || now.Before(g.keptAliveUntil) {
+		return PendingIdle{}, false
+	}
+
+	g.pending = &PendingIdle{Since: now, IdleAt: now.Add(g.duration)}
+	return *g.pending, true
+}
+
+// expired returns true if a grace period is pending and ended before the given time.
+// The pending idle is cleared in this case.
+func (g *gracePeriod) expired(now time.Time) bool {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.pending == nil || now.Before(g.pending.IdleAt) {
+		return false
+	}
+	g.pending = nil
+	return true
+}
+
+// cancel cancels the pending idle. It returns true if an idle was pending.
+func (g *gracePeriod) cancel() bool {
+	g.Lock()
+	defer g.Unlock()
+
+	cancelled := g.pending != nil
+	g.pending = nil
+	return cancelled
+}
+
+// keepAlive cancels the pending idle and prevents a new one from being started for
+// the duration of a grace period. It returns true if an idle was pending.
+func (g *gracePeriod) keepAlive(now time.Time) bool {
+	g.Lock()
+	defer g.Unlock()
+
+	cancelled := g.pending != nil
+	g.pending = nil
+	g.keptAliveUntil = now.Add(g.duration)
+	return cancelled
+}
+
+// get returns the pending idle, the second return value is false if there is none.
+func (g *gracePeriod) get() (PendingIdle, bool) {
+	g.Lock()
+	defer g.Unlock()
+
+	if g.pending == nil {
+		return PendingIdle{}, false
+	}
+	return *g.pending, true
+}
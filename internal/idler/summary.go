@@ -0,0 +1,87 @@
+package idler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+)
+
+// Summary is a snapshot of the Jenkins instance tracked by a UserIdler.
+type Summary struct {
+	// Cluster is the API URL of the OpenShift cluster Jenkins runs on.
+	Cluster string
+	User    model.User
+	// State is the state of Jenkins as observed at StateObservedAt, unknown if it has not been observed yet.
+	State           model.PodState
+	StateObservedAt time.Time
+	// NextCheck is the time of the next time based idle check, zero if none is scheduled.
+	NextCheck time.Time
+}
+
+// tracker keeps the summary of a UserIdler, which is updated by the goroutine of the UserIdler and read
+// by the REST API.
+type tracker struct {
+	lock    sync.RWMutex
+	summary Summary
+}
+
+func (t *tracker) get() Summary {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+	return t.summary
+}
+
+func (t *tracker) update(f func(s *Summary)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	f(&t.summary)
+}
+
+// Summary returns a snapshot of the Jenkins instance tracked by this UserIdler.
+func (idler *UserIdler) Summary() Summary {
+	summary := idler.tracker.get()
+	if pending, ok := idler.grace.get(); ok && (summary.NextCheck.IsZero() || pending.IdleAt.Before(summary.NextCheck)) {
+		// the check at the end of the grace period comes first
+		summary.NextCheck = pending.IdleAt
+	}
+	return summary
+}
+
+// trackUser records the current user data in the summary.
+func (idler *UserIdler) trackUser() {
+	user := idler.user
+	idler.tracker.update(func(s *Summary) {
+		s.User = user
+	})
+}
+
+// trackState records the observed state of Jenkins in the summary.
+func (idler *UserIdler) trackState(state model.PodState) {
+	idler.tracker.update(func(s *Summary) {
+		s.State = state
+		s.StateObservedAt = time.Now().UTC()
+	})
+}
+
+// scheduleCheck returns a channel for the next time based idle check after d and records it in the summary.
+func (idler *UserIdler) scheduleCheck(d time.Duration) <-chan time.Time {
+	next := time.Now().Add(d).UTC()
+	idler.tracker.update(func(s *Summary) {
+		s.NextCheck = next
+	})
+	return time.After(d)
+}
+
+// clearScheduledCheck clears the time based idle check from the summary once it has fired.
+func (idler *UserIdler) clearScheduledCheck() {
+	idler.tracker.update(func(s *Summary) {
+		s.NextCheck = time.Time{}
+	})
+}
+
+// publish publishes an event concerning the Jenkins namespace of this UserIdler.
+func (idler *UserIdler) publish(eventType string, data interface{}) {
+	idler.bus.Publish(events.Event{Type: eventType, Namespace: idler.user.Name + jenkinsNamespaceSuffix, Data: data})
+}
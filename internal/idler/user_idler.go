@@ -3,20 +3,32 @@ package idler
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/prewarm"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/toggles"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
+	"github.com/fabric8-services/fabric8-jenkins-idler/metric"
 	logrus "github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var logger = logrus.WithField("component", "user-idler")
 
+// Recorder to capture the decisions of the user idlers
+var Recorder metric.Recorder = metric.PrometheusRecorder{}
+
 // JenkinsServices is an array of all the services getting idled or unidled
 // they go along the main build detection logic of jenkins and don't have
 // any specific scenarios.
@@ -26,6 +38,8 @@ const (
 	bufferSize             = 10
 	jenkinsNamespaceSuffix = "-jenkins"
 	jenkinsServiceName     = "jenkins"
+	projectCheckInterval   = 5 * time.Minute
+	metricsTimeout         = 10 * time.Second
 )
 
 // UserIdler is created for each monitored user/namespace.
@@ -36,26 +50,54 @@ type UserIdler struct {
 	openShiftAPI         string
 	openShiftBearerToken string
 	openShiftClient      client.OpenShiftClient
-	maxRetries           int
 	idleAttempts         int
 	unIdleAttempts       int
 	Conditions           *condition.Conditions
 	logger               *logrus.Entry
 	userChan             chan model.User
+	checkChan            chan checkRequest
 	user                 model.User
 	config               configuration.Configuration
 	features             toggles.Features
 	tenantService        tenant.Service
+	dryRun               bool
+	history              *History
+	predictor            *prewarm.Predictor
+	notifier             Notifier
+	grace                *gracePeriod
+	graceTimer           <-chan time.Time
+	softIdleResources    *v1.ResourceRequirements
+	schedules            []condition.Schedule
+	combination          condition.Combination
+	evaluationLock       sync.RWMutex
+	evaluation           *condition.Evaluation
+	tier                 *configuration.Tier
+	proxyClient          condition.ProxyClient
+	jenkinsClient        condition.JenkinsClient
+	jenkinsURL           string
+	metricsClient        condition.MetricsClient
+	hungBuildPolicy      condition.HungBuildPolicy
+	decisionCondition    condition.Condition
+	hungBuilds           map[string]HungBuildOutcome
+	policy               Policy
+	annotations          model.PolicyAnnotations
+	projectAnnotations   model.PolicyAnnotations
+	projectCheckedAt     time.Time
+	tracker              *tracker
+	leases               namespaceLeases
+	bus                  *events.Bus
 }
 
 // NewUserIdler creates an instance of UserIdler.
 // It returns a pointer to UserIdler,
 func NewUserIdler(
 	user model.User,
-	openShiftAPI, openShiftBearerToken string,
+	openShiftAPI, openShiftBearerToken, metricsURL string,
 	config configuration.Configuration,
 	features toggles.Features,
-	tenantService tenant.Service) *UserIdler {
+	tenantService tenant.Service,
+	bus *events.Bus,
+	leases *lease.Leases) *UserIdler {
 
 	logEntry := logger.WithFields(logrus.Fields{
 		"name": user.Name,
@@ -64,7 +106,44 @@ func NewUserIdler(
 	})
 	logEntry.Info("UserIdler created.")
 
-	conditions := createWatchConditions(config.GetProxyURL(), config.GetIdleAfter(), config.GetIdleLongBuild(), logEntry)
+	var predictor *prewarm.Predictor
+	if config.GetPrewarm() {
+		predictor = prewarm.NewPredictor(
+			time.Duration(config.GetPrewarmWindow())*time.Minute,
+			config.GetPrewarmThreshold(),
+			config.GetPrewarmMinWeeks())
+	}
+
+	var notifier Notifier
+	if url := config.GetPreIdleWebhook(); url != "" {
+		notifier = NewWebhookNotifier(url)
+	}
+
+	tier := resolveTier(config, features, user, logEntry)
+	policy := newPolicy(config, user.Name, tier)
+	if tier != nil {
+		logEntry.Infof("Using idle policy of tier %s", tier.Name)
+	}
+
+	var softIdleResources *v1.ResourceRequirements
+	resources, err := newSoftIdleResources(config.GetSoftIdleCPU(), config.GetSoftIdleMemory())
+	if err != nil {
+		if policy.SoftIdle {
+			logEntry.Errorf("Invalid soft-idle resources, scaling down instead: %s", err)
+		}
+	} else {
+		softIdleResources = &resources
+	}
+
+	var proxyClient condition.ProxyClient
+	if config.GetUserCondition() {
+		proxyClient = condition.NewProxyClient(config.GetProxyURL(),
+			time.Duration(config.GetProxyTimeout())*time.Second, config.GetProxyRetries())
+	}
+
+	if leases == nil {
+		leases = lease.NewLeases(store.NewMemoryStore())
+	}
 
 	userChan := make(chan model.User, bufferSize)
 
@@ -72,17 +151,46 @@ func NewUserIdler(
 		openShiftAPI:         openShiftAPI,
 		openShiftBearerToken: openShiftBearerToken,
 		openShiftClient:      client.NewOpenShift(),
-		maxRetries:           config.GetMaxRetries(),
 		idleAttempts:         0,
 		unIdleAttempts:       0,
-		Conditions:           conditions,
 		logger:               logEntry,
 		userChan:             userChan,
+		checkChan:            make(chan checkRequest),
 		user:                 user,
 		config:               config,
 		features:             features,
 		tenantService:        tenantService,
+		dryRun:               config.GetDryRun() || util.Contains(config.GetDryRunUsers(), user.Name),
+		history:              NewHistory(defaultHistorySize),
+		predictor:            predictor,
+		notifier:             notifier,
+		grace:                &gracePeriod{duration: time.Duration(config.GetPreIdleGracePeriod()) * time.Minute},
+		softIdleResources:    softIdleResources,
+		schedules:            createSchedules(config, user.Name, openShiftAPI, logEntry),
+		combination:          createCombination(config, logEntry),
+		hungBuildPolicy:      createHungBuildPolicy(config, logEntry),
+		tier:                 tier,
+		proxyClient:          proxyClient,
+		policy:               policy,
+		bus:                  bus,
+		leases:               namespaceLeases{store: leases, namespace: user.Name + jenkinsNamespaceSuffix},
+		tracker:              &tracker{summary: Summary{Cluster: openShiftAPI, User: user}},
+	}
+	if config.GetJenkinsCondition() {
+		userIdler.jenkinsClient = condition.NewJenkinsClient(userIdler.resolveJenkinsURL, openShiftBearerToken,
+			time.Duration(config.GetJenkinsTimeout())*time.Second)
+	}
+	if config.GetMetricsCondition() {
+		if metricsURL == "" {
+			logEntry.Warn("Metrics condition enabled, but the cluster has no metrics URL")
+		} else {
+			userIdler.metricsClient = condition.NewMetricsClient(metricsURL, openShiftBearerToken, metricsTimeout)
+		}
+	}
+	if url := config.GetDecisionWebhook(); url != "" {
+		userIdler.decisionCondition = createDecisionCondition(config, url, openShiftAPI, logEntry)
 	}
+	userIdler.Conditions = userIdler.createConditions()
 	return &userIdler
 }
 
@@ -96,9 +204,114 @@ func (idler *UserIdler) GetChannel() chan model.User {
 	return idler.userChan
 }
 
+// IsDryRun returns true if this UserIdler only records its decisions
+// without ever idling or un-idling Jenkins.
+func (idler *UserIdler) IsDryRun() bool {
+	return idler.dryRun
+}
+
+// History returns the most recent decisions taken by this UserIdler.
+func (idler *UserIdler) History() *History {
+	return idler.history
+}
+
+// PendingIdle returns the idle which has been announced via the pre-idle webhook, but not executed yet.
+// The second return value is false if no idle is pending.
+func (idler *UserIdler) PendingIdle() (PendingIdle, bool) {
+	return idler.grace.get()
+}
+
+// KeepAlive cancels a pending idle and postpones any new one for the duration of the grace period.
+// It returns true if an idle was pending.
+func (idler *UserIdler) KeepAlive() bool {
+	cancelled := idler.grace.keepAlive(time.Now())
+	if cancelled {
+		idler.logger.Info("Pending idle cancelled by keep alive request.")
+	}
+	return cancelled
+}
+
+// ObserveActivity records user activity at the given time, e.g. an un-idle request, so
+// that future activity can be predicted. It is a no-op if pre-warming is disabled.
+func (idler *UserIdler) ObserveActivity(t time.Time) {
+	if idler.predictor == nil {
+		return
+	}
+	idler.predictor.Observe(t)
+}
+
+// observeBuild records the start of new builds as activity.
+func (idler *UserIdler) observeBuild(previous model.User) {
+	for name, build := range idler.user.ActiveBuilds {
+		if _, ok := previous.ActiveBuilds[name]; ok {
+			continue
+		}
+
+		started := build.Status.StartTimestamp.Time
+		if started.IsZero() {
+			started = time.Now()
+		}
+		idler.ObserveActivity(started)
+	}
+}
+
+// seedPredictor observes the start of the builds still kept by OpenShift, so that the activity pattern does not
+// need to be learned from scratch whenever the idler is restarted. It is a no-op if pre-warming is disabled.
+func (idler *UserIdler) seedPredictor() {
+	if idler.predictor == nil {
+		return
+	}
+
+	builds, err := idler.openShiftClient.Builds(idler.openShiftAPI, idler.openShiftBearerToken, idler.user.Name)
+	if err != nil {
+		idler.logger.Warnf("Failed to load builds for pre-warming: %s", err)
+		return
+	}
+
+	var starts []time.Time
+	for _, build := range builds.Items {
+		if jenkinsNS := build.Metadata.Annotations.JenkinsNamespace; jenkinsNS != "" && jenkinsNS != idler.user.Name+jenkinsNamespaceSuffix {
+			continue
+		}
+		if started := build.Status.StartTimestamp.Time; !started.IsZero() {
+			starts = append(starts, started)
+		}
+	}
+
+	// the histogram expects observations in chronological order
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	for _, started := range starts {
+		idler.predictor.Observe(started)
+	}
+	idler.logger.Infof("Seeded pre-warming with %d builds", len(starts))
+}
+
+// expireBuilds drops the active builds which exceed twice the long build timeout. By then the build conditions
+// no longer consider them, most likely the end of the build has been missed by the watch.
+func (idler *UserIdler) expireBuilds() {
+	if idler.policy.IdleLongBuild <= 0 {
+		return
+	}
+
+	maxAge := 2 * time.Duration(idler.policy.IdleLongBuild) * time.Hour
+	if expired := idler.user.ExpireBuilds(time.Now().UTC().Add(-maxAge)); len(expired) > 0 {
+		idler.logger.Warnf("Dropped builds %v which have been active for more than %v", expired, maxAge)
+	}
+}
+
+// buildReason describes the last build of the user for logging.
+func (idler *UserIdler) buildReason() string {
+	build := idler.user.LastBuild()
+	if idler.user.HasActiveBuilds() {
+		return fmt.Sprintf("ActiveBuilds:%d BuildName:%s Last:%s", len(idler.user.ActiveBuilds), build.Metadata.Name, build.Status.StartTimestamp.Time)
+	}
+	return fmt.Sprintf("DoneBuild BuildName:%s Last:%s", build.Metadata.Name, build.Status.StartTimestamp.Time)
+}
+
 // checkIdle verifies the state of conditions and decides if we should idle/unidle
 // and performs the required action if needed.
 func (idler *UserIdler) checkIdle() error {
+	defer idler.trackUser()
 
 	enabled, err := idler.isIdlerEnabled()
 	if err != nil {
@@ -108,23 +321,45 @@ func (idler *UserIdler) checkIdle() error {
 
 	if !enabled {
 		idler.logger.Warnf("idler disabled for user %s - skipping", idler.user.Name)
+		idler.setEvaluation(condition.Evaluation{EvaluatedAt: time.Now().UTC(), Reason: "idler disabled for user"})
 		return nil
 	}
 
+	idler.refreshPolicy()
+	if idler.policy.Disabled {
+		idler.logger.Infof("idler disabled via annotation for user %s - skipping", idler.user.Name)
+		idler.setEvaluation(condition.Evaluation{EvaluatedAt: time.Now().UTC(), Reason: "idler disabled via annotation"})
+		return nil
+	}
+
+	idler.handleHungBuilds()
+	idler.expireBuilds()
+
 	idler.logger.Infof("Evaluating conditions for user %s", idler.user.Name)
 
-	action, errors := idler.Conditions.Eval(idler.user)
+	evaluation, errors := idler.Conditions.Eval(idler.user)
+	idler.setEvaluation(evaluation)
 	if !errors.Empty() {
 		idler.logger.Errorf("Failed to evaluate conditions for %s", idler.user.Name)
 		return errors.ToError()
 	}
 
+	action := evaluation.Action
 	log := idler.logger.WithField("action", action)
-	log.Infof("jenkins idle conditions eval result: %v", action)
+	log.Infof("jenkins idle conditions eval result: %v (%s)", action, evaluation.Reason)
+
+	if action != condition.Idle && idler.grace.cancel() {
+		log.Info("Pending idle cancelled due to new activity.")
+	}
+
+	if idler.dryRun {
+		return idler.simulate(action)
+	}
 
 	if action == condition.Idle {
 		if err := idler.doIdle(); err != nil {
 			log.Errorf("Idling jenkins failed:  %s", err)
+			idler.recordDecision(Decision{Action: action.String(), Error: err.Error()})
 			return err
 		}
 		// TODO: find a better way to update IdleStatus inside doIdle()
@@ -132,14 +367,51 @@ func (idler *UserIdler) checkIdle() error {
 	} else if action == condition.UnIdle {
 		if err := idler.doUnIdle(); err != nil {
 			log.Errorf("UnIdling jenkins failed:  %s", err)
+			idler.recordDecision(Decision{Action: action.String(), Error: err.Error()})
 			return err
 		}
 		// TODO: find a better way to update IdleStatus inside doUnIdle()
 		idler.user.IdleStatus = model.NewUnidleStatus(err)
 	}
+	idler.recordDecision(Decision{Action: action.String()})
 	return nil
 }
 
+// simulate records the decision for the given action without idling or
+// un-idling Jenkins. The current state of Jenkins is recorded along with
+// the decision so that it can be told whether the action would have had an effect.
+func (idler *UserIdler) simulate(action condition.Action) error {
+	decision := Decision{Action: action.String(), DryRun: true}
+	if action == condition.NoAction {
+		idler.recordDecision(decision)
+		return nil
+	}
+
+	state, err := idler.getJenkinsState()
+	if err != nil {
+		idler.logger.Errorf("failed to get status of jenkins: %s", err)
+		decision.Error = err.Error()
+		idler.recordDecision(decision)
+		return err
+	}
+
+	decision.State = state.String()
+	idler.logger.WithFields(logrus.Fields{
+		"action": action,
+		"state":  state,
+	}).Infof("dry-run: not executing %s, jenkins is %s", action, state)
+
+	idler.recordDecision(decision)
+	return nil
+}
+
+// recordDecision timestamps the given decision and adds it to the history as well as the metrics.
+func (idler *UserIdler) recordDecision(d Decision) {
+	d.Timestamp = time.Now().UTC()
+	idler.history.Add(d)
+	Recorder.RecordDecision(d.Action, d.DryRun)
+}
+
 // Run runs/starts the Idler
 // It checks if Jenkins is idle at every interval duration.
 func (idler *UserIdler) Run(
@@ -153,8 +425,10 @@ func (idler *UserIdler) Run(
 
 	wg.Add(1)
 	go func() {
+		idler.seedPredictor()
+
 		ticker := time.Tick(maxRetriesQuietInterval)
-		timer := time.After(interval)
+		timer := idler.scheduleCheck(interval)
 		defer wg.Done()
 		for {
 			select {
@@ -162,19 +436,23 @@ func (idler *UserIdler) Run(
 				idler.logger.Info("Shutting down user idler.")
 				cancel()
 				return
-			case idler.user = <-idler.userChan:
+			case user := <-idler.userChan:
+				previous := idler.user
+				idler.user = user
 				idler.logger.WithField("state", idler.user.StateDump()).Debug("Received user data.")
+				idler.observeBuild(previous)
 
 				err := idler.checkIdle()
 				if err != nil {
 					idler.logger.WithField("error", err.Error()).Warnf("Error during idle check: %s", err)
 				}
 				// Resetting the timer
-				timer = time.After(interval)
+				timer = idler.scheduleCheck(interval)
 			case <-timer:
 				// Timer handles the case where there are no OpenShift events received
 				// for the user for the checkIdle duration.
 				// This ensures checkIdle will be called regularly.
+				idler.clearScheduledCheck()
 
 				idler.logger.WithField("state", idler.user.StateDump()).Info("Time based idle check.")
 				err := idler.checkIdle()
@@ -182,6 +460,19 @@ func (idler *UserIdler) Run(
 					idler.logger.WithField("error", err.Error()).Warn("Error during idle check.")
 				}
 
+			case request := <-idler.checkChan:
+				// Idle check forced via the REST API
+				request.report <- idler.forceCheck(request.dryRun)
+
+			case <-idler.graceTimer:
+				// The grace period of a pending idle is over
+				idler.graceTimer = nil
+				idler.logger.Info("Grace period based idle check.")
+				err := idler.checkIdle()
+				if err != nil {
+					idler.logger.WithField("error", err.Error()).Warn("Error during idle check.")
+				}
+
 			case <-ticker:
 				// Using ticker for the resetting of counters to ensure it occurs
 				idler.logger.Debug("Resetting retry counters.")
@@ -193,8 +484,8 @@ func (idler *UserIdler) Run(
 
 func (idler *UserIdler) doIdle() error {
 
-	if idler.idleAttempts >= idler.maxRetries {
-		idler.logger.Warn("Skipping idle request since max retry count %d has reached.", idler.maxRetries)
+	if idler.idleAttempts >= idler.policy.MaxRetries {
+		idler.logger.Warnf("Skipping idle request since max retry count %d has reached.", idler.policy.MaxRetries)
 		return nil
 	}
 
@@ -209,22 +500,31 @@ func (idler *UserIdler) doIdle() error {
 		return nil
 	}
 
-	idler.logger.Infof("Idling services, attempts: %d/%d", idler.idleAttempts, idler.maxRetries)
+	if idler.notifier != nil && !idler.gracePeriodOver() {
+		return nil
+	}
+
+	idler.logger.Infof("Idling services, attempts: %d/%d", idler.idleAttempts, idler.policy.MaxRetries)
 
 	idler.incrementIdleAttempts()
+	idler.publish(events.IdleStarted, nil)
+	err = idler.idleServices()
+	idler.publish(events.IdleFinished, events.NewResult(err))
+	return err
+}
+
+// idleServices idles all Jenkins services of the namespace.
+func (idler *UserIdler) idleServices() error {
 	for _, service := range JenkinsServices {
 
 		log := idler.logger.WithField(
-			"attempt", fmt.Sprintf("(%d/%d)", idler.idleAttempts, idler.maxRetries))
+			"attempt", fmt.Sprintf("(%d/%d)", idler.idleAttempts, idler.policy.MaxRetries))
 		// Let's add some more reasons, we probably want to
-		reason := fmt.Sprintf("DoneBuild BuildName:%s Last:%s", idler.user.DoneBuild.Metadata.Name, idler.user.DoneBuild.Status.StartTimestamp.Time)
-		if idler.user.ActiveBuild.Metadata.Name != "" {
-			reason = fmt.Sprintf("ActiveBuild BuildName:%s Last:%s", idler.user.ActiveBuild.Metadata.Name, idler.user.ActiveBuild.Status.StartTimestamp.Time)
-		}
+		reason := idler.buildReason()
 
 		log.Infof("About to idle %s, reason %s", service, reason)
 
-		err := idler.openShiftClient.Idle(idler.openShiftAPI, idler.openShiftBearerToken, idler.user.Name+jenkinsNamespaceSuffix, service)
+		err := idler.idleAction().Idle(idler.openShiftAPI, idler.openShiftBearerToken, idler.user.Name+jenkinsNamespaceSuffix, service)
 		if err != nil {
 			log.Errorf("Idling of %s returned error:  %s", service, err)
 			return err
@@ -234,10 +534,39 @@ func (idler *UserIdler) doIdle() error {
 	return nil
 }
 
+// gracePeriodOver returns true if the grace period of a pending idle has ended. Otherwise it
+// announces the idle via the notifier and starts the grace period, unless an idle is pending
+// already or Jenkins is being kept alive.
+func (idler *UserIdler) gracePeriodOver() bool {
+	now := time.Now()
+	if idler.grace.expired(now) {
+		return true
+	}
+
+	pending, started := idler.grace.start(now)
+	if !started {
+		idler.logger.Debug("Idle pending or kept alive, not idling.")
+		return false
+	}
+	idler.graceTimer = time.After(pending.IdleAt.Sub(now))
+
+	idler.logger.Infof("Announcing idle at %s", pending.IdleAt)
+	err := idler.notifier.Notify(PreIdleNotification{
+		ID:        idler.user.ID,
+		Name:      idler.user.Name,
+		Namespace: idler.user.Name + jenkinsNamespaceSuffix,
+		IdleAt:    pending.IdleAt,
+	})
+	if err != nil {
+		idler.logger.Errorf("Failed to send pre-idle notification: %s", err)
+	}
+	return false
+}
+
 func (idler *UserIdler) doUnIdle() error {
 
-	idler.logger.Debugf("Current un-idle attempt count: %v, maximum retry count: %v", idler.unIdleAttempts, idler.maxRetries)
-	if idler.unIdleAttempts >= idler.maxRetries {
+	idler.logger.Debugf("Current un-idle attempt count: %v, maximum retry count: %v", idler.unIdleAttempts, idler.policy.MaxRetries)
+	if idler.unIdleAttempts >= idler.policy.MaxRetries {
 		idler.logger.Warn("Skipping un-idle request since max retry count has been reached.")
 		return nil
 	}
@@ -270,20 +599,11 @@ func (idler *UserIdler) doUnIdle() error {
 	}
 
 	idler.incrementUnIdleAttempts()
-	for _, service := range JenkinsServices {
-		// Let's add some more reasons, we probably want to
-		reasonString := fmt.Sprintf("DoneBuild BuildName:%s Last:%s", idler.user.DoneBuild.Metadata.Name, idler.user.DoneBuild.Status.StartTimestamp.Time)
-		if idler.user.ActiveBuild.Metadata.Name != "" {
-			reasonString = fmt.Sprintf("ActiveBuild BuildName:%s Last:%s", idler.user.ActiveBuild.Metadata.Name, idler.user.ActiveBuild.Status.StartTimestamp.Time)
-		}
-		idler.logger.WithField("attempt", fmt.Sprintf("(%d/%d)", idler.unIdleAttempts, idler.maxRetries)).Info("About to un-idle "+service+", Reason: ", reasonString)
-		err := idler.openShiftClient.UnIdle(idler.openShiftAPI, idler.openShiftBearerToken, ns, service)
-		if err != nil {
-			idler.logger.Warnf("Failed to un-idle service %v in namespace %v (un-idle attempt: %v)", service, ns, idler.unIdleAttempts)
-			idler.logger.Error(err)
-			return err
-		}
-		idler.logger.Infof("Successfully un-idled service %v in namespace %v (un-idle attempt: %v)", service, ns, idler.unIdleAttempts)
+	idler.publish(events.UnIdleStarted, nil)
+	err = idler.unIdleServices(ns)
+	idler.publish(events.UnIdleFinished, events.NewResult(err))
+	if err != nil {
+		return err
 	}
 
 	// NOTE: sometimes bc events get fired/handled before a DC event and the
@@ -299,6 +619,26 @@ func (idler *UserIdler) doUnIdle() error {
 
 }
 
+// unIdleServices un-idles all Jenkins services of the namespace.
+func (idler *UserIdler) unIdleServices(ns string) error {
+	for _, service := range JenkinsServices {
+		// Let's add some more reasons, we probably want to
+		reasonString := idler.buildReason()
+		idler.logger.WithField("attempt", fmt.Sprintf("(%d/%d)", idler.unIdleAttempts, idler.policy.MaxRetries)).Info("About to un-idle "+service+", Reason: ", reasonString)
+		action, err := idler.unIdleAction(ns, service)
+		if err == nil {
+			err = action.UnIdle(idler.openShiftAPI, idler.openShiftBearerToken, ns, service)
+		}
+		if err != nil {
+			idler.logger.Warnf("Failed to un-idle service %v in namespace %v (un-idle attempt: %v)", service, ns, idler.unIdleAttempts)
+			idler.logger.Error(err)
+			return err
+		}
+		idler.logger.Infof("Successfully un-idled service %v in namespace %v (un-idle attempt: %v)", service, ns, idler.unIdleAttempts)
+	}
+	return nil
+}
+
 func (idler *UserIdler) isIdlerEnabled() (bool, error) {
 	enabled, err := idler.features.IsIdlerEnabled(idler.user.ID)
 	if err != nil {
@@ -317,12 +657,41 @@ func (idler *UserIdler) isIdlerEnabled() (bool, error) {
 	return false, nil
 }
 
+// idleAction returns the strategy used to idle Jenkins according to the current policy.
+func (idler *UserIdler) idleAction() IdleAction {
+	if idler.policy.SoftIdle && idler.softIdleResources != nil {
+		return NewSoftAction(idler.openShiftClient, *idler.softIdleResources)
+	}
+	return NewScaleAction(idler.openShiftClient)
+}
+
+// unIdleAction returns the strategy used to un-idle the given service. It depends on how the service has been
+// idled, as recorded by the DeploymentConfig, rather than on the current policy which might have changed since.
+func (idler *UserIdler) unIdleAction(ns string, service string) (IdleAction, error) {
+	dc, err := idler.openShiftClient.DeploymentConfig(idler.openShiftAPI, idler.openShiftBearerToken, ns, service)
+	if err != nil {
+		return nil, err
+	}
+	if dc.SoftIdled() {
+		return NewSoftAction(idler.openShiftClient, v1.ResourceRequirements{}), nil
+	}
+	return NewScaleAction(idler.openShiftClient), nil
+}
+
+// getJenkinsState returns the state of Jenkins, reporting a soft-idled Jenkins as idled regardless of the
+// current policy.
 func (idler *UserIdler) getJenkinsState() (model.PodState, error) {
 	ns := idler.user.Name + jenkinsNamespaceSuffix
-	state, err := idler.openShiftClient.State(idler.openShiftAPI, idler.openShiftBearerToken, ns, jenkinsServiceName)
+	dc, err := idler.openShiftClient.DeploymentConfig(idler.openShiftAPI, idler.openShiftBearerToken, ns, jenkinsServiceName)
 	if err != nil {
 		return model.PodStateUnknown, err
 	}
+
+	state := dc.State()
+	if dc.SoftIdled() {
+		state = model.PodIdled
+	}
+	idler.trackState(state)
 	return state, nil
 }
 
@@ -339,15 +708,209 @@ func (idler *UserIdler) resetCounters() {
 	idler.unIdleAttempts = 0
 }
 
-func createWatchConditions(proxyURL string, idleAfter int, idleLongBuild int, log *logrus.Entry) *condition.Conditions {
+// createConditions creates the conditions according to the current policy of this UserIdler.
+func (idler *UserIdler) createConditions() *condition.Conditions {
+	conditions := createWatchConditions(idler.config, idler.proxyClient, idler.hungBuildPolicy,
+		idler.policy.IdleAfter, idler.policy.IdleLongBuild, idler.logger)
+	conditions.SetCombination(idler.combination)
+	if idler.jenkinsClient != nil {
+		conditions.Add("jenkins", condition.NewJenkinsCondition(idler.jenkinsClient))
+	}
+	if idler.metricsClient != nil {
+		conditions.Add("metrics", condition.NewMetricsCondition(idler.metricsClient,
+			time.Duration(idler.config.GetMetricsWindow())*time.Minute,
+			idler.config.GetMetricsCPUThreshold(), idler.config.GetMetricsNetworkThreshold()))
+	}
+	if idler.decisionCondition != nil {
+		conditions.Add("decision", idler.decisionCondition)
+	}
+	if len(idler.schedules) > 0 {
+		conditions.AddOverride("schedule", condition.NewScheduleCondition(idler.schedules))
+	}
+	// a lease is applied last, so that it keeps Jenkins running even during an idle schedule
+	conditions.AddOverride("lease", condition.NewLeaseCondition(idler.leases))
+	if idler.predictor != nil {
+		conditions.Add("prewarm", condition.NewPrewarmCondition(idler.predictor))
+	}
+	return conditions
+}
+
+// resolveJenkinsURL returns the URL of the Jenkins route of the given namespace. The URL is looked up once
+// and cached afterwards.
+func (idler *UserIdler) resolveJenkinsURL(namespace string) (string, error) {
+	if idler.jenkinsURL != "" {
+		return idler.jenkinsURL, nil
+	}
+
+	url, err := idler.openShiftClient.RouteURL(idler.openShiftAPI, idler.openShiftBearerToken, namespace, jenkinsServiceName)
+	if err != nil {
+		return "", err
+	}
+	idler.jenkinsURL = url
+	return url, nil
+}
+
+// refreshPolicy applies the policy annotations of the Jenkins DeploymentConfig and its project,
+// the former taking precedence. The project annotations are re-read at most every projectCheckInterval.
+// If the annotations changed, the conditions are re-created and invalid values are reported as events.
+func (idler *UserIdler) refreshPolicy() {
+	ns := idler.user.Name + jenkinsNamespaceSuffix
+	if time.Since(idler.projectCheckedAt) > projectCheckInterval {
+		annotations, err := idler.openShiftClient.ProjectAnnotations(idler.openShiftAPI, idler.openShiftBearerToken, ns)
+		if err != nil {
+			idler.logger.Warnf("Failed to get annotations of project %s: %s", ns, err)
+		} else {
+			idler.projectAnnotations = annotations
+			idler.projectCheckedAt = time.Now()
+		}
+	}
+
+	annotations := idler.projectAnnotations.Merge(idler.user.PolicyAnnotations)
+	if annotations == idler.annotations {
+		return
+	}
+	idler.annotations = annotations
+
+	policy, errors := newPolicy(idler.config, idler.user.Name, idler.tier).apply(annotations)
+	for _, err := range errors {
+		idler.logger.Warn(err)
+		idler.reportInvalidAnnotation(err)
+	}
+
+	idler.logger.Infof("Policy changed to %+v", policy)
+	idler.policy = policy
+	idler.Conditions = idler.createConditions()
+}
+
+// reportInvalidAnnotation creates a warning event for the Jenkins DeploymentConfig.
+func (idler *UserIdler) reportInvalidAnnotation(err error) {
+	ns := idler.user.Name + jenkinsNamespaceSuffix
+	now := metav1.Now()
+	event := v1.Event{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: jenkinsServiceName + ".", Namespace: ns},
+		InvolvedObject: v1.ObjectReference{
+			Kind:      "DeploymentConfig",
+			Namespace: ns,
+			Name:      jenkinsServiceName,
+		},
+		Reason:         "InvalidIdlerAnnotation",
+		Message:        err.Error(),
+		Type:           v1.EventTypeWarning,
+		Source:         v1.EventSource{Component: "jenkins-idler"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+
+	if err := idler.openShiftClient.CreateEvent(idler.openShiftAPI, idler.openShiftBearerToken, ns, event); err != nil {
+		idler.logger.Errorf("Failed to create event for invalid annotation: %s", err)
+	}
+}
+
+func createWatchConditions(config configuration.Configuration, proxyClient condition.ProxyClient,
+	hung condition.HungBuildPolicy, idleAfter int, idleLongBuild int, log *logrus.Entry) *condition.Conditions {
 	conditions := condition.NewConditions()
 
 	conditions.Add("dc", condition.NewDCCondition(time.Duration(idleAfter)*time.Minute))
 
 	// Add a Build condition.
-	conditions.Add("build", condition.NewBuildCondition(
+	conditions.Add("build", condition.NewHungBuildCondition(
 		time.Duration(idleAfter)*time.Minute,
-		time.Duration(idleLongBuild)*time.Hour))
+		time.Duration(idleLongBuild)*time.Hour,
+		hung))
+
+	// Add a User condition based on the activity seen by the Jenkins Proxy.
+	if proxyClient != nil {
+		conditions.Add("user", condition.NewUserCondition(proxyClient,
+			time.Duration(config.GetUserConditionIdleAfter())*time.Minute,
+			config.GetProxyUnavailable() == configuration.ProxyUnavailableVeto))
+	}
 
 	return &conditions
 }
+
+// createSchedules returns the configured schedules which apply to the given user on the given cluster.
+// Invalid schedules are logged and skipped.
+func createSchedules(config configuration.Configuration, userName string, openShiftAPI string, log *logrus.Entry) []condition.Schedule {
+	var schedules []condition.Schedule
+	for _, s := range config.GetSchedules() {
+		if !s.AppliesTo(userName, openShiftAPI, config.GetTeams()) {
+			continue
+		}
+
+		schedule, err := condition.NewSchedule(s.Name, s.Action, s.Days, s.From, s.To, s.TimeZone)
+		if err != nil {
+			log.Errorf("Skipping invalid schedule: %s", err)
+			continue
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules
+}
+
+// createCombination returns the configured combination of the condition results. If the configuration
+// is invalid, the error is logged and the results are combined by taking the max.
+func createCombination(config configuration.Configuration, log *logrus.Entry) condition.Combination {
+	c := config.GetCombination()
+	combination := condition.Combination{
+		Order:   c.Order,
+		Vote:    c.Combine == "vote",
+		Weights: c.Weights,
+	}
+
+	for _, r := range c.Rules {
+		then, err := condition.ParseAction(r.Then)
+		if err != nil {
+			log.Errorf("Ignoring invalid condition combination, rule %q: %s", r.Name, err)
+			return condition.Combination{}
+		}
+		when, err := createExpr(r.When)
+		if err != nil {
+			log.Errorf("Ignoring invalid condition combination, rule %q: %s", r.Name, err)
+			return condition.Combination{}
+		}
+		combination.Rules = append(combination.Rules, condition.Rule{Name: r.Name, When: when, Then: then})
+	}
+	return combination
+}
+
+// createDecisionCondition returns the condition which delegates to the decision webhook at the given URL.
+// Invalid failure actions are rejected by the configuration's Verify, for unverified configurations they
+// default to no action.
+func createDecisionCondition(config configuration.Configuration, url string, openShiftAPI string, log *logrus.Entry) condition.Condition {
+	failure, err := condition.ParseAction(config.GetDecisionWebhookFailure())
+	if err != nil {
+		log.Errorf("Invalid failure action of decision webhook, defaulting to %q: %s", condition.NoAction, err)
+	}
+	return condition.NewWebhookCondition(url, openShiftAPI,
+		time.Duration(config.GetDecisionWebhookTimeout())*time.Second,
+		time.Duration(config.GetDecisionWebhookCache())*time.Second,
+		failure)
+}
+
+func createExpr(e configuration.CombinationExpr) (condition.Expr, error) {
+	expr := condition.Expr{Condition: e.Condition}
+	if e.Condition != "" {
+		is, err := condition.ParseAction(e.Is)
+		if err != nil {
+			return expr, err
+		}
+		expr.Is = is
+	}
+
+	for _, sub := range e.All {
+		s, err := createExpr(sub)
+		if err != nil {
+			return expr, err
+		}
+		expr.All = append(expr.All, s)
+	}
+	for _, sub := range e.Any {
+		s, err := createExpr(sub)
+		if err != nil {
+			return expr, err
+		}
+		expr.Any = append(expr.Any, s)
+	}
+	return expr, nil
+}
@@ -2,32 +2,49 @@ package idler
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/condition"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/common"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
 	log "github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type ErrorCondition struct {
 }
 
-func (c *ErrorCondition) Eval(object interface{}) (condition.Action, error) {
-	return condition.NoAction, errors.New("eval error")
+func (c *ErrorCondition) Eval(object interface{}) (condition.Verdict, error) {
+	return condition.Verdict{}, errors.New("eval error")
 }
 
 type UnIdleCondition struct {
 }
 
-func (c *UnIdleCondition) Eval(object interface{}) (condition.Action, error) {
-	return condition.UnIdle, nil
+func (c *UnIdleCondition) Eval(object interface{}) (condition.Verdict, error) {
+	return condition.NewVerdict(condition.UnIdle, "unidle"), nil
+}
+
+type IdleCondition struct {
+}
+
+func (c *IdleCondition) Eval(object interface{}) (condition.Verdict, error) {
+	return condition.NewVerdict(condition.Idle, "idle"), nil
 }
 
 func Test_idle_check_skipped_if_feature_not_enabled(t *testing.T) {
@@ -39,10 +56,10 @@ func Test_idle_check_skipped_if_feature_not_enabled(t *testing.T) {
 
 	user := model.User{ID: "100"}
 	userIdler := NewUserIdler(
-		user, "", "", &mock.Config{},
+		user, "", "", "", &mock.Config{},
 		mock.NewMockFeatureToggle([]string{"42"}),
 		&mock.TenantService{},
-	)
+		nil, nil)
 
 	err := userIdler.checkIdle()
 	assert.NoError(t, err, "No error expected.")
@@ -56,10 +73,10 @@ func Test_idle_check_returns_error_on_evaluation_failure(t *testing.T) {
 
 	user := model.User{ID: "42"}
 	userIdler := NewUserIdler(
-		user, "", "", &mock.Config{},
+		user, "", "", "", &mock.Config{},
 		mock.NewMockFeatureToggle([]string{"42"}),
 		&mock.TenantService{},
-	)
+		nil, nil)
 	userIdler.Conditions.Add("error", &ErrorCondition{})
 
 	err := userIdler.checkIdle()
@@ -76,7 +93,7 @@ func Test_idle_check_occurs_even_without_openshift_events(t *testing.T) {
 	config := &mock.Config{}
 	features := mock.NewMockFeatureToggle([]string{"42"})
 	tenantService := &mock.TenantService{}
-	userIdler := NewUserIdler(user, "", "", config, features, tenantService)
+	userIdler := NewUserIdler(user, "", "", "", config, features, tenantService, nil, nil)
 
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
@@ -130,7 +147,7 @@ func Test_number_of_idle_calls_are_capped(t *testing.T) {
 	config.MaxRetries = maxRetry
 	features := mock.NewMockFeatureToggle([]string{"42"})
 	tenantService := &mock.TenantService{}
-	userIdler := NewUserIdler(user, "", "", config, features, tenantService)
+	userIdler := NewUserIdler(user, "", "", "", config, features, tenantService, nil, nil)
 	userIdler.openShiftClient = openShiftClient
 
 	var wg sync.WaitGroup
@@ -179,7 +196,7 @@ func Test_number_of_unidle_calls_are_capped(t *testing.T) {
 	features := mock.NewMockFeatureToggle([]string{"42"})
 	tenantService := &mock.TenantService{}
 
-	userIdler := NewUserIdler(user, "", "", config, features, tenantService)
+	userIdler := NewUserIdler(user, "", "", "", config, features, tenantService, nil, nil)
 	userIdler.openShiftClient = openShiftClient
 	conditions := condition.NewConditions()
 	conditions.Add("unidle", &UnIdleCondition{})
@@ -215,6 +232,311 @@ func Test_number_of_unidle_calls_are_capped(t *testing.T) {
 	assert.Equal(t, 0, openShiftClient.IdleCallCount, "There should be no idle calls.")
 }
 
+func Test_dry_run_never_idles_or_unidles(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "John Doe"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodIdled}
+
+	config := &mock.Config{MaxRetries: 5, DryRunUsers: []string{"John Doe"}}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := condition.NewConditions()
+	conditions.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &conditions
+
+	assert.True(t, userIdler.IsDryRun(), "User idler should be in dry-run mode")
+
+	err := userIdler.checkIdle()
+	assert.NoError(t, err, "No error expected.")
+	assert.Equal(t, 0, openShiftClient.UnIdleCallCount, "There should be no un-idle calls.")
+	assert.Equal(t, 0, openShiftClient.IdleCallCount, "There should be no idle calls.")
+
+	decision, ok := userIdler.History().Last()
+	assert.True(t, ok, "Decision should have been recorded")
+	assert.True(t, decision.DryRun, "Decision should be marked as dry-run")
+	assert.Equal(t, "unidle", decision.Action, "Unexpected action")
+	assert.True(t, decision.WouldUnIdle(), "Decision should report that jenkins would be un-idled")
+}
+
+func Test_decisions_are_recorded(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "John Doe"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodIdled}
+
+	config := &mock.Config{MaxRetries: 5}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := condition.NewConditions()
+	conditions.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &conditions
+
+	assert.False(t, userIdler.IsDryRun(), "User idler should not be in dry-run mode")
+
+	err := userIdler.checkIdle()
+	assert.NoError(t, err, "No error expected.")
+	assert.Equal(t, 1, openShiftClient.UnIdleCallCount, "There should be one un-idle call.")
+
+	decision, ok := userIdler.History().Last()
+	assert.True(t, ok, "Decision should have been recorded")
+	assert.False(t, decision.DryRun, "Decision should not be marked as dry-run")
+	assert.Equal(t, "unidle", decision.Action, "Unexpected action")
+}
+
+func Test_only_applicable_schedules_are_created(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	config := &mock.Config{
+		Schedules: []configuration.Schedule{
+			{Name: "all", Action: "idle"},
+			{Name: "team", Action: "keep", Teams: []string{"a-team"}},
+			{Name: "other-cluster", Action: "idle", Clusters: []string{"https://api.other/"}},
+			{Name: "invalid", Action: "idle", From: "noon"},
+		},
+		Teams: map[string][]string{"a-team": {"john"}},
+	}
+
+	schedules := createSchedules(config, "john", "https://api.cluster/", logger)
+	var names []string
+	for _, s := range schedules {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"all", "team"}, names, "Unexpected schedules")
+
+	schedules = createSchedules(config, "jane", "https://api.cluster/", logger)
+	assert.Len(t, schedules, 1, "Only the schedule for all users should apply")
+}
+
+func Test_build_starts_are_observed_for_prewarming(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	config := &mock.Config{Prewarm: true, PrewarmWindow: 30, PrewarmThreshold: 0.5, PrewarmMinWeeks: 1}
+	userIdler := NewUserIdler(model.NewUser("42", "john"), "", "", "", config,
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	assert.NotNil(t, userIdler.predictor, "Predictor should have been created")
+
+	// monday 9:00 UTC
+	started := time.Date(2018, time.April, 2, 9, 0, 0, 0, time.UTC)
+	previous := userIdler.user
+	userIdler.user.StartBuild(model.Build{
+		Metadata: model.Metadata{Name: "build-1"},
+		Status:   model.Status{Phase: "Running", StartTimestamp: model.BuildTime{Time: started}},
+	})
+	userIdler.observeBuild(previous)
+
+	next := started.Add(2*7*24*time.Hour - 15*time.Minute)
+	assert.True(t, userIdler.predictor.Predict(next), "Build should have been observed")
+
+	userIdler = NewUserIdler(model.NewUser("42", "john"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	assert.Nil(t, userIdler.predictor, "Prewarming is disabled by default")
+	userIdler.ObserveActivity(started)
+}
+
+func Test_predictor_is_seeded_with_existing_builds(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	// monday 9:00 UTC in two consecutive weeks
+	started := time.Date(2018, time.April, 2, 9, 0, 0, 0, time.UTC)
+	build := func(name string, jenkinsNS string, started time.Time) model.Build {
+		return model.Build{
+			Metadata: model.Metadata{Name: name, Annotations: model.Annotations{JenkinsNamespace: jenkinsNS}},
+			Status:   model.Status{Phase: "Complete", StartTimestamp: model.BuildTime{Time: started}},
+		}
+	}
+	openShiftClient := &mock.OpenShiftClient{BuildList: model.BuildList{Items: []model.Build{
+		build("build-2", "john-jenkins", started.Add(7*24*time.Hour)),
+		build("build-1", "john-jenkins", started),
+		build("other-1", "jane-jenkins", started.Add(3*time.Hour)),
+	}}}
+
+	config := &mock.Config{Prewarm: true, PrewarmWindow: 30, PrewarmThreshold: 0.5, PrewarmMinWeeks: 1}
+	userIdler := NewUserIdler(model.NewUser("42", "john"), "", "", "", config,
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	userIdler.seedPredictor()
+
+	next := started.Add(2*7*24*time.Hour - 15*time.Minute)
+	assert.True(t, userIdler.predictor.Predict(next), "Existing builds should have been observed")
+	assert.False(t, userIdler.predictor.Predict(next.Add(3*time.Hour)), "Builds of other Jenkins should be ignored")
+}
+
+func Test_idle_is_announced_and_can_be_vetoed(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	var notifications []PreIdleNotification
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := PreIdleNotification{}
+		json.NewDecoder(r.Body).Decode(&n)
+		notifications = append(notifications, n)
+	}))
+	defer webhook.Close()
+
+	user := model.User{ID: "42", Name: "john"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	config := &mock.Config{MaxRetries: 5, PreIdleWebhook: webhook.URL, PreIdleGracePeriod: 10}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := condition.NewConditions()
+	conditions.Add("idle", &IdleCondition{})
+	userIdler.Conditions = &conditions
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 0, openShiftClient.IdleCallCount, "Jenkins should not be idled during the grace period")
+	assert.Len(t, notifications, 1, "Idle should have been announced")
+	assert.Equal(t, "john-jenkins", notifications[0].Namespace, "Unexpected namespace")
+	pending, ok := userIdler.PendingIdle()
+	assert.True(t, ok, "Idle should be pending")
+	assert.Equal(t, pending.IdleAt.Unix(), notifications[0].IdleAt.Unix(), "Unexpected idle time")
+
+	assert.True(t, userIdler.KeepAlive(), "Pending idle should have been cancelled")
+	_, ok = userIdler.PendingIdle()
+	assert.False(t, ok, "No idle should be pending after keep alive")
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 0, openShiftClient.IdleCallCount, "Jenkins should be kept alive")
+	assert.Len(t, notifications, 1, "No idle should be announced while Jenkins is kept alive")
+
+	userIdler.grace.keptAliveUntil = time.Time{}
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Len(t, notifications, 2, "Idle should have been announced again")
+
+	userIdler.grace.pending.IdleAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.IdleCallCount, "Jenkins should be idled after the grace period")
+	_, ok = userIdler.PendingIdle()
+	assert.False(t, ok, "No idle should be pending after idling")
+}
+
+func Test_pending_idle_is_cancelled_by_activity(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "john"}
+	config := &mock.Config{MaxRetries: 5, PreIdleGracePeriod: 10}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = &mock.OpenShiftClient{IdleState: model.PodRunning}
+	conditions := condition.NewConditions()
+	conditions.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &conditions
+
+	userIdler.grace.start(time.Now())
+	_, ok := userIdler.PendingIdle()
+	assert.True(t, ok, "Idle should be pending")
+
+	assert.NoError(t, userIdler.checkIdle())
+	_, ok = userIdler.PendingIdle()
+	assert.False(t, ok, "Pending idle should have been cancelled")
+}
+
+func Test_soft_idle_shrinks_and_restores_jenkins(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "john"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	config := &mock.Config{MaxRetries: 5, SoftIdleUsers: []string{"john"}, SoftIdleCPU: "100m", SoftIdleMemory: "512Mi"}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	idle := condition.NewConditions()
+	idle.Add("idle", &IdleCondition{})
+	userIdler.Conditions = &idle
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.IdleCallCount, "Jenkins should have been soft-idled")
+	assert.True(t, openShiftClient.SoftIdledState, "Jenkins should have been soft-idled")
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.IdleCallCount, "Soft-idled Jenkins should be reported as idled")
+
+	unIdle := condition.NewConditions()
+	unIdle.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &unIdle
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.UnIdleCallCount, "Resources should have been restored")
+	assert.False(t, openShiftClient.SoftIdledState, "Jenkins should not be soft-idled anymore")
+}
+
+func Test_soft_idled_jenkins_is_restored_after_policy_change(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	// soft-idled while the policy asked for it, which it does not anymore
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning, SoftIdledState: true}
+	userIdler := NewUserIdler(model.NewUser("42", "john"), "", "", "", &mock.Config{MaxRetries: 5},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	unIdle := condition.NewConditions()
+	unIdle.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &unIdle
+
+	state, err := userIdler.getJenkinsState()
+	assert.NoError(t, err)
+	assert.Equal(t, model.PodState(model.PodIdled), state, "Soft-idled Jenkins should be reported as idled")
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.UnIdleCallCount, "Resources should have been restored")
+	assert.False(t, openShiftClient.SoftIdledState, "Jenkins should not be soft-idled anymore")
+}
+
+func Test_invalid_soft_idle_resources_fall_back_to_scaling(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	config := &mock.Config{SoftIdle: true, SoftIdleCPU: "a lot", SoftIdleMemory: "512Mi"}
+	userIdler := NewUserIdler(model.NewUser("42", "john"), "", "", "", config,
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	assert.Nil(t, userIdler.softIdleResources, "Invalid resources should not be used")
+	assert.IsType(t, &scaleAction{}, userIdler.idleAction(), "Jenkins should be scaled down")
+}
+
+func Test_policy_annotations_recreate_conditions(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "john"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	config := &mock.Config{MaxRetries: 5, IdleAfter: 45, IdleLongBuild: 3}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := userIdler.Conditions
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.True(t, conditions == userIdler.Conditions, "Conditions should not change without annotations")
+
+	// the DC annotation takes precedence over the project annotation
+	openShiftClient.ProjectPolicy = model.PolicyAnnotations{IdleAfter: "60", LongBuildTimeout: "12"}
+	userIdler.projectCheckedAt = time.Time{}
+	userIdler.user.PolicyAnnotations = model.PolicyAnnotations{IdleAfter: "90"}
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, Policy{IdleAfter: 90, IdleLongBuild: 12, MaxRetries: 5}, userIdler.policy)
+	assert.False(t, conditions == userIdler.Conditions, "Conditions should have been re-created")
+	assert.Empty(t, openShiftClient.Events, "No events expected for valid annotations")
+
+	userIdler.user.PolicyAnnotations = model.PolicyAnnotations{IdleAfter: "soon", Disabled: "true"}
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, Policy{IdleAfter: 45, IdleLongBuild: 12, MaxRetries: 5, Disabled: true}, userIdler.policy)
+	assert.Len(t, openShiftClient.Events, 1, "Invalid annotation should have been reported")
+	assert.Equal(t, "InvalidIdlerAnnotation", openShiftClient.Events[0].Reason)
+	assert.Equal(t, "john-jenkins", openShiftClient.Events[0].InvolvedObject.Namespace)
+
+	idle := condition.NewConditions()
+	idle.Add("idle", &IdleCondition{})
+	userIdler.Conditions = &idle
+	openShiftClient.ResetCounts()
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 0, openShiftClient.IdleCallCount, "Idler should be disabled via annotation")
+	assert.Len(t, openShiftClient.Events, 1, "Unchanged annotations should not be reported again")
+}
+
 func extractLogMessages(entries []*log.Entry) []string {
 	messages := []string{}
 	for _, logEntry := range entries {
@@ -222,3 +544,279 @@ func extractLogMessages(entries []*log.Entry) []string {
 	}
 	return messages
 }
+
+func Test_create_combination(t *testing.T) {
+	logEntry := log.WithField("component", "test")
+	config := &mock.Config{Combination: configuration.Combination{
+		Combine: "vote",
+		Rules: []configuration.CombinationRule{{
+			Name: "veto",
+			When: configuration.CombinationExpr{Any: []configuration.CombinationExpr{{Condition: "build", Is: "unidle"}}},
+			Then: "none",
+		}},
+	}}
+
+	combination := createCombination(config, logEntry)
+	assert.True(t, combination.Vote)
+	assert.Equal(t, []condition.Rule{{
+		Name: "veto",
+		When: condition.Expr{Any: []condition.Expr{{Condition: "build", Is: condition.UnIdle}}},
+		Then: condition.NoAction,
+	}}, combination.Rules)
+
+	config.Combination.Rules[0].Then = "maybe"
+	assert.Equal(t, condition.Combination{}, createCombination(config, logEntry), "Invalid combination should fall back to max")
+}
+
+func Test_decision_is_explained(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "john", JenkinsLastUpdate: time.Now().Add(-10 * time.Minute)}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	config := &mock.Config{MaxRetries: 5}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "", "", config, features, &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := condition.NewConditions()
+	conditions.Add("dc", condition.NewDCCondition(time.Hour))
+	userIdler.Conditions = &conditions
+
+	_, ok := userIdler.Explain()
+	assert.False(t, ok, "Nothing to explain before the first evaluation")
+
+	assert.NoError(t, userIdler.checkIdle())
+	explanation, ok := userIdler.Explain()
+	assert.True(t, ok, "Evaluation should be explained")
+	assert.Equal(t, condition.UnIdle, explanation.Evaluation.Action)
+	assert.Equal(t, "max of all conditions", explanation.Evaluation.Reason)
+	dc := explanation.Evaluation.Conditions["dc"]
+	assert.Equal(t, user.JenkinsLastUpdate.Unix(), dc.Timestamps["jenkins_last_update"].Unix())
+	assert.NotNil(t, explanation.NextIdle, "Next idle should be forecast")
+	assert.Equal(t, user.JenkinsLastUpdate.Add(time.Hour).Unix(), explanation.NextIdle.Unix(), "Unexpected next idle")
+
+	userIdler.user.PolicyAnnotations = model.PolicyAnnotations{Disabled: "true"}
+	assert.NoError(t, userIdler.checkIdle())
+	explanation, _ = userIdler.Explain()
+	assert.Equal(t, "idler disabled via annotation", explanation.Evaluation.Reason)
+	assert.Nil(t, explanation.NextIdle, "Disabled idler should not forecast an idle")
+}
+
+func Test_busy_jenkins_is_kept_up(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	jenkins := common.NewFakeJenkins("token")
+	defer jenkins.Close()
+	jenkins.BusyExecutors = 1
+
+	user := model.User{ID: "42", Name: "john"}
+	config := &mock.Config{MaxRetries: 5, JenkinsCondition: true, JenkinsTimeout: 1}
+	features := mock.NewMockFeatureToggle([]string{"42"})
+
+	userIdler := NewUserIdler(user, "", "token", "", config, features, &mock.TenantService{}, nil, nil)
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning, JenkinsURL: jenkins.URL}
+	userIdler.openShiftClient = openShiftClient
+
+	assert.NoError(t, userIdler.checkIdle())
+	explanation, _ := userIdler.Explain()
+	assert.Equal(t, condition.UnIdle, explanation.Evaluation.Conditions["jenkins"].Action, "Busy executor should keep Jenkins up")
+	assert.Equal(t, 0, openShiftClient.IdleCallCount, "Busy Jenkins should not be idled")
+	assert.Equal(t, jenkins.URL, userIdler.jenkinsURL, "Jenkins URL should be cached")
+
+	jenkins.BusyExecutors = 0
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, 1, openShiftClient.IdleCallCount, "Jenkins should be idled once it is not busy anymore")
+}
+
+func Test_hung_builds_are_cancelled_and_explained(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	config := &mock.Config{MaxRetries: 5, IdleAfter: 10, IdleLongBuild: 10, InputWaitTimeout: 60, InputWaitAction: "cancel"}
+	userIdler := NewUserIdler(model.NewUser("42", "john"), "", "", "", config,
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	userIdler.openShiftClient = openShiftClient
+
+	build := model.Build{
+		Metadata: model.Metadata{Name: "app-1", Namespace: "john"},
+		Status:   model.Status{Phase: "Running", StartTimestamp: model.BuildTime{Time: time.Now().Add(-2 * time.Hour)}},
+	}
+	build.Metadata.Annotations.JenkinsStatus = fmt.Sprintf(
+		`{"status": "PAUSED_PENDING_INPUT", "stages": [{"name": "Approve", "status": "PAUSED_PENDING_INPUT", "startTimeMillis": %d}]}`,
+		time.Now().Add(-90*time.Minute).UnixNano()/int64(time.Millisecond))
+	userIdler.user.StartBuild(build)
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Equal(t, []string{"app-1"}, openShiftClient.CancelledBuilds, "Build waiting on input should have been cancelled")
+	assert.Len(t, openShiftClient.Events, 1, "Cancellation should have been reported")
+
+	explanation, ok := userIdler.Explain()
+	assert.True(t, ok)
+	assert.Len(t, explanation.HungBuilds, 1)
+	assert.True(t, explanation.HungBuilds[0].Cancelled)
+	assert.Equal(t, condition.AwaitingInput, explanation.HungBuilds[0].State)
+
+	assert.NoError(t, userIdler.checkIdle())
+	assert.Len(t, openShiftClient.CancelledBuilds, 1, "Build should only be cancelled once")
+}
+
+func Test_decision_webhook_is_evaluated(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"action": "unidle", "reason": "on call"}`))
+	}))
+	defer webhook.Close()
+
+	config := &mock.Config{MaxRetries: 5, DecisionWebhook: webhook.URL, DecisionWebhookTimeout: 1, DecisionWebhookFailure: "none"}
+	userIdler := NewUserIdler(model.User{ID: "42", Name: "john"}, "https://api.cluster/", "", "", config,
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = &mock.OpenShiftClient{IdleState: model.PodRunning}
+
+	assert.NoError(t, userIdler.checkIdle())
+	explanation, _ := userIdler.Explain()
+	assert.Equal(t, condition.UnIdle, explanation.Evaluation.Conditions["decision"].Action)
+	assert.Equal(t, "on call", explanation.Evaluation.Conditions["decision"].Reason)
+}
+
+func Test_summary(t *testing.T) {
+	userIdler := NewUserIdler(model.NewUser("42", "foo"), "http://cluster/", "", "", &mock.Config{PreIdleGracePeriod: 10},
+		mock.NewMockFeatureToggle([]string{}), &mock.TenantService{}, nil, nil)
+
+	summary := userIdler.Summary()
+	assert.Equal(t, "http://cluster/", summary.Cluster)
+	assert.Equal(t, "42", summary.User.ID)
+	assert.Equal(t, model.PodStateUnknown, summary.State, "State should not be observed yet")
+	assert.True(t, summary.NextCheck.IsZero(), "No check should be scheduled yet")
+
+	userIdler.scheduleCheck(time.Hour)
+	userIdler.trackState(model.PodRunning)
+	summary = userIdler.Summary()
+	assert.Equal(t, model.PodState(model.PodRunning), summary.State)
+	assert.False(t, summary.StateObservedAt.IsZero(), "Observation should be timestamped")
+	assert.WithinDuration(t, time.Now().Add(time.Hour), summary.NextCheck, time.Minute)
+
+	// the end of the grace period of a pending idle comes before the scheduled check
+	pending, _ := userIdler.grace.start(time.Now())
+	assert.Equal(t, pending.IdleAt, userIdler.Summary().NextCheck)
+
+	userIdler.clearScheduledCheck()
+	userIdler.KeepAlive()
+	assert.True(t, userIdler.Summary().NextCheck.IsZero(), "No check should be scheduled")
+}
+
+func Test_decisions_and_actions_are_published(t *testing.T) {
+	bus := events.NewBus()
+	published, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	userIdler := NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{MaxRetries: 5},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, bus, nil)
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodRunning}
+	userIdler.openShiftClient = openShiftClient
+
+	userIdler.setEvaluation(condition.Evaluation{EvaluatedAt: time.Now().UTC(), Reason: "test"})
+	assert.NoError(t, userIdler.doIdle())
+	openShiftClient.IdleState = model.PodIdled
+	assert.NoError(t, userIdler.doUnIdle())
+
+	var types []string
+	for len(published) > 0 {
+		event := <-published
+		assert.Equal(t, "foo-jenkins", event.Namespace)
+		if event.Type == events.IdleFinished || event.Type == events.UnIdleFinished {
+			assert.Equal(t, events.Result{Success: true}, event.Data)
+		}
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{events.Evaluation, events.IdleStarted, events.IdleFinished, events.UnIdleStarted, events.UnIdleFinished}, types)
+}
+
+func Test_lease_keeps_jenkins_running(t *testing.T) {
+	leases := lease.NewLeases(store.NewMemoryStore())
+	userIdler := NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, leases)
+	userIdler.Conditions.Add("idle", &IdleCondition{})
+
+	pinned, err := userIdler.Pin(time.Hour, "release testing")
+	require.NoError(t, err)
+	active, ok := userIdler.Lease()
+	assert.True(t, ok, "Lease should be active")
+	assert.Equal(t, pinned, active)
+	shared, ok := leases.Get("foo-jenkins", time.Now())
+	assert.True(t, ok, "Lease should be stored in the shared leases")
+	assert.Equal(t, "release testing", shared.Reason)
+
+	request := <-userIdler.checkChan
+	assert.False(t, request.dryRun, "Taking a lease should trigger an idle check")
+	request.report <- CheckReport{}
+
+	evaluation, errors := userIdler.Conditions.Eval(userIdler.user)
+	assert.True(t, errors.Empty())
+	assert.Equal(t, condition.UnIdle, evaluation.Action, "Lease should veto idling")
+	assert.Equal(t, "kept alive: release testing", evaluation.Overrides["lease"].Reason)
+
+	revoked, err := userIdler.Unpin()
+	require.NoError(t, err)
+	assert.True(t, revoked, "Active lease should be revoked")
+	revoked, _ = userIdler.Unpin()
+	assert.False(t, revoked, "No lease should be left to revoke")
+	evaluation, _ = userIdler.Conditions.Eval(userIdler.user)
+	assert.Equal(t, condition.Idle, evaluation.Action)
+
+	// expired leases are not active
+	require.NoError(t, leases.Pin(lease.Entry{Namespace: "foo-jenkins", Reason: "expired", Expires: time.Now().Add(time.Millisecond)}))
+	time.Sleep(2 * time.Millisecond)
+	_, ok = userIdler.Lease()
+	assert.False(t, ok, "Expired lease should not be active")
+}
+
+func Test_forced_check_takes_or_previews_action(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	user := model.User{ID: "42", Name: "John Doe"}
+	openShiftClient := &mock.OpenShiftClient{IdleState: model.PodIdled}
+	userIdler := NewUserIdler(user, "", "", "", &mock.Config{MaxRetries: 5},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+	userIdler.openShiftClient = openShiftClient
+	conditions := condition.NewConditions()
+	conditions.Add("unidle", &UnIdleCondition{})
+	userIdler.Conditions = &conditions
+
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(context.Background())
+	userIdler.Run(ctx, &wg, cancel, time.Hour, time.Hour)
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	report, err := userIdler.Check(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, condition.UnIdle, report.Evaluation.Action)
+	assert.Equal(t, "unidle", report.Decision.Action)
+	assert.True(t, report.Decision.WouldUnIdle(), "Preview should report that jenkins would be un-idled")
+	assert.Equal(t, "John Doe", report.User.Name)
+	assert.Equal(t, 0, openShiftClient.UnIdleCallCount, "Preview should not un-idle")
+	_, recorded := userIdler.History().Last()
+	assert.False(t, recorded, "Preview should not be recorded")
+
+	report, err = userIdler.Check(ctx, false)
+	require.NoError(t, err)
+	assert.False(t, report.DryRun)
+	assert.Equal(t, "unidle", report.Decision.Action)
+	assert.Equal(t, 1, report.UnIdleAttempts)
+	assert.Equal(t, 5, report.MaxRetries)
+	assert.Equal(t, 1, openShiftClient.UnIdleCallCount, "Check should un-idle")
+}
+
+func Test_forced_check_waits_for_running_idler(t *testing.T) {
+	userIdler := NewUserIdler(model.NewUser("42", "foo"), "", "", "", &mock.Config{},
+		mock.NewMockFeatureToggle([]string{"42"}), &mock.TenantService{}, nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := userIdler.Check(ctx, true)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,148 @@
+package lease
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+)
+
+// ConfigMapKey is the key of the config map data under which the entries are stored as JSON.
+const ConfigMapKey = "leases.json"
+
+// Entry is a keep-alive lease which keeps the Jenkins of a namespace running until it expires.
+type Entry struct {
+	Namespace string    `json:"namespace"`
+	Reason    string    `json:"reason"`
+	Created   time.Time `json:"created"`
+	Expires   time.Time `json:"expires"`
+}
+
+// Expired returns true if the lease has expired at the given point in time.
+func (e Entry) Expired(now time.Time) bool {
+	return !now.Before(e.Expires)
+}
+
+// Leases are the keep-alive leases by namespace. They are cached in memory and kept in sync with the store,
+// so that leases taken resp. revoked by other replicas are picked up by Refresh.
+type Leases struct {
+	lock    sync.RWMutex
+	entries map[string]Entry
+	store   store.Store
+}
+
+// NewLeases creates the keep-alive leases persisted to the given store.
+func NewLeases(s store.Store) *Leases {
+	return &Leases{entries: make(map[string]Entry), store: s}
+}
+
+// Get returns the lease of the given namespace if it is active at the given point in time.
+func (l *Leases) Get(namespace string, now time.Time) (Entry, bool) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+
+	entry, ok := l.entries[namespace]
+	if !ok || entry.Expired(now) {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Entries returns the active leases, ordered by namespace.
+func (l *Leases) Entries() []Entry {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	return active(l.entries, time.Now())
+}
+
+// Pin stores the given lease, replacing any lease of its namespace.
+func (l *Leases) Pin(entry Entry) error {
+	_, err := l.update(func(entries map[string]Entry) bool {
+		entries[entry.Namespace] = entry
+		return true
+	})
+	return err
+}
+
+// Unpin revokes the lease of the given namespace. It returns true if the lease was active.
+func (l *Leases) Unpin(namespace string) (bool, error) {
+	return l.update(func(entries map[string]Entry) bool {
+		entry, ok := entries[namespace]
+		delete(entries, namespace)
+		return ok && !entry.Expired(time.Now())
+	})
+}
+
+// update applies the given change to the entries loaded from the store and saves the active entries. The
+// update is retried if the store was changed concurrently. It returns the result of the last applied change.
+func (l *Leases) update(change func(map[string]Entry) bool) (bool, error) {
+	var changed bool
+	var result []Entry
+	err := store.Update(l.store, func(data []byte) (interface{}, error) {
+		entries := []Entry{}
+		if err := store.Decode(data, &entries); err != nil {
+			return nil, err
+		}
+
+		updated := make(map[string]Entry, len(entries)+1)
+		for _, entry := range entries {
+			updated[entry.Namespace] = entry
+		}
+		changed = change(updated)
+
+		result = active(updated, time.Now())
+		return result, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	l.set(result)
+	return changed, nil
+}
+
+// Refresh reloads the entries from the store.
+func (l *Leases) Refresh() error {
+	data, _, err := l.store.Load()
+	if err != nil {
+		return err
+	}
+	entries := []Entry{}
+	if err := store.Decode(data, &entries); err != nil {
+		return err
+	}
+	l.set(entries)
+	return nil
+}
+
+// Watch refreshes the entries from the store in the given interval until the context is done.
+func (l *Leases) Watch(ctx context.Context, wg *sync.WaitGroup, interval time.Duration) {
+	store.Watch(ctx, wg, interval, l.Refresh, "leases")
+}
+
+func (l *Leases) set(entries []Entry) {
+	byNamespace := make(map[string]Entry, len(entries))
+	for _, entry := range entries {
+		byNamespace[entry.Namespace] = entry
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.entries = byNamespace
+}
+
+// active returns the entries which have not expired at the given point in time, ordered by namespace.
+func active(entries map[string]Entry, now time.Time) []Entry {
+	result := []Entry{}
+	for _, entry := range entries {
+		if !entry.Expired(now) {
+			result = append(result, entry)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Namespace < result[j].Namespace
+	})
+	return result
+}
@@ -0,0 +1,60 @@
+package lease
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_pin_and_unpin(t *testing.T) {
+	leases := NewLeases(store.NewMemoryStore())
+	expires := time.Now().Add(time.Hour)
+
+	require.NoError(t, leases.Pin(Entry{Namespace: "bob-jenkins", Reason: "release", Expires: expires}))
+	require.NoError(t, leases.Pin(Entry{Namespace: "alice-jenkins", Reason: "demo", Expires: expires}))
+	require.NoError(t, leases.Pin(Entry{Namespace: "alice-jenkins", Reason: "longer demo", Expires: expires.Add(time.Hour)}))
+
+	entries := leases.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "alice-jenkins", entries[0].Namespace)
+	assert.Equal(t, "longer demo", entries[0].Reason, "Lease should replace the one of its namespace")
+
+	revoked, err := leases.Unpin("alice-jenkins")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = leases.Unpin("alice-jenkins")
+	require.NoError(t, err)
+	assert.False(t, revoked, "Revoked lease should not be revoked again")
+	_, ok := leases.Get("alice-jenkins", time.Now())
+	assert.False(t, ok)
+}
+
+func Test_expired_leases_are_dropped(t *testing.T) {
+	leases := NewLeases(store.NewMemoryStore())
+	now := time.Now()
+
+	require.NoError(t, leases.Pin(Entry{Namespace: "alice-jenkins", Expires: now.Add(-time.Minute)}))
+	require.NoError(t, leases.Pin(Entry{Namespace: "bob-jenkins", Expires: now.Add(time.Minute)}))
+	_, ok := leases.Get("alice-jenkins", now)
+	assert.False(t, ok, "Expired lease should not be active")
+	_, ok = leases.Get("bob-jenkins", now.Add(2*time.Minute))
+	assert.False(t, ok, "Lease should not be active after it expired")
+	assert.Len(t, leases.Entries(), 1)
+}
+
+func Test_refresh_picks_up_leases_of_other_replicas(t *testing.T) {
+	shared := store.NewMemoryStore()
+	replica1 := NewLeases(shared)
+	replica2 := NewLeases(shared)
+
+	require.NoError(t, replica1.Pin(Entry{Namespace: "alice-jenkins", Expires: time.Now().Add(time.Hour)}))
+	_, ok := replica2.Get("alice-jenkins", time.Now())
+	assert.False(t, ok, "Lease of other replica should only be seen after refresh")
+
+	require.NoError(t, replica2.Refresh())
+	_, ok = replica2.Get("alice-jenkins", time.Now())
+	assert.True(t, ok)
+}
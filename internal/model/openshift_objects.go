@@ -79,6 +79,36 @@ type Annotations struct {
 	IdledAt          string `json:"idling.alpha.openshift.io/idled-at,omitempty"`
 	UnidleTargets    string `json:"idling.alpha.openshift.io/unidle-targets,omitempty"`
 	PrevScale        string `json:"idling.alpha.openshift.io/previous-scale,omitempty"`
+	// JenkinsStatus is the pipeline status as reported by the Jenkins sync plugin.
+	JenkinsStatus string `json:"openshift.io/jenkins-status-json,omitempty"`
+	// OriginalResources are the container resources of a soft-idled DeploymentConfig before it was soft-idled.
+	OriginalResources string `json:"idler.fabric8.io/original-resources,omitempty"`
+	PolicyAnnotations
+}
+
+// PolicyAnnotations are the annotations of a Jenkins DeploymentConfig or its project which override
+// the global idling configuration for a single namespace.
+type PolicyAnnotations struct {
+	// IdleAfter is the number of minutes before Jenkins is idled.
+	IdleAfter string `json:"idler.fabric8.io/idle-after,omitempty"`
+	// LongBuildTimeout is the number of hours a long running build keeps Jenkins from being idled.
+	LongBuildTimeout string `json:"idler.fabric8.io/long-build-timeout,omitempty"`
+	// Disabled disables idling resp. un-idling if set to true.
+	Disabled string `json:"idler.fabric8.io/disabled,omitempty"`
+}
+
+// Merge returns the annotations overridden by all non empty values of the given annotations.
+func (a PolicyAnnotations) Merge(overrides PolicyAnnotations) PolicyAnnotations {
+	if overrides.IdleAfter != "" {
+		a.IdleAfter = overrides.IdleAfter
+	}
+	if overrides.LongBuildTimeout != "" {
+		a.LongBuildTimeout = overrides.LongBuildTimeout
+	}
+	if overrides.Disabled != "" {
+		a.Disabled = overrides.Disabled
+	}
+	return a
 }
 
 // Endpoint is the how a service is getting accessed.
@@ -102,6 +132,36 @@ type DeploymentConfig struct {
 	Spec     Spec     `json:"spec,omitempty"`
 }
 
+// State returns `PodIdled` if the deployment is scaled down, `PodStarting` if it is in the process
+// of scaling up and `PodRunning` if it is fully up.
+func (dc DeploymentConfig) State() PodState {
+	if dc.Status.Replicas == 0 {
+		return PodIdled
+	}
+	if dc.Status.ReadyReplicas == 0 {
+		return PodStarting
+	}
+	return PodRunning
+}
+
+// SoftIdled returns true if the resources of the deployment have been reduced instead of scaling it down.
+func (dc DeploymentConfig) SoftIdled() bool {
+	return dc.Metadata.Annotations.OriginalResources != ""
+}
+
+// RolloutFailed returns true and the reason reported by OpenShift if the latest rollout of the deployment has
+// failed, e.g. because it exceeded its progress deadline.
+func (dc DeploymentConfig) RolloutFailed() (string, bool) {
+	c, err := dc.Status.GetByType("Progressing")
+	if err != nil || c.Status != "False" {
+		return "", false
+	}
+	if c.Message != "" {
+		return c.Message, true
+	}
+	return c.Reason, true
+}
+
 // DCStatus represents the current deployment state.
 type DCStatus struct {
 	Replicas            int
@@ -116,6 +176,8 @@ type Condition struct {
 	Type           string
 	LastUpdateTime time.Time
 	Status         string
+	Reason         string
+	Message        string
 }
 
 // Spec holds all the input necessary to produce a new build, and the conditions when to trigger them.
@@ -168,6 +230,11 @@ func (bt *BuildTime) UnmarshalJSON(b []byte) (err error) {
 	return
 }
 
+// MarshalJSON writes the time in the same format as OpenShift does, so that it can be read back by UnmarshalJSON.
+func (bt BuildTime) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bt.Time.Format(time.RFC3339))
+}
+
 // UnmarshalJSON gets a Status Object from raw bytes.
 func (s *Status) UnmarshalJSON(b []byte) (err error) {
 	type LStatus Status
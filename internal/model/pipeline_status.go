@@ -0,0 +1,87 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	// PipelineInProgress is the status of a running pipeline resp. stage.
+	PipelineInProgress = "IN_PROGRESS"
+	// PipelinePendingInput is the status of a pipeline resp. stage waiting on an input step.
+	PipelinePendingInput = "PAUSED_PENDING_INPUT"
+)
+
+// PipelineStatus is the status of a Jenkins pipeline run as reported by the Jenkins sync plugin
+// in the openshift.io/jenkins-status-json annotation of a build.
+type PipelineStatus struct {
+	Status          string          `json:"status"`
+	StartTimeMillis int64           `json:"startTimeMillis"`
+	Stages          []PipelineStage `json:"stages"`
+}
+
+// PipelineStage is the status of a single stage of a pipeline run.
+type PipelineStage struct {
+	Name            string `json:"name"`
+	Status          string `json:"status"`
+	StartTimeMillis int64  `json:"startTimeMillis"`
+	DurationMillis  int64  `json:"durationMillis"`
+}
+
+// PipelineStatus returns the pipeline status of the build. The second return value is false if the
+// build has no resp. an invalid status annotation.
+func (b Build) PipelineStatus() (PipelineStatus, bool) {
+	if b.Metadata.Annotations.JenkinsStatus == "" {
+		return PipelineStatus{}, false
+	}
+
+	status := PipelineStatus{}
+	if err := json.Unmarshal([]byte(b.Metadata.Annotations.JenkinsStatus), &status); err != nil {
+		return PipelineStatus{}, false
+	}
+	return status, true
+}
+
+// Finished returns true if the pipeline has finished, no matter whether successfully or not.
+func (p PipelineStatus) Finished() bool {
+	switch p.Status {
+	case "SUCCESS", "UNSTABLE", "FAILED", "ABORTED":
+		return true
+	}
+	return false
+}
+
+// AwaitingInput returns true if the pipeline waits on an input step.
+func (p PipelineStatus) AwaitingInput() bool {
+	if p.Status == PipelinePendingInput {
+		return true
+	}
+	stage, ok := p.CurrentStage()
+	return ok && stage.Status == PipelinePendingInput
+}
+
+// CurrentStage returns the last stage which has not finished yet. The second return value is false
+// if there is no such stage.
+func (p PipelineStatus) CurrentStage() (PipelineStage, bool) {
+	for i := len(p.Stages) - 1; i >= 0; i-- {
+		if p.Stages[i].Status == PipelineInProgress || p.Stages[i].Status == PipelinePendingInput {
+			return p.Stages[i], true
+		}
+	}
+	return PipelineStage{}, false
+}
+
+// StartTime returns the point in time the stage started.
+func (s PipelineStage) StartTime() time.Time {
+	return time.Unix(0, s.StartTimeMillis*int64(time.Millisecond)).UTC()
+}
+
+// summary identifies the state of the pipeline, it changes on status resp. stage transitions only
+// and not on the regular updates of the durations.
+func (p PipelineStatus) summary() string {
+	summary := p.Status
+	if stage, ok := p.CurrentStage(); ok {
+		summary += "/" + stage.Name + "/" + stage.Status
+	}
+	return summary
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"fmt"
+	"sort"
 	"time"
 )
 
@@ -9,12 +10,18 @@ import (
 // about latest builds and changes to Jenkins DC for the user, which is then used in decision
 // whether to (un)idle Jenkins.
 type User struct {
-	Name              string
-	ID                string
-	ActiveBuild       Build
+	Name string
+	ID   string
+	// ActiveBuilds are the builds currently running, by build name. The map is never modified
+	// in place, so that copies of a User can be handed to other goroutines.
+	ActiveBuilds      map[string]Build
 	DoneBuild         Build
 	JenkinsLastUpdate time.Time
 	IdleStatus        IdleStatus
+	// PolicyAnnotations are the policy annotations of the Jenkins DeploymentConfig
+	PolicyAnnotations PolicyAnnotations
+	// Tier is the name of the idle policy tier as assigned by the tenant service
+	Tier string
 }
 
 // IdleStatus contains information about the idle/un-idle status like timestamp
@@ -60,17 +67,101 @@ func NewUnidleStatus(err error) IdleStatus {
 // NewUser creates a new instance of a User given an id and name.
 func NewUser(id string, name string) User {
 	return User{
-		ID:          id,
-		Name:        name,
-		ActiveBuild: Build{Status: Status{Phase: "New"}},
-		DoneBuild:   Build{},
+		ID:           id,
+		Name:         name,
+		ActiveBuilds: map[string]Build{},
+		DoneBuild:    Build{},
 	}
 }
 
+// StartBuild records the given build as active. It returns true if the build was not active
+// before, its phase changed or its pipeline moved on to another status resp. stage.
+func (u *User) StartBuild(b Build) bool {
+	if last, ok := u.ActiveBuilds[b.Metadata.Name]; ok && last.Status.Phase == b.Status.Phase &&
+		pipelineSummary(last) == pipelineSummary(b) {
+		return false
+	}
+
+	active := u.copyActiveBuilds()
+	active[b.Metadata.Name] = b
+	u.ActiveBuilds = active
+	return true
+}
+
+// FinishBuild records the given build as the last done build and removes it from the active
+// builds. It returns true if anything changed.
+func (u *User) FinishBuild(b Build) bool {
+	changed := false
+	if u.DoneBuild.Status.Phase != b.Status.Phase || u.DoneBuild.Metadata.Name != b.Metadata.Name {
+		u.DoneBuild = b
+		changed = true
+	}
+
+	if _, ok := u.ActiveBuilds[b.Metadata.Name]; ok {
+		active := u.copyActiveBuilds()
+		delete(active, b.Metadata.Name)
+		u.ActiveBuilds = active
+		changed = true
+	}
+	return changed
+}
+
+// RemoveBuild removes the build of the given name from the active builds, e.g. because the build has been
+// deleted. Unlike FinishBuild it does not record the build as done. It returns true if the build was active.
+func (u *User) RemoveBuild(name string) bool {
+	if _, ok := u.ActiveBuilds[name]; !ok {
+		return false
+	}
+
+	active := u.copyActiveBuilds()
+	delete(active, name)
+	u.ActiveBuilds = active
+	return true
+}
+
+// ExpireBuilds removes the active builds started before the given time. Builds whose end was missed, e.g.
+// because they were deleted while the watch was down, would otherwise stay active forever. It returns the
+// names of the removed builds.
+func (u *User) ExpireBuilds(startedBefore time.Time) []string {
+	var expired []string
+	for name, b := range u.ActiveBuilds {
+		if started := b.Status.StartTimestamp.Time; !started.IsZero() && started.Before(startedBefore) {
+			expired = append(expired, name)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	active := u.copyActiveBuilds()
+	for _, name := range expired {
+		delete(active, name)
+	}
+	u.ActiveBuilds = active
+	sort.Strings(expired)
+	return expired
+}
+
+func pipelineSummary(b Build) string {
+	status, ok := b.PipelineStatus()
+	if !ok {
+		return ""
+	}
+	return status.summary()
+}
+
+func (u *User) copyActiveBuilds() map[string]Build {
+	active := make(map[string]Build, len(u.ActiveBuilds)+1)
+	for name, b := range u.ActiveBuilds {
+		active[name] = b
+	}
+	return active
+}
+
 // HasActiveBuilds checks if current user has any active builds.
 // If so true is returned, otherwise false.
 func (u *User) HasActiveBuilds() bool {
-	return len(u.ActiveBuild.Metadata.Name) > 0
+	return len(u.ActiveBuilds) > 0
 }
 
 // HasCompletedBuilds checks if current User has any completed/done builds,
@@ -79,13 +170,21 @@ func (u *User) HasCompletedBuilds() bool {
 	return len(u.DoneBuild.Metadata.Name) > 0
 }
 
-// LastBuild returns last Jenkins Build of the current user.
+// LastBuild returns last Jenkins Build of the current user, i.e. the latest started active build
+// if there is any, the last done build otherwise.
 func (u *User) LastBuild() Build {
-	if u.HasActiveBuilds() {
-		return u.ActiveBuild
+	if !u.HasActiveBuilds() {
+		return u.DoneBuild
 	}
 
-	return u.DoneBuild
+	var last Build
+	for _, b := range u.ActiveBuilds {
+		if last.Metadata.Name == "" || b.Status.StartTimestamp.Time.After(last.Status.StartTimestamp.Time) ||
+			(b.Status.StartTimestamp.Time.Equal(last.Status.StartTimestamp.Time) && b.Metadata.Name > last.Metadata.Name) {
+			last = b
+		}
+	}
+	return last
 }
 
 // HasBuilds checks if current User has any active od completed Builds,
@@ -97,6 +196,6 @@ func (u *User) HasBuilds() bool {
 // StateDump returns a String representing the internal states like
 // HasActiveBuilds, LastUpdate etc useful for debugging
 func (u *User) StateDump() string {
-	return fmt.Sprintf("HasBuilds:%t HasActiveBuilds:%t JenkinsLastUpdate:%v",
-		u.HasBuilds(), u.HasActiveBuilds(), u.JenkinsLastUpdate.Format(time.RFC822))
+	return fmt.Sprintf("HasBuilds:%t HasActiveBuilds:%t ActiveBuilds:%d JenkinsLastUpdate:%v",
+		u.HasBuilds(), u.HasActiveBuilds(), len(u.ActiveBuilds), u.JenkinsLastUpdate.Format(time.RFC822))
 }
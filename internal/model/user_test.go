@@ -2,7 +2,9 @@ package model
 
 import (
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,7 +13,58 @@ func Test_String(t *testing.T) {
 	user := User{ID: "42"}
 	userAsString := user.StateDump()
 
-	assert.Equal(t, userAsString, "HasBuilds:false HasActiveBuilds:false JenkinsLastUpdate:01 Jan 01 00:00 UTC", "Unexpected string format")
+	assert.Equal(t, userAsString, "HasBuilds:false HasActiveBuilds:false ActiveBuilds:0 JenkinsLastUpdate:01 Jan 01 00:00 UTC", "Unexpected string format")
+}
+
+func Test_concurrent_builds(t *testing.T) {
+	user := NewUser("42", "foo")
+	first := Build{Metadata: Metadata{Name: "foo-1"}, Status: Status{Phase: "Running"}}
+	second := Build{Metadata: Metadata{Name: "foo-2"}, Status: Status{Phase: "Pending"}}
+
+	assert.True(t, user.StartBuild(first))
+	assert.False(t, user.StartBuild(first), "Unchanged build should not be reported as change")
+	copied := user
+	assert.True(t, user.StartBuild(second))
+	assert.Len(t, copied.ActiveBuilds, 1, "Copies of the user must not be modified")
+	assert.Len(t, user.ActiveBuilds, 2)
+
+	first.Status.Phase = "Complete"
+	assert.True(t, user.FinishBuild(first))
+	assert.True(t, user.HasActiveBuilds(), "Second build is still running")
+	assert.Equal(t, "foo-2", user.LastBuild().Metadata.Name)
+	assert.Equal(t, "foo-1", user.DoneBuild.Metadata.Name)
+
+	second.Status.Phase = "Failed"
+	assert.True(t, user.FinishBuild(second))
+	assert.False(t, user.HasActiveBuilds())
+	assert.False(t, user.FinishBuild(second), "Finishing a build twice should not be reported as change")
+}
+
+func Test_deleted_and_expired_builds(t *testing.T) {
+	now := time.Now().UTC()
+	user := NewUser("42", "foo")
+	user.StartBuild(Build{Metadata: Metadata{Name: "foo-1"}, Status: Status{Phase: "Running", StartTimestamp: BuildTime{now.Add(-48 * time.Hour)}}})
+	user.StartBuild(Build{Metadata: Metadata{Name: "foo-2"}, Status: Status{Phase: "Running", StartTimestamp: BuildTime{now.Add(-time.Hour)}}})
+	user.StartBuild(Build{Metadata: Metadata{Name: "foo-3"}, Status: Status{Phase: "New"}})
+
+	copied := user
+	assert.Equal(t, []string{"foo-1"}, user.ExpireBuilds(now.Add(-24*time.Hour)))
+	assert.Len(t, copied.ActiveBuilds, 3, "Copies of the user must not be modified")
+	assert.Nil(t, user.ExpireBuilds(now.Add(-24*time.Hour)))
+
+	assert.True(t, user.RemoveBuild("foo-2"))
+	assert.False(t, user.RemoveBuild("foo-2"), "Removing a build twice should not be reported as change")
+	assert.Equal(t, []string{"foo-3"}, sortedNames(user.ActiveBuilds), "Builds which have not been started yet should be kept")
+	assert.False(t, user.HasCompletedBuilds(), "Removed builds should not be recorded as done")
+}
+
+func sortedNames(builds map[string]Build) []string {
+	var names []string
+	for name := range builds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
 
 func TestNewIdleStatus(t *testing.T) {
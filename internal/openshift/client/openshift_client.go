@@ -25,12 +25,26 @@ type OpenShiftClient interface {
 	Idle(apiURL string, bearerToken string, namespace string, service string) error
 	UnIdle(apiURL string, bearerToken string, namespace string, service string) error
 	State(apiURL string, bearerToken string, namespace string, service string) (model.PodState, error)
+	DeploymentConfig(apiURL string, bearerToken string, namespace string, service string) (*model.DeploymentConfig, error)
+	Builds(apiURL string, bearerToken string, namespace string) (model.BuildList, error)
 	WhoAmI(apiURL string, bearerToken string) (string, error)
 	WatchBuilds(apiURL string, bearerToken string, buildType string, callback func(model.Object) error) error
 	WatchDeploymentConfigs(apiURL string, bearerToken string, namespaceSuffix string, callback func(model.DCObject) error) error
 	Reset(apiURL string, bearerToken string, namespace string) error
+	SoftIdle(apiURL string, bearerToken string, namespace string, service string, resources v1.ResourceRequirements) error
+	SoftUnIdle(apiURL string, bearerToken string, namespace string, service string) error
+	SoftIdled(apiURL string, bearerToken string, namespace string, service string) (bool, error)
+	ProjectAnnotations(apiURL string, bearerToken string, namespace string) (model.PolicyAnnotations, error)
+	CreateEvent(apiURL string, bearerToken string, namespace string, event v1.Event) error
+	RouteURL(apiURL string, bearerToken string, namespace string, route string) (string, error)
+	CancelBuild(apiURL string, bearerToken string, namespace string, build string) error
+	ConfigMap(apiURL string, bearerToken string, namespace string, name string) (*v1.ConfigMap, error)
+	SaveConfigMap(apiURL string, bearerToken string, namespace string, configMap v1.ConfigMap) error
 }
 
+// ErrConflict is returned by SaveConfigMap if the config map has been changed since it was read.
+var ErrConflict = errors.New("conflicting change")
+
 type user struct {
 	Metadata struct {
 		Name string
@@ -242,30 +256,212 @@ func (o *openShift) UnIdle(apiURL string, bearerToken string, namespace string,
 // `PodStarting` if it is in the process of scaling up, `PodRunning`
 // if it is fully up.
 func (o *openShift) State(apiURL string, bearerToken string, namespace string, service string) (model.PodState, error) {
-	req, err := o.reqOAPI(apiURL, bearerToken, "GET", namespace, "deploymentconfigs/"+service, nil)
+	dc, err := o.DeploymentConfig(apiURL, bearerToken, namespace, service)
 	if err != nil {
 		return model.PodStateUnknown, err
 	}
-	resp, err := o.do(req)
+
+	return dc.State(), nil
+}
+
+// DeploymentConfig returns the DeploymentConfig of the given service in the given openShift namespace.
+func (o *openShift) DeploymentConfig(apiURL string, bearerToken string, namespace string, service string) (*model.DeploymentConfig, error) {
+	req, err := o.reqOAPI(apiURL, bearerToken, "GET", namespace, "deploymentconfigs/"+service, nil)
 	if err != nil {
-		return model.PodStateUnknown, err
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
 	}
 
 	defer bodyClose(resp)
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+
 	dc := &model.DeploymentConfig{}
-	err = json.NewDecoder(resp.Body).Decode(dc)
+	if err := json.NewDecoder(resp.Body).Decode(dc); err != nil {
+		return nil, err
+	}
+	return dc, nil
+}
+
+// ProjectAnnotations returns the policy annotations of the given openShift project.
+func (o *openShift) ProjectAnnotations(apiURL string, bearerToken string, namespace string) (model.PolicyAnnotations, error) {
+	req, err := o.reqOAPI(apiURL, bearerToken, "GET", "", "projects/"+namespace, nil)
 	if err != nil {
-		return model.PodStateUnknown, err
+		return model.PolicyAnnotations{}, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return model.PolicyAnnotations{}, err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return model.PolicyAnnotations{}, fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+
+	project := struct {
+		Metadata struct {
+			Annotations model.PolicyAnnotations `json:"annotations"`
+		} `json:"metadata"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&project)
+	if err != nil {
+		return model.PolicyAnnotations{}, err
+	}
+	return project.Metadata.Annotations, nil
+}
+
+// CreateEvent creates the given Kubernetes event in the given namespace.
+func (o *openShift) CreateEvent(apiURL string, bearerToken string, namespace string, event v1.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := o.reqAPI(apiURL, bearerToken, "POST", namespace, "events", ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+	return nil
+}
+
+// RouteURL returns the URL under which the given route of the given namespace is exposed, e.g. https://jenkins-foo.example.com.
+func (o *openShift) RouteURL(apiURL string, bearerToken string, namespace string, route string) (string, error) {
+	req, err := o.reqOAPI(apiURL, bearerToken, "GET", namespace, "routes/"+route, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+
+	r := struct {
+		Spec struct {
+			Host string `json:"host"`
+			TLS  *struct {
+				Termination string `json:"termination"`
+			} `json:"tls"`
+		} `json:"spec"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return "", err
+	}
+	if r.Spec.Host == "" {
+		return "", fmt.Errorf("route %s in namespace %s has no host", route, namespace)
 	}
+	return fmt.Sprintf("%s://%s", o.getScheme(r.Spec.TLS != nil), r.Spec.Host), nil
+}
+
+// CancelBuild cancels the given build in the given namespace, the same way as oc cancel-build does.
+func (o *openShift) CancelBuild(apiURL string, bearerToken string, namespace string, build string) error {
+	logger.WithField("ns", namespace).Infof("Cancelling build %s", build)
+
+	body := []byte(`{"status": {"cancelled": true}}`)
+	req, err := o.reqOAPI(apiURL, bearerToken, "PATCH", namespace, "builds/"+build, ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/strategic-merge-patch+json")
 
-	if dc.Status.Replicas == 0 {
-		return model.PodIdled, nil
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
 	}
-	if dc.Status.ReadyReplicas == 0 {
-		return model.PodStarting, nil
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
 	}
-	return model.PodRunning, nil
+	return nil
+}
+
+// ConfigMap returns the given config map of the given namespace, nil if it does not exist.
+func (o *openShift) ConfigMap(apiURL string, bearerToken string, namespace string, name string) (*v1.ConfigMap, error) {
+	req, err := o.reqAPI(apiURL, bearerToken, "GET", namespace, "configmaps/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+
+	configMap := &v1.ConfigMap{}
+	if err := json.NewDecoder(resp.Body).Decode(configMap); err != nil {
+		return nil, err
+	}
+	return configMap, nil
+}
+
+// SaveConfigMap creates the given config map in the given namespace if it has no resource version, and replaces
+// it otherwise. ErrConflict is returned if the config map has been changed since the resource version was read.
+func (o *openShift) SaveConfigMap(apiURL string, bearerToken string, namespace string, configMap v1.ConfigMap) error {
+	body, err := json.Marshal(configMap)
+	if err != nil {
+		return err
+	}
+
+	method, command := "PUT", "configmaps/"+configMap.Name
+	if configMap.ResourceVersion == "" {
+		method, command = "POST", "configmaps"
+	}
+	req, err := o.reqAPI(apiURL, bearerToken, method, namespace, command, ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode == http.StatusConflict {
+		return ErrConflict
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+	return nil
 }
 
 // GetScheme converts bool representing whether a route
@@ -451,19 +647,24 @@ func (o openShift) WhoAmI(apiURL string, bearerToken string) (string, error) {
 	return user.Metadata.Name, nil
 }
 
-// GetBuilds loads builds for a given namespace from openShift.
-func (o openShift) getBuilds(apiURL string, bearerToken string, namespace string) (bl model.BuildList, err error) {
+// Builds loads builds for a given namespace from openShift.
+func (o *openShift) Builds(apiURL string, bearerToken string, namespace string) (bl model.BuildList, err error) {
 	req, err := o.reqOAPI(apiURL, bearerToken, "GET", namespace, "builds", nil)
 	if err != nil {
 		return
 	}
 
-	resp, err := o.do(req)
+	resp, err := o.client.Do(req)
 	if err != nil {
 		return
 	}
 
 	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+		return
+	}
 	err = json.NewDecoder(resp.Body).Decode(&bl)
 	return
 }
@@ -536,12 +737,17 @@ func (o *openShift) patch(req *http.Request) (b []byte, err error) {
 	req.Header.Set("Accept", "application/json")
 	req.Header.Set("Content-Type", "application/strategic-merge-patch+json")
 
-	resp, err := o.do(req)
+	resp, err := o.client.Do(req)
 	if err != nil {
 		return
 	}
 
 	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		err = fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+		return
+	}
 	b, err = ioutil.ReadAll(resp.Body)
 	return
 }
@@ -7,6 +7,7 @@ package client
 import (
 	model "github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	gomock "github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
 	reflect "reflect"
 )
 
@@ -76,6 +77,36 @@ func (mr *MockOpenShiftClientMockRecorder) State(apiURL, bearerToken, namespace,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "State", reflect.TypeOf((*MockOpenShiftClient)(nil).State), apiURL, bearerToken, namespace, service)
 }
 
+// DeploymentConfig mocks base method
+func (m *MockOpenShiftClient) DeploymentConfig(apiURL, bearerToken, namespace, service string) (*model.DeploymentConfig, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeploymentConfig", apiURL, bearerToken, namespace, service)
+	ret0, _ := ret[0].(*model.DeploymentConfig)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeploymentConfig indicates an expected call of DeploymentConfig
+func (mr *MockOpenShiftClientMockRecorder) DeploymentConfig(apiURL, bearerToken, namespace, service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeploymentConfig", reflect.TypeOf((*MockOpenShiftClient)(nil).DeploymentConfig), apiURL, bearerToken, namespace, service)
+}
+
+// Builds mocks base method
+func (m *MockOpenShiftClient) Builds(apiURL, bearerToken, namespace string) (model.BuildList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Builds", apiURL, bearerToken, namespace)
+	ret0, _ := ret[0].(model.BuildList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Builds indicates an expected call of Builds
+func (mr *MockOpenShiftClientMockRecorder) Builds(apiURL, bearerToken, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Builds", reflect.TypeOf((*MockOpenShiftClient)(nil).Builds), apiURL, bearerToken, namespace)
+}
+
 // WhoAmI mocks base method
 func (m *MockOpenShiftClient) WhoAmI(apiURL, bearerToken string) (string, error) {
 	m.ctrl.T.Helper()
@@ -132,3 +163,133 @@ func (mr *MockOpenShiftClientMockRecorder) Reset(apiURL, bearerToken, namespace
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockOpenShiftClient)(nil).Reset), apiURL, bearerToken, namespace)
 }
+
+// SoftIdle mocks base method
+func (m *MockOpenShiftClient) SoftIdle(apiURL, bearerToken, namespace, service string, resources v1.ResourceRequirements) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftIdle", apiURL, bearerToken, namespace, service, resources)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftIdle indicates an expected call of SoftIdle
+func (mr *MockOpenShiftClientMockRecorder) SoftIdle(apiURL, bearerToken, namespace, service, resources interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftIdle", reflect.TypeOf((*MockOpenShiftClient)(nil).SoftIdle), apiURL, bearerToken, namespace, service, resources)
+}
+
+// SoftUnIdle mocks base method
+func (m *MockOpenShiftClient) SoftUnIdle(apiURL, bearerToken, namespace, service string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftUnIdle", apiURL, bearerToken, namespace, service)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftUnIdle indicates an expected call of SoftUnIdle
+func (mr *MockOpenShiftClientMockRecorder) SoftUnIdle(apiURL, bearerToken, namespace, service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftUnIdle", reflect.TypeOf((*MockOpenShiftClient)(nil).SoftUnIdle), apiURL, bearerToken, namespace, service)
+}
+
+// SoftIdled mocks base method
+func (m *MockOpenShiftClient) SoftIdled(apiURL, bearerToken, namespace, service string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftIdled", apiURL, bearerToken, namespace, service)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftIdled indicates an expected call of SoftIdled
+func (mr *MockOpenShiftClientMockRecorder) SoftIdled(apiURL, bearerToken, namespace, service interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftIdled", reflect.TypeOf((*MockOpenShiftClient)(nil).SoftIdled), apiURL, bearerToken, namespace, service)
+}
+
+// ProjectAnnotations mocks base method
+func (m *MockOpenShiftClient) ProjectAnnotations(apiURL, bearerToken, namespace string) (model.PolicyAnnotations, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProjectAnnotations", apiURL, bearerToken, namespace)
+	ret0, _ := ret[0].(model.PolicyAnnotations)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ProjectAnnotations indicates an expected call of ProjectAnnotations
+func (mr *MockOpenShiftClientMockRecorder) ProjectAnnotations(apiURL, bearerToken, namespace interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProjectAnnotations", reflect.TypeOf((*MockOpenShiftClient)(nil).ProjectAnnotations), apiURL, bearerToken, namespace)
+}
+
+// CreateEvent mocks base method
+func (m *MockOpenShiftClient) CreateEvent(apiURL, bearerToken, namespace string, event v1.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEvent", apiURL, bearerToken, namespace, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEvent indicates an expected call of CreateEvent
+func (mr *MockOpenShiftClientMockRecorder) CreateEvent(apiURL, bearerToken, namespace, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEvent", reflect.TypeOf((*MockOpenShiftClient)(nil).CreateEvent), apiURL, bearerToken, namespace, event)
+}
+
+// RouteURL mocks base method
+func (m *MockOpenShiftClient) RouteURL(apiURL, bearerToken, namespace, route string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RouteURL", apiURL, bearerToken, namespace, route)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RouteURL indicates an expected call of RouteURL
+func (mr *MockOpenShiftClientMockRecorder) RouteURL(apiURL, bearerToken, namespace, route interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RouteURL", reflect.TypeOf((*MockOpenShiftClient)(nil).RouteURL), apiURL, bearerToken, namespace, route)
+}
+
+// CancelBuild mocks base method
+func (m *MockOpenShiftClient) CancelBuild(apiURL, bearerToken, namespace, build string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelBuild", apiURL, bearerToken, namespace, build)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelBuild indicates an expected call of CancelBuild
+func (mr *MockOpenShiftClientMockRecorder) CancelBuild(apiURL, bearerToken, namespace, build interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelBuild", reflect.TypeOf((*MockOpenShiftClient)(nil).CancelBuild), apiURL, bearerToken, namespace, build)
+}
+
+// ConfigMap mocks base method
+func (m *MockOpenShiftClient) ConfigMap(apiURL, bearerToken, namespace, name string) (*v1.ConfigMap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfigMap", apiURL, bearerToken, namespace, name)
+	ret0, _ := ret[0].(*v1.ConfigMap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfigMap indicates an expected call of ConfigMap
+func (mr *MockOpenShiftClientMockRecorder) ConfigMap(apiURL, bearerToken, namespace, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfigMap", reflect.TypeOf((*MockOpenShiftClient)(nil).ConfigMap), apiURL, bearerToken, namespace, name)
+}
+
+// SaveConfigMap mocks base method
+func (m *MockOpenShiftClient) SaveConfigMap(apiURL, bearerToken, namespace string, configMap v1.ConfigMap) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveConfigMap", apiURL, bearerToken, namespace, configMap)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveConfigMap indicates an expected call of SaveConfigMap
+func (mr *MockOpenShiftClientMockRecorder) SaveConfigMap(apiURL, bearerToken, namespace, configMap interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveConfigMap", reflect.TypeOf((*MockOpenShiftClient)(nil).SaveConfigMap), apiURL, bearerToken, namespace, configMap)
+}
@@ -0,0 +1,100 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_route_url(t *testing.T) {
+	route := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/oapi/v1/namespaces/john-jenkins/routes/jenkins", r.URL.Path)
+		w.Write([]byte(route))
+	}))
+	defer server.Close()
+
+	c := NewOpenShift()
+
+	route = `{"spec": {"host": "jenkins-john.example.com", "tls": {"termination": "edge"}}}`
+	url, err := c.RouteURL(server.URL, "token", "john-jenkins", "jenkins")
+	require.NoError(t, err)
+	assert.Equal(t, "https://jenkins-john.example.com", url)
+
+	route = `{"spec": {"host": "jenkins-john.example.com"}}`
+	url, err = c.RouteURL(server.URL, "token", "john-jenkins", "jenkins")
+	require.NoError(t, err)
+	assert.Equal(t, "http://jenkins-john.example.com", url)
+
+	route = `{"spec": {}}`
+	_, err = c.RouteURL(server.URL, "token", "john-jenkins", "jenkins")
+	assert.Error(t, err, "Route without host should be rejected")
+}
+
+func Test_cancel_build(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/oapi/v1/namespaces/john/builds/app-1", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.JSONEq(t, `{"status": {"cancelled": true}}`, string(body))
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	err := NewOpenShift().CancelBuild(server.URL, "token", "john", "app-1")
+	assert.NoError(t, err)
+}
+
+func Test_save_config_map(t *testing.T) {
+	tests := []struct {
+		version string
+		method  string
+		path    string
+		status  int
+		err     error
+	}{
+		{"", "POST", "/api/v1/namespaces/idler/configmaps", http.StatusCreated, nil},
+		{"42", "PUT", "/api/v1/namespaces/idler/configmaps/disabled-users", http.StatusOK, nil},
+		{"41", "PUT", "/api/v1/namespaces/idler/configmaps/disabled-users", http.StatusConflict, ErrConflict},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, test.method, r.Method)
+			assert.Equal(t, test.path, r.URL.Path)
+			w.WriteHeader(test.status)
+			w.Write([]byte(`{}`))
+		}))
+
+		configMap := v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "disabled-users", ResourceVersion: test.version}}
+		err := NewOpenShift().SaveConfigMap(server.URL, "token", "idler", configMap)
+		assert.Equal(t, test.err, err)
+		server.Close()
+	}
+}
+
+func Test_config_map(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/idler/configmaps/disabled-users" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"metadata": {"name": "disabled-users", "resourceVersion": "42"}, "data": {"users.json": "[]"}}`))
+	}))
+	defer server.Close()
+
+	configMap, err := NewOpenShift().ConfigMap(server.URL, "token", "idler", "disabled-users")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", configMap.ResourceVersion)
+	assert.Equal(t, "[]", configMap.Data["users.json"])
+
+	configMap, err = NewOpenShift().ConfigMap(server.URL, "token", "idler", "unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, configMap, "Missing config map should be nil")
+}
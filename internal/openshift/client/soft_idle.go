@@ -0,0 +1,162 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"k8s.io/api/core/v1"
+)
+
+// OriginalResourcesAnnotation is the DeploymentConfig annotation under which the original
+// container resources of a soft-idled service are stored.
+const OriginalResourcesAnnotation = "idler.fabric8.io/original-resources"
+
+// dcTemplate is the part of a DeploymentConfig needed to shrink resp. restore its resources.
+type dcTemplate struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		Template struct {
+			Spec struct {
+				Containers []v1.Container `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+}
+
+// SoftIdle reduces the resources of all containers of the given service to the specified resources
+// instead of scaling it down. The original resources are kept in an annotation of the DeploymentConfig.
+// Soft-idling an already soft-idled service keeps the original resources.
+func (o *openShift) SoftIdle(apiURL string, bearerToken string, namespace string, service string, resources v1.ResourceRequirements) error {
+	log := logger.WithField("ns", namespace)
+	log.Infof("Soft-idling service %s in namespace %s", service, namespace)
+
+	dc, err := o.getDCTemplate(apiURL, bearerToken, namespace, service)
+	if err != nil {
+		return err
+	}
+
+	original, ok := dc.Metadata.Annotations[OriginalResourcesAnnotation]
+	if !ok {
+		current := make(map[string]v1.ResourceRequirements)
+		for _, c := range dc.Spec.Template.Spec.Containers {
+			current[c.Name] = c.Resources
+		}
+		b, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		original = string(b)
+	}
+
+	reduced := make(map[string]v1.ResourceRequirements)
+	for _, c := range dc.Spec.Template.Spec.Containers {
+		reduced[c.Name] = resources
+	}
+
+	return o.patchResources(apiURL, bearerToken, namespace, service, reduced, &original)
+}
+
+// SoftUnIdle restores the original resources of a soft-idled service and removes the annotation
+// holding them. It is a no-op if the service is not soft-idled.
+func (o *openShift) SoftUnIdle(apiURL string, bearerToken string, namespace string, service string) error {
+	log := logger.WithField("ns", namespace)
+
+	dc, err := o.getDCTemplate(apiURL, bearerToken, namespace, service)
+	if err != nil {
+		return err
+	}
+
+	original, ok := dc.Metadata.Annotations[OriginalResourcesAnnotation]
+	if !ok {
+		log.Infof("Service %s in namespace %s is not soft-idled", service, namespace)
+		return nil
+	}
+
+	log.Infof("Restoring resources of service %s in namespace %s", service, namespace)
+	resources := make(map[string]v1.ResourceRequirements)
+	if err := json.Unmarshal([]byte(original), &resources); err != nil {
+		return fmt.Errorf("invalid %s annotation: %s", OriginalResourcesAnnotation, err)
+	}
+
+	return o.patchResources(apiURL, bearerToken, namespace, service, resources, nil)
+}
+
+// SoftIdled returns true if the given service is soft-idled.
+func (o *openShift) SoftIdled(apiURL string, bearerToken string, namespace string, service string) (bool, error) {
+	dc, err := o.getDCTemplate(apiURL, bearerToken, namespace, service)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := dc.Metadata.Annotations[OriginalResourcesAnnotation]
+	return ok, nil
+}
+
+func (o *openShift) getDCTemplate(apiURL string, bearerToken string, namespace string, service string) (*dcTemplate, error) {
+	req, err := o.reqOAPI(apiURL, bearerToken, "GET", namespace, "deploymentconfigs/"+service, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer bodyClose(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status %s (%d) from %s", resp.Status, resp.StatusCode, req.URL)
+	}
+
+	dc := &dcTemplate{}
+	err = json.NewDecoder(resp.Body).Decode(dc)
+	return dc, err
+}
+
+// patchResources replaces the resources of the given containers. If original is nil the
+// annotation holding the original resources is removed, otherwise it is set to original.
+func (o *openShift) patchResources(apiURL string, bearerToken string, namespace string, service string,
+	resources map[string]v1.ResourceRequirements, original *string) error {
+
+	var containers []map[string]interface{}
+	for name, r := range resources {
+		b, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		replace := make(map[string]interface{})
+		if err := json.Unmarshal(b, &replace); err != nil {
+			return err
+		}
+		// replace instead of merge, so that limits resp. requests not set originally are removed again
+		replace["$patch"] = "replace"
+		containers = append(containers, map[string]interface{}{"name": name, "resources": replace})
+	}
+
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{OriginalResourcesAnnotation: original},
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{"containers": containers},
+			},
+		},
+	}
+	body, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	req, err := o.reqOAPI(apiURL, bearerToken, "PATCH", namespace, "deploymentconfigs/"+service, ioutil.NopCloser(bytes.NewReader(body)))
+	if err != nil {
+		return err
+	}
+	_, err = o.patch(req)
+	return err
+}
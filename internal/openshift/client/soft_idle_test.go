@@ -0,0 +1,73 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const jenkinsDC = `{
+  "metadata": {"name": "jenkins", "annotations": {%s}},
+  "spec": {"template": {"spec": {"containers": [
+    {"name": "jenkins", "resources": {"limits": {"memory": "1Gi"}, "requests": {"cpu": "1", "memory": "1Gi"}}}
+  ]}}}
+}`
+
+func Test_soft_idle_stores_original_resources(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	annotations := ""
+	var patch map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/oapi/v1/namespaces/john-jenkins/deploymentconfigs/jenkins", r.URL.Path)
+		if r.Method == "PATCH" {
+			assert.Equal(t, "application/strategic-merge-patch+json", r.Header.Get("Content-Type"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			w.Write([]byte("{}"))
+			return
+		}
+		w.Write([]byte(fmt.Sprintf(jenkinsDC, annotations)))
+	}))
+	defer server.Close()
+
+	c := NewOpenShiftWithClient(server.Client())
+	soft, err := c.SoftIdled(server.URL, "token", "john-jenkins", "jenkins")
+	require.NoError(t, err)
+	assert.False(t, soft, "Jenkins should not be soft-idled")
+
+	low := v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m"), v1.ResourceMemory: resource.MustParse("512Mi")}
+	err = c.SoftIdle(server.URL, "token", "john-jenkins", "jenkins", v1.ResourceRequirements{Limits: low, Requests: low})
+	require.NoError(t, err)
+
+	original := patch["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})[OriginalResourcesAnnotation].(string)
+	assert.JSONEq(t, `{"jenkins": {"limits": {"memory": "1Gi"}, "requests": {"cpu": "1", "memory": "1Gi"}}}`, original)
+
+	containers := patch["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	resources := containers[0].(map[string]interface{})["resources"].(map[string]interface{})
+	assert.Equal(t, "replace", resources["$patch"], "Resources should be replaced")
+	assert.Equal(t, "512Mi", resources["limits"].(map[string]interface{})["memory"])
+
+	// restore the original resources
+	b, _ := json.Marshal(original)
+	annotations = `"` + OriginalResourcesAnnotation + `": ` + string(b)
+	soft, err = c.SoftIdled(server.URL, "token", "john-jenkins", "jenkins")
+	require.NoError(t, err)
+	assert.True(t, soft, "Jenkins should be soft-idled")
+
+	require.NoError(t, c.SoftUnIdle(server.URL, "token", "john-jenkins", "jenkins"))
+	assert.Nil(t, patch["metadata"].(map[string]interface{})["annotations"].(map[string]interface{})[OriginalResourcesAnnotation],
+		"Annotation should be removed")
+	containers = patch["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"].([]interface{})
+	resources = containers[0].(map[string]interface{})["resources"].(map[string]interface{})
+	assert.Nil(t, resources["limits"].(map[string]interface{})["cpu"], "CPU limit should not be set")
+	assert.Equal(t, "1", resources["requests"].(map[string]interface{})["cpu"])
+}
@@ -10,7 +10,10 @@ import (
 	"sync"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/lease"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/toggles"
@@ -39,6 +42,7 @@ type Controller interface {
 type controllerImpl struct {
 	openshiftURL  string
 	osBearerToken string
+	metricsURL    string
 	userIdlers    *UserIdlerMap
 	tenantService tenant.Service
 	features      toggles.Features
@@ -47,26 +51,33 @@ type controllerImpl struct {
 	ctx           context.Context
 	cancel        context.CancelFunc
 	unknownUsers  *UnknownUsersMap
-	disabledUsers *model.StringSet
+	disabledUsers *disabled.Users
+	leases        *lease.Leases
+	bus           *events.Bus
+	statesLock    sync.Mutex
+	states        map[string]model.PodState
 }
 
 // NewController creates an instance of controllerImpl.
 func NewController(
 	ctx context.Context,
-	openshiftURL string, osBearerToken string,
+	openshiftURL string, osBearerToken string, metricsURL string,
 	userIdlers *UserIdlerMap,
 	t tenant.Service,
 	features toggles.Features,
 	config configuration.Configuration,
 	wg *sync.WaitGroup,
 	cancel context.CancelFunc,
-	disabledUsers *model.StringSet) Controller {
+	disabledUsers *disabled.Users,
+	leases *lease.Leases,
+	bus *events.Bus) Controller {
 
 	logger.WithField("cluster", openshiftURL).Info("Creating new controller instance")
 
 	controller := controllerImpl{
 		openshiftURL:  openshiftURL,
 		osBearerToken: osBearerToken,
+		metricsURL:    metricsURL,
 		userIdlers:    userIdlers,
 		tenantService: t,
 		features:      features,
@@ -76,6 +87,9 @@ func NewController(
 		cancel:        cancel,
 		unknownUsers:  NewUnknownUsersMap(),
 		disabledUsers: disabledUsers,
+		leases:        leases,
+		bus:           bus,
+		states:        make(map[string]model.PodState),
 	}
 
 	return &controller
@@ -116,38 +130,27 @@ func (c *controllerImpl) HandleBuild(o model.Object) error {
 	}
 	evalConditions := false
 
-	if c.isActive(&o.Object) {
-
-		lastActive := user.ActiveBuild
-		if lastActive.Status.Phase != o.Object.Status.Phase ||
-			lastActive.Metadata.Name != o.Object.Metadata.Name {
-
-			user.ActiveBuild = o.Object
+	if o.Type == "DELETED" {
+		// a deleted build never reaches a terminal phase, it must not keep Jenkins running
+		if user.RemoveBuild(o.Object.Metadata.Name) {
+			evalConditions = true
+			log.Infof("should evaluate conditions for %q due to deleted build, %d builds still active",
+				user.Name, len(user.ActiveBuilds))
+		}
+	} else if c.isActive(&o.Object) {
+		if user.StartBuild(o.Object) {
 			evalConditions = true
 			log.Infof("should evaluate conditions for %q due to active build", user.Name)
 		}
 	} else {
-
-		lastDone := user.DoneBuild
-		if lastDone.Status.Phase != o.Object.Status.Phase ||
-			lastDone.Metadata.Name != o.Object.Metadata.Name {
-
-			user.DoneBuild = o.Object
+		// a build which is done is no longer active, other builds of the user might still be running
+		if user.FinishBuild(o.Object) {
 			evalConditions = true
-			log.Infof("should evaluate conditions for %q due to completed build", user.Name)
+			log.Infof("should evaluate conditions for %q due to completed build, %d builds still active",
+				user.Name, len(user.ActiveBuilds))
 		}
 	}
 
-	// If we have same build name (space name + build number) in Active and Done
-	// it means last event was transition of an Active build into Done build
-	// So we need to clean up the Active build ref.
-	if user.ActiveBuild.Metadata.Name == user.DoneBuild.Metadata.Name {
-		log.Infof("Active and Done builds are the same (%s), cleaning active builds", user.ActiveBuild.Metadata.Name)
-		user.ActiveBuild = model.Build{Status: model.Status{Phase: "New"}}
-		evalConditions = true
-		log.Infof("should evaluate conditions for %q due to transition of active to  done build", user.Name)
-	}
-
 	if evalConditions {
 		log.Infof("Sending user %q to user-idler for evaluating conditions", user.Name)
 		sendUserToIdler(userIdler, user)
@@ -169,6 +172,12 @@ func (c *controllerImpl) HandleDeploymentConfig(dc model.DCObject) error {
 		"ns":        ns,
 	})
 
+	if dc.Type == "DELETED" {
+		log.Info("Jenkins deployment config deleted")
+		c.forgetState(dc.Object.Metadata.Namespace)
+		return nil
+	}
+
 	ok, err := c.createIfNotExist(ns)
 	if err != nil {
 		log.Errorf("Creating user-idler record failed: %s", err)
@@ -179,6 +188,8 @@ func (c *controllerImpl) HandleDeploymentConfig(dc model.DCObject) error {
 		return nil
 	}
 
+	c.publishStateChange(dc.Object.Metadata.Namespace, dc.Object.State())
+
 	// ensure user-idler is created for user so that pod would be
 	// idled/unidled even if there aren't any build events
 	userIdler := c.userIdlerForNamespace(ns)
@@ -194,10 +205,17 @@ func (c *controllerImpl) HandleDeploymentConfig(dc model.DCObject) error {
 		"name": user.Name,
 	})
 
+	annotationsChanged := user.PolicyAnnotations != dc.Object.Metadata.Annotations.PolicyAnnotations
+	user.PolicyAnnotations = dc.Object.Metadata.Annotations.PolicyAnnotations
+
 	availability, err := dc.Object.Status.GetByType(availableCond)
 	if err != nil {
 		// stop processing since the pod isn't available yet
 		log.Errorf("available condition not present in the list of conditions - %s", err)
+		if annotationsChanged {
+			log.Infof("evaluate conditions for %q due to changed annotations", user.Name)
+			sendUserToIdler(userIdler, user)
+		}
 		return nil
 	}
 
@@ -263,10 +281,11 @@ func (c *controllerImpl) createIfNotExist(ns string) (bool, error) {
 
 	log.Warnf("tenant info from tenant-service %v", ti)
 	user := model.NewUser(ti.Data[0].ID, ns)
+	user.Tier = ti.Data[0].Attributes.Tier
 
 	userIdler := idler.NewUserIdler(
-		user, c.openshiftURL, c.osBearerToken,
-		c.config, c.features, c.tenantService)
+		user, c.openshiftURL, c.osBearerToken, c.metricsURL,
+		c.config, c.features, c.tenantService, c.bus, c.leases)
 
 	c.userIdlers.Store(ns, userIdler)
 
@@ -303,3 +322,24 @@ func sendUserToIdler(idler *idler.UserIdler, user model.User) {
 			"Unable to send user to channel. Discarding event.")
 	}
 }
+
+// publishStateChange publishes a state change event if the state of Jenkins in the namespace differs
+// from the state seen by the last deployment config event.
+func (c *controllerImpl) publishStateChange(ns string, state model.PodState) {
+	c.statesLock.Lock()
+	previous, seen := c.states[ns]
+	c.states[ns] = state
+	c.statesLock.Unlock()
+
+	if seen && previous == state {
+		return
+	}
+	c.bus.Publish(events.Event{Type: events.StateChanged, Namespace: ns, Data: events.NewStateChange(previous, state)})
+}
+
+// forgetState forgets the state of Jenkins in the namespace, e.g. because the namespace has been removed.
+func (c *controllerImpl) forgetState(ns string) {
+	c.statesLock.Lock()
+	defer c.statesLock.Unlock()
+	delete(c.states, ns)
+}
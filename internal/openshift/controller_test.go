@@ -3,6 +3,8 @@ package openshift
 import (
 	"testing"
 
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/common"
 
 	"context"
@@ -11,6 +13,8 @@ import (
 	"net/http/httptest"
 	"sync"
 
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/events"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/idler"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
@@ -37,6 +41,10 @@ func (m *mockFeatureToggle) IsIdlerEnabled(uid string) (bool, error) {
 	return false, nil
 }
 
+func (m *mockFeatureToggle) Tier(uid string, tiers []string) (string, error) {
+	return "", nil
+}
+
 func Test_handle_build(t *testing.T) {
 	setUp(t)
 	defer tearDown()
@@ -54,6 +62,26 @@ func Test_handle_build(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func Test_deleted_build_is_no_longer_active(t *testing.T) {
+	setUp(t)
+	defer tearDown()
+
+	build := model.Build{Metadata: model.Metadata{Name: "foo-1", Namespace: "foo"}, Status: model.Status{Phase: "Running"}}
+	user := model.NewUser(testUserID, "foo")
+	user.StartBuild(build)
+	ci := controller.(*controllerImpl)
+	ci.userIdlers.Store("foo", idler.NewUserIdler(user, "", "", "", &mock.Config{}, &mockFeatureToggle{}, &mock.TenantService{}, nil, nil))
+
+	err := controller.HandleBuild(model.Object{Type: "DELETED", Object: build})
+	assert.NoError(t, err)
+
+	userIdler, _ := ci.userIdlers.Load("foo")
+	if assert.Len(t, userIdler.GetChannel(), 1, "Deleted build should be sent to the user idler") {
+		sent := <-userIdler.GetChannel()
+		assert.False(t, sent.HasActiveBuilds())
+	}
+}
+
 func TestHandleBuildChannelLength(t *testing.T) {
 	setUp(t)
 	defer tearDown()
@@ -219,8 +247,8 @@ func setUp(t *testing.T) {
 	defer cancel()
 
 	userIdlers := NewUserIdlerMap()
-	disabledUsers := model.NewStringSet()
-	controller = NewController(ctx, "", "", userIdlers, tenantService, features, &mock.Config{}, &wg, cancel, disabledUsers)
+	disabledUsers := disabled.NewUsers(store.NewMemoryStore())
+	controller = NewController(ctx, "", "", "", userIdlers, tenantService, features, &mock.Config{}, &wg, cancel, disabledUsers, nil, nil)
 }
 
 func emptyChannel(ch chan model.User) {
@@ -234,3 +262,32 @@ func tearDown() {
 	openShiftService.Close()
 	log.SetOutput(origWriter)
 }
+
+func Test_state_changes_are_published(t *testing.T) {
+	bus := events.NewBus()
+	published, unsubscribe := bus.Subscribe()
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	ci := NewController(ctx, "", "", "", NewUserIdlerMap(), &mock.TenantService{}, &mockFeatureToggle{}, &mock.Config{},
+		&wg, cancel, disabled.NewUsers(store.NewMemoryStore()), nil, bus).(*controllerImpl)
+
+	ci.publishStateChange("foo-jenkins", model.PodIdled)
+	ci.publishStateChange("foo-jenkins", model.PodIdled)
+	ci.publishStateChange("foo-jenkins", model.PodStarting)
+
+	assert.Len(t, published, 2, "Unchanged states should not be published")
+	for _, expected := range []events.StateChange{{From: "unknown", To: "idled"}, {From: "idled", To: "starting"}} {
+		event := <-published
+		assert.Equal(t, events.StateChanged, event.Type)
+		assert.Equal(t, "foo-jenkins", event.Namespace)
+		assert.Equal(t, expected, event.Data)
+	}
+
+	err := ci.HandleDeploymentConfig(model.DCObject{Type: "DELETED", Object: model.DeploymentConfig{Metadata: model.Metadata{Namespace: "foo-jenkins"}}})
+	assert.NoError(t, err)
+	assert.Empty(t, ci.states, "State of deleted Jenkins should be forgotten")
+	assert.Len(t, published, 0, "Deletion should not be published as state change")
+}
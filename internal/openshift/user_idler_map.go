@@ -40,3 +40,13 @@ func (m *UserIdlerMap) Len() int {
 func (m *UserIdlerMap) Store(namespace string, i *idler.UserIdler) {
 	m.internal.Set(namespace, i)
 }
+
+// Range calls f sequentially for each namespace and user idler present in the map.
+// If f returns false, Range stops the iteration.
+func (m *UserIdlerMap) Range(f func(namespace string, i *idler.UserIdler) bool) {
+	for item := range m.internal.IterBuffered() {
+		if !f(item.Key, item.Val.(*idler.UserIdler)) {
+			return
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package prewarm
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	week        = 7 * 24 * time.Hour
+	bucketCount = 7 * 24
+)
+
+// Histogram is a time-of-week histogram of activity. It keeps one bucket per hour of the week
+// (in UTC) and counts in how many weeks activity was observed within that hour.
+// Observations are expected to be roughly in chronological order.
+type Histogram struct {
+	sync.RWMutex
+	first    time.Time
+	counts   [bucketCount]int
+	lastWeek [bucketCount]int64
+}
+
+// NewHistogram creates an empty Histogram.
+func NewHistogram() *Histogram {
+	h := &Histogram{}
+	for i := range h.lastWeek {
+		h.lastWeek[i] = -1
+	}
+	return h
+}
+
+// Observe records activity at the given time. Multiple observations within
+// the same hour of the same week are counted once.
+func (h *Histogram) Observe(t time.Time) {
+	h.Lock()
+	defer h.Unlock()
+
+	if h.first.IsZero() || t.Before(h.first) {
+		h.first = t
+	}
+
+	b := bucket(t)
+	w := weekIndex(t)
+	if h.lastWeek[b] == w {
+		return
+	}
+	h.lastWeek[b] = w
+	h.counts[b]++
+}
+
+// Weeks returns the number of complete weeks of history at the given time.
+func (h *Histogram) Weeks(t time.Time) int {
+	h.RLock()
+	defer h.RUnlock()
+
+	if h.first.IsZero() || t.Before(h.first) {
+		return 0
+	}
+	return int(t.Sub(h.first) / week)
+}
+
+// Probability returns the fraction of the past weeks in which there was activity
+// in the same hour of the week as the given time.
+func (h *Histogram) Probability(t time.Time) float64 {
+	h.RLock()
+	defer h.RUnlock()
+
+	if h.first.IsZero() {
+		return 0
+	}
+
+	// number of times the hour of the week of t occurred since the first observation
+	occurrences := int(t.Truncate(time.Hour).Sub(h.first.Truncate(time.Hour)) / week)
+	if occurrences <= 0 {
+		return 0
+	}
+
+	p := float64(h.counts[bucket(t)]) / float64(occurrences)
+	if p > 1 {
+		// activity within the current hour is counted as well
+		p = 1
+	}
+	return p
+}
+
+func bucket(t time.Time) int {
+	u := t.UTC()
+	return int(u.Weekday())*24 + u.Hour()
+}
+
+func weekIndex(t time.Time) int64 {
+	return t.Unix() / int64(week/time.Second)
+}
@@ -0,0 +1,91 @@
+package prewarm
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/metric"
+)
+
+// Recorder to capture the outcome of predictions
+var Recorder metric.Recorder = metric.PrometheusRecorder{}
+
+// Predictor learns the activity pattern of a single namespace and predicts whether
+// there will be activity within a given window. Each prediction is checked against
+// the observed activity, predictions which are followed by activity within the window
+// are recorded as hits, the others as misses together with the time Jenkins was kept
+// warm in vain.
+type Predictor struct {
+	sync.Mutex
+	histogram *Histogram
+	window    time.Duration
+	threshold float64
+	minWeeks  int
+
+	// pending prediction, zero if there is none
+	predictedAt    time.Time
+	predictedUntil time.Time
+}
+
+// NewPredictor creates a Predictor which predicts activity within the given window if the
+// activity probability of any hour of the week overlapping the window reaches the threshold.
+// No activity is predicted as long as less than minWeeks of history have been observed.
+func NewPredictor(window time.Duration, threshold float64, minWeeks int) *Predictor {
+	return &Predictor{
+		histogram: NewHistogram(),
+		window:    window,
+		threshold: threshold,
+		minWeeks:  minWeeks,
+	}
+}
+
+// Observe records activity, e.g. the start of a build or an un-idle request, at the given time.
+func (p *Predictor) Observe(t time.Time) {
+	p.histogram.Observe(t)
+
+	p.Lock()
+	defer p.Unlock()
+
+	if p.predictedAt.IsZero() || t.Before(p.predictedAt) || t.After(p.predictedUntil) {
+		return
+	}
+	Recorder.RecordPrediction(true, 0)
+	p.predictedAt = time.Time{}
+	p.predictedUntil = time.Time{}
+}
+
+// Predict returns true if activity is likely within the window following now.
+func (p *Predictor) Predict(now time.Time) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.predictedAt.IsZero() && now.After(p.predictedUntil) {
+		Recorder.RecordPrediction(false, p.predictedUntil.Sub(p.predictedAt).Seconds())
+		p.predictedAt = time.Time{}
+		p.predictedUntil = time.Time{}
+	}
+
+	if !p.likely(now) {
+		return false
+	}
+
+	if p.predictedAt.IsZero() {
+		p.predictedAt = now
+	}
+	p.predictedUntil = now.Add(p.window)
+	return true
+}
+
+func (p *Predictor) likely(now time.Time) bool {
+	if p.histogram.Weeks(now) < p.minWeeks {
+		return false
+	}
+
+	end := now.Add(p.window)
+	for t := now.Truncate(time.Hour); !t.After(end); t = t.Add(time.Hour) {
+		if p.histogram.Probability(t) >= p.threshold {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package prewarm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/metric"
+	"github.com/stretchr/testify/assert"
+)
+
+type predictionRecorder struct {
+	hits   int
+	misses int
+	wasted float64
+}
+
+func (r *predictionRecorder) Initialize() {}
+
+func (r *predictionRecorder) RecordReqDuration(jenkinsService, operation string, code int, elapsedTime float64) {
+}
+
+func (r *predictionRecorder) RecordDecision(action string, dryRun bool) {}
+
+func (r *predictionRecorder) RecordPrediction(hit bool, wasted float64) {
+	if hit {
+		r.hits++
+		return
+	}
+	r.misses++
+	r.wasted += wasted
+}
+
+// monday 9:00 UTC
+var start = time.Date(2018, time.April, 2, 9, 0, 0, 0, time.UTC)
+
+func Test_histogram_counts_weeks_with_activity(t *testing.T) {
+	h := NewHistogram()
+	assert.Equal(t, 0.0, h.Probability(start), "empty histogram should not predict anything")
+
+	h.Observe(start)
+	h.Observe(start.Add(10 * time.Minute))
+	h.Observe(start.Add(week + 5*time.Minute))
+
+	now := start.Add(3*week - time.Hour)
+	assert.Equal(t, 2, h.Weeks(now))
+	assert.InDelta(t, 2.0/3.0, h.Probability(now.Add(time.Hour)), 0.001, "activity was observed in 2 out of 3 weeks")
+	assert.Equal(t, 0.0, h.Probability(now.Add(2*time.Hour)), "no activity at 10:00")
+}
+
+func Test_predictor_needs_min_weeks(t *testing.T) {
+	p := NewPredictor(30*time.Minute, 0.5, 2)
+	p.Observe(start)
+
+	assert.False(t, p.Predict(start.Add(2*week-15*time.Minute)), "one week of history is not enough")
+	assert.True(t, p.Predict(start.Add(2*week+time.Minute)), "history covers two weeks")
+}
+
+func Test_predictor_looks_ahead_window(t *testing.T) {
+	p := NewPredictor(30*time.Minute, 0.5, 1)
+	p.Observe(start)
+	p.Observe(start.Add(week))
+
+	monday := start.Add(2 * week)
+	assert.False(t, p.Predict(monday.Add(-45*time.Minute)), "activity is more than 30 minutes ahead")
+	assert.True(t, p.Predict(monday.Add(-20*time.Minute)), "activity is within 30 minutes")
+	assert.False(t, p.Predict(monday.Add(90*time.Minute)), "no activity expected")
+}
+
+func Test_predictor_records_hits_and_misses(t *testing.T) {
+	recorder := &predictionRecorder{}
+	defer func(r metric.Recorder) { Recorder = r }(Recorder)
+	Recorder = recorder
+
+	p := NewPredictor(30*time.Minute, 0.5, 1)
+	p.Observe(start)
+	p.Observe(start.Add(week))
+
+	// activity follows the prediction
+	monday := start.Add(2 * week)
+	assert.True(t, p.Predict(monday.Add(-20*time.Minute)))
+	assert.True(t, p.Predict(monday.Add(-5*time.Minute)))
+	p.Observe(monday.Add(5 * time.Minute))
+	assert.Equal(t, 1, recorder.hits)
+	assert.Equal(t, 0, recorder.misses)
+
+	// no activity the week after
+	monday = monday.Add(week)
+	assert.True(t, p.Predict(monday.Add(-20*time.Minute)))
+	assert.True(t, p.Predict(monday.Add(5*time.Minute)))
+	assert.False(t, p.Predict(monday.Add(3*time.Hour)))
+	assert.Equal(t, 1, recorder.hits)
+	assert.Equal(t, 1, recorder.misses)
+	assert.Equal(t, (55 * time.Minute).Seconds(), recorder.wasted, "jenkins was kept warm from 8:40 until 9:35")
+}
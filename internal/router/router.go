@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/api"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
 	"github.com/julienschmidt/httprouter"
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
@@ -82,33 +83,103 @@ func (r *Router) Shutdown() {
 	cancel()
 }
 
-// CreateAPIRouter a pointer to the http router for the idler APIs.
-func CreateAPIRouter(api api.IdlerAPI) *httprouter.Router {
+// CreateAPIRouter a pointer to the http router for the idler APIs, v1 under /api/idler and v2 under /api/v2.
+// If authenticator is not nil, every route requires a bearer token granting the scope of the route.
+func CreateAPIRouter(api api.IdlerAPI, v2 api.IdlerAPIV2, authenticator auth.Authenticator) *httprouter.Router {
 	router := httprouter.New()
 
-	router.GET("/api/idler/idle/:namespace", api.Idle)
-	router.GET("/api/idler/idle/:namespace/", api.Idle)
+	scoped := func(scope auth.Scope, handle httprouter.Handle) httprouter.Handle {
+		if authenticator == nil {
+			return handle
+		}
+		return auth.Authorize(authenticator, scope, handle)
+	}
+
+	router.GET("/api/idler/idle/:namespace", scoped(auth.ScopeOperate, api.Idle))
+	router.GET("/api/idler/idle/:namespace/", scoped(auth.ScopeOperate, api.Idle))
+
+	router.GET("/api/idler/unidle/:namespace", scoped(auth.ScopeOperate, api.UnIdle))
+	router.GET("/api/idler/unidle/:namespace/", scoped(auth.ScopeOperate, api.UnIdle))
+
+	router.GET("/api/idler/isidle/:namespace", scoped(auth.ScopeRead, api.IsIdle))
+	router.GET("/api/idler/isidle/:namespace/", scoped(auth.ScopeRead, api.IsIdle))
+
+	router.GET("/api/idler/status/:namespace", scoped(auth.ScopeRead, api.Status))
+	router.GET("/api/idler/status/:namespace/", scoped(auth.ScopeRead, api.Status))
+
+	router.GET("/api/idler/cluster", scoped(auth.ScopeRead, api.ClusterDNSView))
+	router.GET("/api/idler/cluster/", scoped(auth.ScopeRead, api.ClusterDNSView))
+
+	router.POST("/api/idler/reset/:namespace", scoped(auth.ScopeAdmin, api.Reset))
+	router.POST("/api/idler/reset/:namespace/", scoped(auth.ScopeAdmin, api.Reset))
+
+	router.GET("/api/idler/userstatus", scoped(auth.ScopeRead, api.GetDisabledUserIdlers))
+	router.GET("/api/idler/userstatus/", scoped(auth.ScopeRead, api.GetDisabledUserIdlers))
+
+	router.POST("/api/idler/userstatus", scoped(auth.ScopeAdmin, api.SetUserIdlerStatus))
+	router.POST("/api/idler/userstatus/", scoped(auth.ScopeAdmin, api.SetUserIdlerStatus))
+
+	router.GET("/api/idler/dryrun", scoped(auth.ScopeRead, api.DryRunReport))
+	router.GET("/api/idler/dryrun/", scoped(auth.ScopeRead, api.DryRunReport))
+
+	router.POST("/api/idler/keepalive/:namespace", scoped(auth.ScopeOperate, api.KeepAlive))
+	router.POST("/api/idler/keepalive/:namespace/", scoped(auth.ScopeOperate, api.KeepAlive))
+	router.DELETE("/api/idler/keepalive/:namespace", scoped(auth.ScopeOperate, api.Unpin))
+	router.DELETE("/api/idler/keepalive/:namespace/", scoped(auth.ScopeOperate, api.Unpin))
+
+	router.GET("/api/idler/leases", scoped(auth.ScopeRead, api.Leases))
+	router.GET("/api/idler/leases/", scoped(auth.ScopeRead, api.Leases))
+
+	router.GET("/api/idler/explain/:namespace", scoped(auth.ScopeRead, api.Explain))
+	router.GET("/api/idler/explain/:namespace/", scoped(auth.ScopeRead, api.Explain))
+
+	router.GET("/api/idler/namespaces", scoped(auth.ScopeRead, api.Namespaces))
+	router.GET("/api/idler/namespaces/", scoped(auth.ScopeRead, api.Namespaces))
+
+	router.POST("/api/idler/bulk", scoped(auth.ScopeAdmin, api.Bulk))
+	router.POST("/api/idler/bulk/", scoped(auth.ScopeAdmin, api.Bulk))
+	router.GET("/api/idler/bulk/:id", scoped(auth.ScopeRead, api.BulkJob))
+	router.GET("/api/idler/bulk/:id/", scoped(auth.ScopeRead, api.BulkJob))
+
+	router.GET("/api/idler/operations/:id", scoped(auth.ScopeRead, api.Operation))
+	router.GET("/api/idler/operations/:id/", scoped(auth.ScopeRead, api.Operation))
+
+	router.GET("/api/idler/events", scoped(auth.ScopeRead, api.Events))
+	router.GET("/api/idler/events/", scoped(auth.ScopeRead, api.Events))
+
+	router.POST("/api/idler/check/:namespace", scoped(auth.ScopeAdmin, api.Check))
+	router.POST("/api/idler/check/:namespace/", scoped(auth.ScopeAdmin, api.Check))
+
+	// self-service requests are authenticated with the token of the end user rather than the scopes above
+	router.POST("/api/idler/self/:action/:namespace", api.SelfService)
+	router.POST("/api/idler/self/:action/:namespace/", api.SelfService)
+
+	router.POST("/api/v2/jenkins/:namespace/idle", scoped(auth.ScopeOperate, v2.Idle))
+	router.POST("/api/v2/jenkins/:namespace/unidle", scoped(auth.ScopeOperate, v2.UnIdle))
+	router.POST("/api/v2/jenkins/:namespace/keepalive", scoped(auth.ScopeOperate, v2.KeepAlive))
+	router.POST("/api/v2/jenkins/:namespace/unpin", scoped(auth.ScopeOperate, v2.Unpin))
+	router.POST("/api/v2/jenkins/:namespace/reset", scoped(auth.ScopeAdmin, v2.Reset))
+	router.GET("/api/v2/jenkins/:namespace/status", scoped(auth.ScopeRead, v2.Status))
+	router.GET("/api/v2/jenkins/:namespace/explain", scoped(auth.ScopeRead, v2.Explain))
+	router.POST("/api/v2/jenkins/:namespace/check", scoped(auth.ScopeAdmin, v2.Check))
 
-	router.GET("/api/idler/unidle/:namespace", api.UnIdle)
-	router.GET("/api/idler/unidle/:namespace/", api.UnIdle)
+	router.GET("/api/v2/clusters", scoped(auth.ScopeRead, v2.Clusters))
+	router.GET("/api/v2/dryrun", scoped(auth.ScopeRead, v2.DryRunReport))
+	router.GET("/api/v2/leases", scoped(auth.ScopeRead, v2.Leases))
 
-	router.GET("/api/idler/isidle/:namespace", api.IsIdle)
-	router.GET("/api/idler/isidle/:namespace/", api.IsIdle)
+	router.GET("/api/v2/users/disabled", scoped(auth.ScopeRead, v2.DisabledUsers))
+	router.PATCH("/api/v2/users/disabled", scoped(auth.ScopeAdmin, v2.UpdateDisabledUsers))
 
-	router.GET("/api/idler/status/:namespace", api.Status)
-	router.GET("/api/idler/status/:namespace/", api.Status)
+	router.GET("/api/v2/namespaces", scoped(auth.ScopeRead, v2.Namespaces))
 
-	router.GET("/api/idler/cluster", api.ClusterDNSView)
-	router.GET("/api/idler/cluster/", api.ClusterDNSView)
+	router.POST("/api/v2/bulk", scoped(auth.ScopeAdmin, v2.Bulk))
+	router.GET("/api/v2/bulk/:id", scoped(auth.ScopeRead, v2.BulkJob))
 
-	router.POST("/api/idler/reset/:namespace", api.Reset)
-	router.POST("/api/idler/reset/:namespace/", api.Reset)
+	router.GET("/api/v2/events", scoped(auth.ScopeRead, v2.Events))
 
-	router.GET("/api/idler/userstatus", api.GetDisabledUserIdlers)
-	router.GET("/api/idler/userstatus/", api.GetDisabledUserIdlers)
+	router.GET("/api/v2/operations/:id", scoped(auth.ScopeRead, v2.Operation))
 
-	router.POST("/api/idler/userstatus", api.SetUserIdlerStatus)
-	router.POST("/api/idler/userstatus/", api.SetUserIdlerStatus)
+	router.POST("/api/v2/self/:namespace/:action", v2.SelfService)
 
 	return router
 }
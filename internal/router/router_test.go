@@ -14,9 +14,12 @@ import (
 	"net/http/httptest"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/api"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/auth"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/cluster"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/disabled"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/store"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
@@ -30,7 +33,7 @@ const (
 )
 
 func Test_all_routes_are_setup(t *testing.T) {
-	router := CreateAPIRouter(&mock.IdlerAPI{})
+	router := CreateAPIRouter(&mock.IdlerAPI{}, &mock.IdlerAPIV2{}, nil)
 
 	var routes = []struct {
 		route  string
@@ -48,6 +51,29 @@ func Test_all_routes_are_setup(t *testing.T) {
 		{"/api/idler/userstatus/", "SetUserIdlerStatus"},
 		{"/api/idler/userstatus", "GetDisabledUserIdlers"},
 		{"/api/idler/userstatus/", "GetDisabledUserIdlers"},
+		{"/api/idler/dryrun", "DryRunReport"},
+		{"/api/idler/dryrun/", "DryRunReport"},
+		{"/api/idler/keepalive/my-namepace", "KeepAlive"},
+		{"/api/idler/keepalive/my-namepace/", "KeepAlive"},
+		{"/api/idler/keepalive/my-namepace", "Unpin"},
+		{"/api/idler/keepalive/my-namepace/", "Unpin"},
+		{"/api/idler/leases", "Leases"},
+		{"/api/idler/leases/", "Leases"},
+		{"/api/idler/explain/my-namepace", "Explain"},
+		{"/api/idler/explain/my-namepace/", "Explain"},
+		{"/api/idler/namespaces", "Namespaces"},
+		{"/api/idler/namespaces/", "Namespaces"},
+		{"/api/idler/bulk", "Bulk"},
+		{"/api/idler/bulk/42", "BulkJob"},
+		{"/api/idler/bulk/42/", "BulkJob"},
+		{"/api/idler/operations/42", "Operation"},
+		{"/api/idler/operations/42/", "Operation"},
+		{"/api/idler/events", "Events"},
+		{"/api/idler/events/", "Events"},
+		{"/api/idler/check/my-namepace", "Check"},
+		{"/api/idler/check/my-namepace/", "Check"},
+		{"/api/idler/self/idle/my-namepace", "SelfService"},
+		{"/api/idler/self/pin/my-namepace/", "SelfService"},
 
 		{"/api/idler/foo", "404 page not found\n"},
 		{"/api/idler/builds/foo/bar", "404 page not found\n"},
@@ -55,10 +81,15 @@ func Test_all_routes_are_setup(t *testing.T) {
 
 	for _, testRoute := range routes {
 		w := new(mock.ResponseWriter)
-		if testRoute.target == "SetUserIdlerStatus" {
+		if testRoute.target == "SetUserIdlerStatus" || testRoute.target == "KeepAlive" || testRoute.target == "SelfService" || testRoute.target == "Check" || testRoute.route == "/api/idler/bulk" {
 			req, _ := http.NewRequest("POST", testRoute.route, nil)
 			router.ServeHTTP(w, req)
 
+			assert.Equal(t, testRoute.target, w.GetBody(), fmt.Sprintf("Routing failed for %s", testRoute.route))
+		} else if testRoute.target == "Unpin" {
+			req, _ := http.NewRequest("DELETE", testRoute.route, nil)
+			router.ServeHTTP(w, req)
+
 			assert.Equal(t, testRoute.target, w.GetBody(), fmt.Sprintf("Routing failed for %s", testRoute.route))
 		} else {
 			req, _ := http.NewRequest("GET", testRoute.route, nil)
@@ -69,6 +100,89 @@ func Test_all_routes_are_setup(t *testing.T) {
 	}
 }
 
+func Test_v2_routes_are_setup(t *testing.T) {
+	router := CreateAPIRouter(&mock.IdlerAPI{}, &mock.IdlerAPIV2{}, nil)
+
+	var routes = []struct {
+		method string
+		route  string
+		target string
+	}{
+		{"POST", "/api/v2/jenkins/my-namespace/idle", "v2.Idle"},
+		{"POST", "/api/v2/jenkins/my-namespace/unidle", "v2.UnIdle"},
+		{"POST", "/api/v2/jenkins/my-namespace/keepalive", "v2.KeepAlive"},
+		{"POST", "/api/v2/jenkins/my-namespace/unpin", "v2.Unpin"},
+		{"POST", "/api/v2/jenkins/my-namespace/reset", "v2.Reset"},
+		{"GET", "/api/v2/jenkins/my-namespace/status", "v2.Status"},
+		{"GET", "/api/v2/jenkins/my-namespace/explain", "v2.Explain"},
+		{"POST", "/api/v2/jenkins/my-namespace/check", "v2.Check"},
+		{"GET", "/api/v2/clusters", "v2.Clusters"},
+		{"GET", "/api/v2/dryrun", "v2.DryRunReport"},
+		{"GET", "/api/v2/leases", "v2.Leases"},
+		{"GET", "/api/v2/users/disabled", "v2.DisabledUsers"},
+		{"PATCH", "/api/v2/users/disabled", "v2.UpdateDisabledUsers"},
+		{"GET", "/api/v2/namespaces", "v2.Namespaces"},
+		{"POST", "/api/v2/bulk", "v2.Bulk"},
+		{"GET", "/api/v2/bulk/42", "v2.BulkJob"},
+		{"GET", "/api/v2/events", "v2.Events"},
+		{"GET", "/api/v2/operations/42", "v2.Operation"},
+		{"POST", "/api/v2/self/my-namespace/pin", "v2.SelfService"},
+		{"GET", "/api/v2/jenkins/my-namespace/idle", "Method Not Allowed\n"},
+	}
+
+	for _, testRoute := range routes {
+		w := new(mock.ResponseWriter)
+		req, _ := http.NewRequest(testRoute.method, testRoute.route, nil)
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, testRoute.target, w.GetBody(), fmt.Sprintf("Routing failed for %s %s", testRoute.method, testRoute.route))
+	}
+}
+
+func Test_routes_require_scope(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+
+	tokens := map[string]auth.Scope{"reader": auth.ScopeRead, "operator": auth.ScopeOperate, "admin": auth.ScopeAdmin}
+	router := CreateAPIRouter(&mock.IdlerAPI{}, &mock.IdlerAPIV2{}, auth.NewAuthenticator(nil, "", "", nil, tokens))
+
+	var tests = []struct {
+		method string
+		route  string
+		token  string
+		status int
+	}{
+		{"GET", "/api/idler/status/my-namespace", "", http.StatusUnauthorized},
+		{"GET", "/api/idler/status/my-namespace", "unknown", http.StatusUnauthorized},
+		{"GET", "/api/idler/status/my-namespace", "reader", http.StatusOK},
+		{"GET", "/api/idler/idle/my-namespace", "reader", http.StatusForbidden},
+		{"GET", "/api/idler/idle/my-namespace", "operator", http.StatusOK},
+		{"POST", "/api/idler/reset/my-namespace", "operator", http.StatusForbidden},
+		{"POST", "/api/idler/userstatus", "admin", http.StatusOK},
+		{"POST", "/api/idler/self/idle/my-namespace", "", http.StatusOK},
+		{"POST", "/api/v2/jenkins/my-namespace/idle", "reader", http.StatusForbidden},
+		{"POST", "/api/v2/jenkins/my-namespace/idle", "operator", http.StatusOK},
+		{"PATCH", "/api/v2/users/disabled", "operator", http.StatusForbidden},
+		{"POST", "/api/v2/self/my-namespace/idle", "", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.route, nil)
+		if test.token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, test.status, w.Code, "Unexpected status for %s %s with token %q", test.method, test.route, test.token)
+		if test.status != http.StatusOK {
+			body := map[string]string{}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+			assert.NotEmpty(t, body["error"], "Error should be reported as JSON")
+			assert.NotEmpty(t, body["description"], "Error should be described")
+		}
+	}
+}
+
 func Test_router_start(t *testing.T) {
 	testPort := testPortBase + 1
 	log.SetOutput(ioutil.Discard)
@@ -78,7 +192,7 @@ func Test_router_start(t *testing.T) {
 
 	assert.True(t, isTCPPortAvailable(testPort), fmt.Sprintf("Port '%d' should be free.", testPort))
 
-	router := NewRouterWithPort(CreateAPIRouter(&mock.IdlerAPI{}), testPort)
+	router := NewRouterWithPort(CreateAPIRouter(&mock.IdlerAPI{}, &mock.IdlerAPIV2{}, nil), testPort)
 
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
@@ -119,8 +233,8 @@ func Test_cluster_dns_view(t *testing.T) {
 	tenantService, cleanup := stubTenantService()
 	defer cleanup()
 
-	idlerAPI := api.NewIdlerAPI(openshift.NewUserIdlerMap(), clusterView, tenantService, model.NewStringSet())
-	router := NewRouterWithPort(CreateAPIRouter(idlerAPI), testPort)
+	idlerAPI := api.NewIdlerAPI(openshift.NewUserIdlerMap(), clusterView, tenantService, nil, disabled.NewUsers(store.NewMemoryStore()), &mock.Config{}, nil)
+	router := NewRouterWithPort(CreateAPIRouter(idlerAPI, &mock.IdlerAPIV2{}, nil), testPort)
 
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
@@ -169,8 +283,8 @@ func Test_openshift_url_parameter_is_used(t *testing.T) {
 
 	clusterView := cluster.NewView([]cluster.Cluster{dummyCluster})
 
-	idlerAPI := api.NewIdlerAPI(openshift.NewUserIdlerMap(), clusterView, tenantService, model.NewStringSet())
-	router := NewRouterWithPort(CreateAPIRouter(idlerAPI), testPort)
+	idlerAPI := api.NewIdlerAPI(openshift.NewUserIdlerMap(), clusterView, tenantService, nil, disabled.NewUsers(store.NewMemoryStore()), &mock.Config{}, nil)
+	router := NewRouterWithPort(CreateAPIRouter(idlerAPI, &mock.IdlerAPIV2{}, nil), testPort)
 
 	// start the router
 	var wg sync.WaitGroup
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// maxConflictRetries is how often an update is retried if another replica changed the store concurrently.
+const maxConflictRetries = 5
+
+var logger = logrus.WithField("component", "store")
+
+// ErrConflict is returned by Store.Save if the data has been changed since the given version was loaded.
+var ErrConflict = errors.New("data changed concurrently")
+
+// Store persists a JSON document, possibly shared by several idler replicas.
+type Store interface {
+	// Load returns the document, nil if nothing has been saved yet, together with its version.
+	Load() ([]byte, string, error)
+
+	// Save replaces the document unless it has been changed since the given version was loaded,
+	// in which case ErrConflict is returned.
+	Save(data []byte, version string) error
+}
+
+type memoryStore struct {
+	lock    sync.Mutex
+	data    []byte
+	version int
+}
+
+// NewMemoryStore creates a Store which keeps the document in memory. It is not shared between replicas
+// and the document is lost on restart.
+func NewMemoryStore() Store {
+	return &memoryStore{}
+}
+
+func (s *memoryStore) Load() ([]byte, string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]byte(nil), s.data...), strconv.Itoa(s.version), nil
+}
+
+func (s *memoryStore) Save(data []byte, version string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if version != strconv.Itoa(s.version) {
+		return ErrConflict
+	}
+	s.data = append([]byte(nil), data...)
+	s.version++
+	return nil
+}
+
+type configMapStore struct {
+	openShiftClient client.OpenShiftClient
+	apiURL          string
+	bearerToken     string
+	namespace       string
+	name            string
+	key             string
+}
+
+// NewConfigMapStore creates a Store which keeps the document under the given key of the given config map of the
+// given namespace, so that it is shared by all replicas and survives restarts. Concurrent changes are detected by
+// the resource version of the config map. The config map is created on the first save.
+func NewConfigMapStore(openShiftClient client.OpenShiftClient, apiURL string, bearerToken string, namespace string, name string, key string) Store {
+	return &configMapStore{
+		openShiftClient: openShiftClient,
+		apiURL:          apiURL,
+		bearerToken:     bearerToken,
+		namespace:       namespace,
+		name:            name,
+		key:             key,
+	}
+}
+
+func (s *configMapStore) Load() ([]byte, string, error) {
+	configMap, err := s.openShiftClient.ConfigMap(s.apiURL, s.bearerToken, s.namespace, s.name)
+	if err != nil || configMap == nil {
+		return nil, "", err
+	}
+
+	data, ok := configMap.Data[s.key]
+	if !ok {
+		return nil, configMap.ResourceVersion, nil
+	}
+	return []byte(data), configMap.ResourceVersion, nil
+}
+
+func (s *configMapStore) Save(data []byte, version string) error {
+	configMap := v1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: s.name, Namespace: s.namespace, ResourceVersion: version},
+		Data:       map[string]string{s.key: string(data)},
+	}
+	err := s.openShiftClient.SaveConfigMap(s.apiURL, s.bearerToken, s.namespace, configMap)
+	if err == client.ErrConflict {
+		return ErrConflict
+	}
+	return err
+}
+
+// Decode decodes the given document into v. An empty document leaves v unchanged.
+func Decode(data []byte, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Update loads the document of the store, passes it to change and saves the value returned by change as JSON.
+// The update is retried with the reloaded document if the store was changed concurrently.
+func Update(s Store, change func(data []byte) (interface{}, error)) error {
+	var err error
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		var data []byte
+		var version string
+		data, version, err = s.Load()
+		if err != nil {
+			return err
+		}
+
+		var value interface{}
+		if value, err = change(data); err != nil {
+			return err
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return err
+		}
+
+		err = s.Save(data, version)
+		if err == ErrConflict {
+			logger.Info("Store changed concurrently, retrying update")
+			continue
+		}
+		return err
+	}
+	return err
+}
+
+// Watch calls refresh in the given interval until the context is done, so that the changes of other replicas
+// are picked up. Errors are logged together with the given description of what is refreshed.
+func Watch(ctx context.Context, wg *sync.WaitGroup, interval time.Duration, refresh func() error, what string) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := refresh(); err != nil {
+					logger.Errorf("Failed to refresh %s: %s", what, err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,89 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/testutils/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conflictingStore is a Store which reports a conflict on the first saves, as if another replica changed it.
+type conflictingStore struct {
+	Store
+	conflicts int
+}
+
+func (s *conflictingStore) Save(data []byte, version string) error {
+	if s.conflicts > 0 {
+		s.conflicts--
+		_, version, _ := s.Store.Load()
+		s.Store.Save([]byte(`["carol"]`), version)
+		return ErrConflict
+	}
+	return s.Store.Save(data, version)
+}
+
+// appendName returns a change for Update which appends the given name to the stored list of names.
+func appendName(name string) func(data []byte) (interface{}, error) {
+	return func(data []byte) (interface{}, error) {
+		names := []string{}
+		if err := Decode(data, &names); err != nil {
+			return nil, err
+		}
+		return append(names, name), nil
+	}
+}
+
+func Test_config_map_store(t *testing.T) {
+	openShiftClient := &mock.OpenShiftClient{}
+	store := NewConfigMapStore(openShiftClient, "https://api.free-stg.openshift.com", "token", "dsaas", "idler", "names.json")
+
+	data, version, err := store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, data, "Missing config map should have no data")
+
+	require.NoError(t, store.Save([]byte(`["alice"]`), version))
+	assert.Equal(t, `["alice"]`, openShiftClient.ConfigMaps["idler"].Data["names.json"])
+
+	data, newVersion, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, `["alice"]`, string(data))
+	assert.NotEqual(t, version, newVersion)
+
+	err = store.Save([]byte(`[]`), version)
+	assert.Equal(t, ErrConflict, err, "Save with outdated version should conflict")
+}
+
+func Test_update_shared_by_config_map(t *testing.T) {
+	openShiftClient := &mock.OpenShiftClient{}
+	replica1 := NewConfigMapStore(openShiftClient, "", "", "dsaas", "idler", "names.json")
+	replica2 := NewConfigMapStore(openShiftClient, "", "", "dsaas", "idler", "names.json")
+
+	require.NoError(t, Update(replica1, appendName("alice")))
+	require.NoError(t, Update(replica2, appendName("bob")))
+
+	data, _, err := replica1.Load()
+	require.NoError(t, err)
+	assert.Equal(t, `["alice","bob"]`, string(data))
+}
+
+func Test_update_retries_on_conflict(t *testing.T) {
+	store := &conflictingStore{Store: NewMemoryStore(), conflicts: 1}
+
+	require.NoError(t, Update(store, appendName("alice")))
+	data, _, _ := store.Load()
+	assert.Equal(t, `["carol","alice"]`, string(data), "Concurrent change should be kept")
+
+	store.conflicts = maxConflictRetries
+	err := Update(store, appendName("bob"))
+	assert.Equal(t, ErrConflict, err, "Update should give up after too many conflicts")
+}
+
+func Test_decode_of_empty_document_keeps_value(t *testing.T) {
+	names := []string{"alice"}
+	require.NoError(t, Decode(nil, &names))
+	assert.Equal(t, []string{"alice"}, names)
+
+	assert.Error(t, Decode([]byte("{"), &names))
+}
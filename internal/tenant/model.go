@@ -35,6 +35,8 @@ type Attributes struct {
 	CreatedAt  time.Time `json:"created-at"`
 	Email      string
 	Namespaces []Namespace
+	// Tier is the name of the idle policy tier the tenant is assigned to, if any.
+	Tier string `json:"tier"`
 }
 
 // Namespace of the build.
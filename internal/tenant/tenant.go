@@ -5,14 +5,19 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
 )
 
+// requestTimeout bounds how long a request to the tenant service may take.
+const requestTimeout = 10 * time.Second
+
 // Service the interface for the cluster service
 type Service interface {
 	GetTenantInfoByNamespace(apiURL string, ns string) (InfoList, error)
 	HasReachedMaxCapacity(apiURL, ns string) (bool, error)
+	GetTenantInfoByToken(userToken string) (Info, error)
 }
 
 // Tenant is a simple client for the fabric8-tenant service.
@@ -20,6 +25,7 @@ type Service interface {
 type tenantService struct {
 	tenantServiceURL string
 	authToken        string
+	client           *http.Client
 }
 
 // NewTenantService returns an instance implementing Service.
@@ -27,6 +33,7 @@ func NewTenantService(tenantServiceURL string, authToken string) Service {
 	return &tenantService{
 		authToken:        authToken,
 		tenantServiceURL: tenantServiceURL,
+		client:           &http.Client{Timeout: requestTimeout},
 	}
 }
 
@@ -43,8 +50,7 @@ func (t tenantService) GetTenantInfoByNamespace(apiURL string, ns string) (InfoL
 	q.Add("namespace", ns)
 	req.URL.RawQuery = q.Encode()
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := t.client.Do(req)
 	if err != nil {
 		return InfoList{}, err
 	}
@@ -64,6 +70,43 @@ func (t tenantService) GetTenantInfoByNamespace(apiURL string, ns string) (InfoL
 	return tenantInfo, nil
 }
 
+// GetTenantInfoByToken gets you the Info of the tenant the given user token belongs to. The request is made with
+// the user's token, so the tenant service only ever returns the caller's own tenant.
+func (t tenantService) GetTenantInfoByToken(userToken string) (Info, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/tenant", t.tenantServiceURL), nil)
+	if err != nil {
+		return Info{}, err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", userToken))
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return Info{}, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Info{}, err
+	}
+
+	tenantInfo := Info{}
+	err = json.Unmarshal(body, &tenantInfo)
+	if err != nil {
+		return Info{}, err
+	}
+
+	if len(tenantInfo.Errors) != 0 {
+		firstError := tenantInfo.Errors[0]
+		return Info{}, fmt.Errorf("%s - %s", firstError.Code, firstError.Detail)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Info{}, fmt.Errorf("got status %s from tenant service", resp.Status)
+	}
+
+	return tenantInfo, nil
+}
+
 // returns true if the cluster the ns is on has reached maximum capacity
 func (t tenantService) HasReachedMaxCapacity(apiURL, ns string) (bool, error) {
 
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 )
@@ -13,3 +14,40 @@ func MockServer(b []byte) *httptest.Server {
 		w.Header().Set("Content-Type", "application/json")
 	}))
 }
+
+// FakeJenkins is a minimal Jenkins serving the queue and computer REST APIs.
+type FakeJenkins struct {
+	*httptest.Server
+	// QueuedItems is the number of items in the build queue.
+	QueuedItems int
+	// BusyExecutors is the number of busy executors.
+	BusyExecutors int
+	// Token is the bearer token requests need to be authorized with.
+	Token string
+}
+
+// NewFakeJenkins starts a FakeJenkins which accepts requests authorized with the given bearer token.
+func NewFakeJenkins(token string) *FakeJenkins {
+	j := &FakeJenkins{Token: token}
+	j.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer "+j.Token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case "/queue/api/json":
+			items := make([]map[string]int, j.QueuedItems)
+			for i := range items {
+				items[i] = map[string]int{"id": i + 1}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+		case "/computer/api/json":
+			json.NewEncoder(w).Encode(map[string]int{"busyExecutors": j.BusyExecutors})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return j
+}
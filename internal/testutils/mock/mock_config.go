@@ -1,26 +1,77 @@
 package mock
 
 import (
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/configuration"
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/util"
 )
 
 // Config a mock implementation of the configuration.Configuration interface.
 // It can be used in tests where any field can be explicitly set to return the needed value.
 type Config struct {
-	ProxyURL              string
-	TenantURL             string
-	ToggleURL             string
-	IdleAfter             int
-	IdleLongBuild         int
-	MaxRetries            int
-	MaxRetriesQuietPeriod int
-	CheckInterval         int
-	Debug                 bool
-	FixedUuids            []string
-	AuthURL               string
-	ServiceAccountID      string
-	ServiceAccountSecret  string
-	AuthTokenKey          string
+	ProxyURL                string
+	TenantURL               string
+	ToggleURL               string
+	IdleAfter               int
+	IdleLongBuild           int
+	MaxRetries              int
+	MaxRetriesQuietPeriod   int
+	CheckInterval           int
+	Debug                   bool
+	FixedUuids              []string
+	DryRun                  bool
+	DryRunUsers             []string
+	Schedules               []configuration.Schedule
+	Teams                   map[string][]string
+	Prewarm                 bool
+	PrewarmWindow           int
+	PrewarmThreshold        float64
+	PrewarmMinWeeks         int
+	PreIdleWebhook          string
+	PreIdleGracePeriod      int
+	SoftIdle                bool
+	SoftIdleUsers           []string
+	SoftIdleCPU             string
+	SoftIdleMemory          string
+	Tiers                   []configuration.Tier
+	DefaultTier             string
+	UserCondition           bool
+	UserConditionIdleAfter  int
+	ProxyTimeout            int
+	ProxyRetries            int
+	ProxyUnavailable        string
+	Combination             configuration.Combination
+	JenkinsCondition        bool
+	JenkinsTimeout          int
+	MetricsCondition        bool
+	MetricsWindow           int
+	MetricsCPUThreshold     float64
+	MetricsNetworkThreshold float64
+	InputWaitTimeout        int
+	InputWaitAction         string
+	StalledStageTimeout     int
+	StalledBuildAction      string
+	DecisionWebhook         string
+	DecisionWebhookTimeout  int
+	DecisionWebhookCache    int
+	DecisionWebhookFailure  string
+	APIAuth                 bool
+	APIServiceAccounts      []string
+	APITokens               []string
+	APITokenIssuer          string
+	APITokenAudience        string
+	BulkConcurrency         int
+	UnIdleTimeout           int
+	MaxKeepAlive            int
+	DisabledUsersConfigMap  string
+	DisabledUsersCluster    string
+	DisabledUsersRefresh    int
+	LeasesConfigMap         string
+	LeasesCluster           string
+	LeasesRefresh           int
+	AuthURL                 string
+	ServiceAccountID        string
+	ServiceAccountSecret    string
+	AuthTokenKey            string
 }
 
 // GetProxyURL returns the Jenkins Proxy API URL.
@@ -79,6 +130,257 @@ func (c *Config) GetFixedUuids() []string {
 	return c.FixedUuids
 }
 
+// GetDryRun returns true if idling decisions should only be recorded, but never executed.
+func (c *Config) GetDryRun() bool {
+	return c.DryRun
+}
+
+// GetDryRunUsers returns a slice of user names for which idling decisions are only recorded.
+func (c *Config) GetDryRunUsers() []string {
+	return c.DryRunUsers
+}
+
+// GetSchedules returns the time based schedules which override the activity based idling decisions.
+func (c *Config) GetSchedules() []configuration.Schedule {
+	return c.Schedules
+}
+
+// GetTeams returns a map of team names to the names of the users belonging to the team.
+func (c *Config) GetTeams() map[string][]string {
+	return c.Teams
+}
+
+// GetPrewarm returns true if Jenkins should be un-idled ahead of time when activity is predicted.
+func (c *Config) GetPrewarm() bool {
+	return c.Prewarm
+}
+
+// GetPrewarmWindow returns the number of minutes ahead of predicted activity Jenkins is un-idled.
+func (c *Config) GetPrewarmWindow() int {
+	return c.PrewarmWindow
+}
+
+// GetPrewarmThreshold returns the probability of activity above which Jenkins is pre-warmed.
+func (c *Config) GetPrewarmThreshold() float64 {
+	return c.PrewarmThreshold
+}
+
+// GetPrewarmMinWeeks returns the number of weeks of activity history needed before any activity is predicted.
+func (c *Config) GetPrewarmMinWeeks() int {
+	return c.PrewarmMinWeeks
+}
+
+// GetPreIdleWebhook returns the URL notifications are posted to before Jenkins is idled.
+func (c *Config) GetPreIdleWebhook() string {
+	return c.PreIdleWebhook
+}
+
+// GetPreIdleGracePeriod returns the number of minutes between the pre-idle notification and the actual idling.
+func (c *Config) GetPreIdleGracePeriod() int {
+	return c.PreIdleGracePeriod
+}
+
+// GetSoftIdle returns true if Jenkins should be soft-idled instead of being scaled down.
+func (c *Config) GetSoftIdle() bool {
+	return c.SoftIdle
+}
+
+// GetSoftIdleUsers returns a slice of user names for which Jenkins is soft-idled.
+func (c *Config) GetSoftIdleUsers() []string {
+	return c.SoftIdleUsers
+}
+
+// GetSoftIdleCPU returns the CPU request and limit of a soft-idled Jenkins.
+func (c *Config) GetSoftIdleCPU() string {
+	return c.SoftIdleCPU
+}
+
+// GetSoftIdleMemory returns the memory request and limit of a soft-idled Jenkins.
+func (c *Config) GetSoftIdleMemory() string {
+	return c.SoftIdleMemory
+}
+
+// GetTiers returns the named idle policies users can be assigned to.
+func (c *Config) GetTiers() []configuration.Tier {
+	return c.Tiers
+}
+
+// GetDefaultTier returns the name of the tier of users which are not assigned to any tier.
+func (c *Config) GetDefaultTier() string {
+	return c.DefaultTier
+}
+
+// GetUserCondition returns true if the activity reported by the Jenkins Proxy is taken into account.
+func (c *Config) GetUserCondition() bool {
+	return c.UserCondition
+}
+
+// GetUserConditionIdleAfter returns the number of minutes after the last activity reported by the
+// Jenkins Proxy before Jenkins is idled.
+func (c *Config) GetUserConditionIdleAfter() int {
+	return c.UserConditionIdleAfter
+}
+
+// GetProxyTimeout returns the number of seconds after which a request to the Jenkins Proxy times out.
+func (c *Config) GetProxyTimeout() int {
+	return c.ProxyTimeout
+}
+
+// GetProxyRetries returns the number of times a failed request to the Jenkins Proxy is retried.
+func (c *Config) GetProxyRetries() int {
+	return c.ProxyRetries
+}
+
+// GetProxyUnavailable returns how an unavailable Jenkins Proxy is treated, either "ignore" or "veto".
+func (c *Config) GetProxyUnavailable() string {
+	return c.ProxyUnavailable
+}
+
+// GetJenkinsCondition returns true if queued jobs and busy executors keep Jenkins from being idled.
+func (c *Config) GetJenkinsCondition() bool {
+	return c.JenkinsCondition
+}
+
+// GetJenkinsTimeout returns the number of seconds after which a request to the Jenkins REST API times out.
+func (c *Config) GetJenkinsTimeout() int {
+	return c.JenkinsTimeout
+}
+
+// GetMetricsCondition returns true if sustained resource usage of the Jenkins pod keeps Jenkins from being idled.
+func (c *Config) GetMetricsCondition() bool {
+	return c.MetricsCondition
+}
+
+// GetMetricsWindow returns the number of minutes the resource usage needs to be above the thresholds.
+func (c *Config) GetMetricsWindow() int {
+	return c.MetricsWindow
+}
+
+// GetMetricsCPUThreshold returns the CPU usage in millicores above which Jenkins is considered active.
+func (c *Config) GetMetricsCPUThreshold() float64 {
+	return c.MetricsCPUThreshold
+}
+
+// GetMetricsNetworkThreshold returns the network traffic in bytes per second above which Jenkins is considered active.
+func (c *Config) GetMetricsNetworkThreshold() float64 {
+	return c.MetricsNetworkThreshold
+}
+
+// GetInputWaitTimeout returns the number of minutes a build may wait on an input step.
+func (c *Config) GetInputWaitTimeout() int {
+	return c.InputWaitTimeout
+}
+
+// GetInputWaitAction returns what happens to builds waiting on an input step for too long.
+func (c *Config) GetInputWaitAction() string {
+	return c.InputWaitAction
+}
+
+// GetStalledStageTimeout returns the number of minutes a build may run the same stage.
+func (c *Config) GetStalledStageTimeout() int {
+	return c.StalledStageTimeout
+}
+
+// GetStalledBuildAction returns what happens to stalled builds.
+func (c *Config) GetStalledBuildAction() string {
+	return c.StalledBuildAction
+}
+
+// GetDecisionWebhook returns the URL of an external endpoint deciding about idling.
+func (c *Config) GetDecisionWebhook() string {
+	return c.DecisionWebhook
+}
+
+// GetDecisionWebhookTimeout returns the timeout in seconds for requests to the decision webhook.
+func (c *Config) GetDecisionWebhookTimeout() int {
+	return c.DecisionWebhookTimeout
+}
+
+// GetDecisionWebhookCache returns the number of seconds a decision of the webhook is cached.
+func (c *Config) GetDecisionWebhookCache() int {
+	return c.DecisionWebhookCache
+}
+
+// GetDecisionWebhookFailure returns the action taken if the decision webhook fails.
+func (c *Config) GetDecisionWebhookFailure() string {
+	return c.DecisionWebhookFailure
+}
+
+// GetAPIAuth returns true if the REST API requires bearer tokens.
+func (c *Config) GetAPIAuth() bool {
+	return c.APIAuth
+}
+
+// GetAPIServiceAccounts returns the service accounts allowed to use the REST API, as name:scope.
+func (c *Config) GetAPIServiceAccounts() []string {
+	return c.APIServiceAccounts
+}
+
+// GetAPITokens returns static tokens allowed to use the REST API, as token:scope.
+func (c *Config) GetAPITokens() []string {
+	return c.APITokens
+}
+
+// GetAPITokenIssuer returns the issuer service account tokens need to be issued by.
+func (c *Config) GetAPITokenIssuer() string {
+	return c.APITokenIssuer
+}
+
+// GetAPITokenAudience returns the audience service account tokens need to be issued for.
+func (c *Config) GetAPITokenAudience() string {
+	return c.APITokenAudience
+}
+
+// GetBulkConcurrency returns how many Jenkins instances per cluster a bulk operation handles concurrently.
+func (c *Config) GetBulkConcurrency() int {
+	return c.BulkConcurrency
+}
+
+// GetUnIdleTimeout returns the time in minutes Jenkins may take to become ready after an un-idle request.
+func (c *Config) GetUnIdleTimeout() int {
+	return c.UnIdleTimeout
+}
+
+// GetMaxKeepAlive returns the maximal duration in minutes of a keep-alive lease.
+func (c *Config) GetMaxKeepAlive() int {
+	return c.MaxKeepAlive
+}
+
+// GetDisabledUsersConfigMap returns the config map the disabled users are persisted to as namespace/name.
+func (c *Config) GetDisabledUsersConfigMap() string {
+	return c.DisabledUsersConfigMap
+}
+
+// GetDisabledUsersCluster returns the API URL of the OpenShift cluster of the disabled users config map.
+func (c *Config) GetDisabledUsersCluster() string {
+	return c.DisabledUsersCluster
+}
+
+// GetDisabledUsersRefresh returns the interval in seconds in which the disabled users are reloaded.
+func (c *Config) GetDisabledUsersRefresh() int {
+	return c.DisabledUsersRefresh
+}
+
+// GetLeasesConfigMap returns the config map the keep-alive leases are persisted to as namespace/name.
+func (c *Config) GetLeasesConfigMap() string {
+	return c.LeasesConfigMap
+}
+
+// GetLeasesCluster returns the API URL of the OpenShift cluster of the leases config map.
+func (c *Config) GetLeasesCluster() string {
+	return c.LeasesCluster
+}
+
+// GetLeasesRefresh returns the interval in seconds in which the keep-alive leases are reloaded.
+func (c *Config) GetLeasesRefresh() int {
+	return c.LeasesRefresh
+}
+
+// GetCombination returns how the results of the idle conditions are combined.
+func (c *Config) GetCombination() configuration.Combination {
+	return c.Combination
+}
+
 // GetServiceAccountID returns the service account id for the Auth service. Used to identify the Idler to the Auth service
 func (c *Config) GetServiceAccountID() string {
 	return c.ServiceAccountID
@@ -7,6 +7,7 @@ import (
 
 type featureToggle struct {
 	uuids []string
+	tiers map[string]string
 }
 
 // NewMockFeatureToggle returns a new instance of featureToggle(toggles.Features).
@@ -14,6 +15,19 @@ func NewMockFeatureToggle(validIds []string) toggles.Features {
 	return &featureToggle{uuids: validIds}
 }
 
+// NewMockFeatureToggleWithTiers returns a new instance of featureToggle(toggles.Features)
+// which enables the tiers specified by user id.
+func NewMockFeatureToggleWithTiers(validIds []string, tiers map[string]string) toggles.Features {
+	return &featureToggle{uuids: validIds, tiers: tiers}
+}
+
 func (m *featureToggle) IsIdlerEnabled(uid string) (bool, error) {
 	return util.Contains(m.uuids, uid), nil
 }
+
+func (m *featureToggle) Tier(uid string, tiers []string) (string, error) {
+	if tier, ok := m.tiers[uid]; ok && util.Contains(tiers, tier) {
+		return tier, nil
+	}
+	return "", nil
+}
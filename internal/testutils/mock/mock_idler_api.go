@@ -70,3 +70,75 @@ func (i *IdlerAPI) GetDisabledUserIdlers(w http.ResponseWriter, r *http.Request,
 	}
 	w.WriteHeader(http.StatusOK)
 }
+
+// DryRunReport mocks the report of the dry-run decisions
+func (i *IdlerAPI) DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("DryRunReport"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// KeepAlive mocks the cancellation of a pending idle
+func (i *IdlerAPI) KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("KeepAlive"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Explain mocks the explanation of the most recent idling decision
+func (i *IdlerAPI) Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Explain"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// SelfService lets end users idle, un-idle, reset or pin their own Jenkins.
+func (i *IdlerAPI) SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("SelfService"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Namespaces mocks the list of tracked namespaces
+func (i *IdlerAPI) Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Namespaces"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Bulk mocks starting a bulk job
+func (i *IdlerAPI) Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Bulk"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// BulkJob mocks the report of a bulk job
+func (i *IdlerAPI) BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("BulkJob"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Events mocks the stream of idler events
+func (i *IdlerAPI) Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Events"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Operation mocks the report of an un-idle operation
+func (i *IdlerAPI) Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Operation"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unpin mocks revoking a keep-alive lease
+func (i *IdlerAPI) Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Unpin"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Leases mocks the list of keep-alive leases
+func (i *IdlerAPI) Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Leases"))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Check mocks a forced idle check
+func (i *IdlerAPI) Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	w.Write([]byte("Check"))
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,112 @@
+package mock
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// IdlerAPIV2 defines version 2 of the REST endpoints of the Idler.
+// This is the mock interface, each endpoint writes its name prefixed with "v2." as response.
+type IdlerAPIV2 struct {
+}
+
+func (i *IdlerAPIV2) write(w http.ResponseWriter, name string) {
+	w.Write([]byte("v2." + name))
+	w.WriteHeader(http.StatusOK)
+}
+
+// Idle mocks idling Jenkins
+func (i *IdlerAPIV2) Idle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Idle")
+}
+
+// UnIdle mocks un-idling Jenkins
+func (i *IdlerAPIV2) UnIdle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "UnIdle")
+}
+
+// Reset mocks resetting Jenkins
+func (i *IdlerAPIV2) Reset(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Reset")
+}
+
+// KeepAlive mocks the cancellation of a pending idle
+func (i *IdlerAPIV2) KeepAlive(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "KeepAlive")
+}
+
+// Unpin mocks the revocation of a keep-alive lease
+func (i *IdlerAPIV2) Unpin(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Unpin")
+}
+
+// Leases mocks the list of keep-alive leases
+func (i *IdlerAPIV2) Leases(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Leases")
+}
+
+// Status mocks the state of Jenkins
+func (i *IdlerAPIV2) Status(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Status")
+}
+
+// Check mocks a forced idle check
+func (i *IdlerAPIV2) Check(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Check")
+}
+
+// Explain mocks the explanation of the most recent idling decision
+func (i *IdlerAPIV2) Explain(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Explain")
+}
+
+// Clusters mocks the view of the clusters
+func (i *IdlerAPIV2) Clusters(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Clusters")
+}
+
+// DisabledUsers mocks the list of users idling is disabled for
+func (i *IdlerAPIV2) DisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "DisabledUsers")
+}
+
+// UpdateDisabledUsers mocks disabling resp. enabling idling for users
+func (i *IdlerAPIV2) UpdateDisabledUsers(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "UpdateDisabledUsers")
+}
+
+// DryRunReport mocks the report of the dry-run decisions
+func (i *IdlerAPIV2) DryRunReport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "DryRunReport")
+}
+
+// SelfService mocks the self-service of end users
+func (i *IdlerAPIV2) SelfService(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "SelfService")
+}
+
+// Namespaces mocks listing the tracked namespaces
+func (i *IdlerAPIV2) Namespaces(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Namespaces")
+}
+
+// Bulk mocks starting a bulk job
+func (i *IdlerAPIV2) Bulk(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Bulk")
+}
+
+// BulkJob mocks reporting a bulk job
+func (i *IdlerAPIV2) BulkJob(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "BulkJob")
+}
+
+// Events mocks streaming events
+func (i *IdlerAPIV2) Events(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Events")
+}
+
+// Operation mocks the report of an un-idle operation
+func (i *IdlerAPIV2) Operation(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	i.write(w, "Operation")
+}
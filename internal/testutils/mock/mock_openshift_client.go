@@ -2,8 +2,11 @@ package mock
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/fabric8-services/fabric8-jenkins-idler/internal/model"
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/openshift/client"
+	"k8s.io/api/core/v1"
 )
 
 // OpenShiftClient is a client for OpenShift API
@@ -13,6 +16,14 @@ type OpenShiftClient struct {
 	IdleCallCount   int
 	UnIdleCallCount int
 	IdleError       string
+	SoftIdledState  bool
+	ProjectPolicy   model.PolicyAnnotations
+	Events          []v1.Event
+	JenkinsURL      string
+	CancelledBuilds []string
+	ConfigMaps      map[string]v1.ConfigMap
+	BuildList       model.BuildList
+	RolloutFailure  string
 }
 
 // Idle mocks Idle method of client.OpenShiftClient.
@@ -43,6 +54,42 @@ func (c *OpenShiftClient) State(apiURL string, bearerToken string, namespace str
 	return c.IdleState, nil
 }
 
+// DeploymentConfig mocks DeploymentConfig method of client.OpenShiftClient.
+// Its status reflects IdleState and it carries the original resources annotation if SoftIdledState is set
+// resp. a failed Progressing condition with RolloutFailure as message if that is set.
+func (c *OpenShiftClient) DeploymentConfig(apiURL string, bearerToken string, namespace string, service string) (*model.DeploymentConfig, error) {
+	if c.IdleError != "" {
+		return nil, fmt.Errorf(c.IdleError)
+	}
+
+	dc := &model.DeploymentConfig{Metadata: model.Metadata{Name: service, Namespace: namespace}}
+	switch c.IdleState {
+	case model.PodStarting:
+		dc.Status.Replicas = 1
+	case model.PodRunning:
+		dc.Status.Replicas = 1
+		dc.Status.ReadyReplicas = 1
+	}
+	if c.SoftIdledState {
+		dc.Metadata.Annotations.OriginalResources = "{}"
+	}
+	if c.RolloutFailure != "" {
+		dc.Status.Conditions = append(dc.Status.Conditions, model.Condition{
+			Type: "Progressing", Status: "False", Reason: "ProgressDeadlineExceeded", Message: c.RolloutFailure,
+		})
+	}
+	return dc, nil
+}
+
+// Builds mocks Builds method of client.OpenShiftClient.
+// It returns BuildList.
+func (c *OpenShiftClient) Builds(apiURL string, bearerToken string, namespace string) (model.BuildList, error) {
+	if c.IdleError != "" {
+		return model.BuildList{}, fmt.Errorf(c.IdleError)
+	}
+	return c.BuildList, nil
+}
+
 // Reset deletes a pod and start a new one
 func (c *OpenShiftClient) Reset(apiURL string, bearerToken string, namespace string) error {
 	if c.IdleError != "" {
@@ -77,6 +124,55 @@ func (c *OpenShiftClient) WatchDeploymentConfigs(apiURL string, bearerToken stri
 	return nil
 }
 
+// SoftIdle mocks SoftIdle method of client.OpenShiftClient.
+// It increases IdleCallCount by 1 and marks the service as soft-idled.
+func (c *OpenShiftClient) SoftIdle(apiURL string, bearerToken string, namespace string, service string, resources v1.ResourceRequirements) error {
+	c.IdleCallCount++
+	if c.IdleError != "" {
+		return fmt.Errorf(c.IdleError)
+	}
+	c.SoftIdledState = true
+	return nil
+}
+
+// SoftUnIdle mocks SoftUnIdle method of client.OpenShiftClient.
+// If the service is marked as soft-idled, it increases UnIdleCallCount by 1 and clears the mark.
+func (c *OpenShiftClient) SoftUnIdle(apiURL string, bearerToken string, namespace string, service string) error {
+	if c.IdleError != "" {
+		return fmt.Errorf(c.IdleError)
+	}
+	if c.SoftIdledState {
+		c.UnIdleCallCount++
+		c.SoftIdledState = false
+	}
+	return nil
+}
+
+// SoftIdled mocks SoftIdled method of client.OpenShiftClient.
+// It returns SoftIdledState.
+func (c *OpenShiftClient) SoftIdled(apiURL string, bearerToken string, namespace string, service string) (bool, error) {
+	if c.IdleError != "" {
+		return false, fmt.Errorf(c.IdleError)
+	}
+	return c.SoftIdledState, nil
+}
+
+// ProjectAnnotations mocks ProjectAnnotations method of client.OpenShiftClient.
+// It returns ProjectPolicy.
+func (c *OpenShiftClient) ProjectAnnotations(apiURL string, bearerToken string, namespace string) (model.PolicyAnnotations, error) {
+	if c.IdleError != "" {
+		return model.PolicyAnnotations{}, fmt.Errorf(c.IdleError)
+	}
+	return c.ProjectPolicy, nil
+}
+
+// CreateEvent mocks CreateEvent method of client.OpenShiftClient.
+// It appends the event to Events.
+func (c *OpenShiftClient) CreateEvent(apiURL string, bearerToken string, namespace string, event v1.Event) error {
+	c.Events = append(c.Events, event)
+	return nil
+}
+
 // ResetCounts resets calls made to the idler(idle/unidle) to 0.
 func (c *OpenShiftClient) ResetCounts() {
 	c.UnIdleCallCount = 0
@@ -87,3 +183,54 @@ func (c *OpenShiftClient) ResetCounts() {
 func (c *OpenShiftClient) String() string {
 	return "MockOpenShiftClient"
 }
+
+// RouteURL mocks RouteURL method of client.OpenShiftClient.
+// It returns JenkinsURL.
+func (c *OpenShiftClient) RouteURL(apiURL string, bearerToken string, namespace string, route string) (string, error) {
+	if c.JenkinsURL == "" {
+		return "", fmt.Errorf("route %s not found", route)
+	}
+	return c.JenkinsURL, nil
+}
+
+// CancelBuild mocks CancelBuild method of client.OpenShiftClient.
+// It appends the build to CancelledBuilds.
+func (c *OpenShiftClient) CancelBuild(apiURL string, bearerToken string, namespace string, build string) error {
+	if c.IdleError != "" {
+		return fmt.Errorf(c.IdleError)
+	}
+	c.CancelledBuilds = append(c.CancelledBuilds, build)
+	return nil
+}
+
+// ConfigMap mocks ConfigMap method of client.OpenShiftClient.
+// It returns the config map stored in ConfigMaps under its name, nil if there is none.
+func (c *OpenShiftClient) ConfigMap(apiURL string, bearerToken string, namespace string, name string) (*v1.ConfigMap, error) {
+	if c.IdleError != "" {
+		return nil, fmt.Errorf(c.IdleError)
+	}
+	configMap, ok := c.ConfigMaps[name]
+	if !ok {
+		return nil, nil
+	}
+	return &configMap, nil
+}
+
+// SaveConfigMap mocks SaveConfigMap method of client.OpenShiftClient.
+// It stores the config map in ConfigMaps with an incremented resource version, unless the given
+// resource version is outdated.
+func (c *OpenShiftClient) SaveConfigMap(apiURL string, bearerToken string, namespace string, configMap v1.ConfigMap) error {
+	if c.IdleError != "" {
+		return fmt.Errorf(c.IdleError)
+	}
+	if c.ConfigMaps == nil {
+		c.ConfigMaps = make(map[string]v1.ConfigMap)
+	}
+	if c.ConfigMaps[configMap.Name].ResourceVersion != configMap.ResourceVersion {
+		return client.ErrConflict
+	}
+	version, _ := strconv.Atoi(configMap.ResourceVersion)
+	configMap.ResourceVersion = strconv.Itoa(version + 1)
+	c.ConfigMaps[configMap.Name] = configMap
+	return nil
+}
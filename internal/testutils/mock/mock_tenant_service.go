@@ -1,10 +1,17 @@
 package mock
 
-import "github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
+import (
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-jenkins-idler/internal/tenant"
+)
 
 // TenantService provides information about tenants running on Openshift cluster.
 // This is the mock interface
-type TenantService struct{}
+type TenantService struct {
+	// Tenants maps user tokens to the tenant returned by GetTenantInfoByToken.
+	Tenants map[string]tenant.Info
+}
 
 // GetTenantInfoByNamespace Mocks get info
 func (t *TenantService) GetTenantInfoByNamespace(apiURL string, ns string) (tenant.InfoList, error) {
@@ -15,3 +22,12 @@ func (t *TenantService) GetTenantInfoByNamespace(apiURL string, ns string) (tena
 func (t *TenantService) HasReachedMaxCapacity(apiURL, ns string) (bool, error) {
 	return false, nil
 }
+
+// GetTenantInfoByToken returns the tenant of the given token, an error for unknown tokens
+func (t *TenantService) GetTenantInfoByToken(userToken string) (tenant.Info, error) {
+	info, ok := t.Tenants[userToken]
+	if !ok {
+		return tenant.Info{}, fmt.Errorf("unknown token")
+	}
+	return info, nil
+}
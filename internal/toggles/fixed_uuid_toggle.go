@@ -17,3 +17,8 @@ func NewFixedUUIDToggle(uuids []string) (Features, error) {
 func (t *fixedUUIDToggle) IsIdlerEnabled(uuid string) (bool, error) {
 	return util.Contains(t.uuids, uuid), nil
 }
+
+// Tier always returns an empty string, the tier of a user cannot be toggled using fixed uuids.
+func (t *fixedUUIDToggle) Tier(uid string, tiers []string) (string, error) {
+	return "", nil
+}
@@ -5,4 +5,8 @@ package toggles
 type Features interface {
 	// IsIdlerEnabled returns true if the Jenkins idler is enabled for the user with the specified uid, false otherwise.
 	IsIdlerEnabled(uid string) (bool, error)
+
+	// Tier returns the first of the specified policy tiers which is enabled for the user with the specified uid.
+	// An empty string is returned if none of the tiers is enabled.
+	Tier(uid string, tiers []string) (string, error)
 }
@@ -16,6 +16,7 @@ import (
 const (
 	appName         = "jenkins-idler"
 	toggleFeature   = "jenkins.idler"
+	tierFeature     = "jenkins.idler.tier."
 	maxWaitForReady = 10
 )
 
@@ -61,6 +62,16 @@ func (t *unleashToggle) IsIdlerEnabled(uid string) (bool, error) {
 	return enabled, nil
 }
 
+// Tier returns the first tier whose feature toggle, e.g. jenkins.idler.tier.enterprise, is enabled for the user.
+func (t *unleashToggle) Tier(uid string, tiers []string) (string, error) {
+	for _, tier := range tiers {
+		if t.unleashClient.IsEnabled(tierFeature+tier, withContext(uid), unleash.WithFallback(false)) {
+			return tier, nil
+		}
+	}
+	return "", nil
+}
+
 // withContext creates a context based toggle with the user id as key.
 func withContext(uid string) unleash.FeatureOption {
 	ctx := context.Context{
@@ -23,10 +23,36 @@ var (
 		Help:      "Bucketed histogram of processing time (s) of requests.",
 		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 8),
 	}, reqLabels)
+
+	decisionLabels = []string{"action", "dry_run"}
+	decisions      = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "idler_decisions_total",
+		Help:      "Number of idle check decisions by action and dry-run mode.",
+	}, decisionLabels)
+
+	predictionLabels = []string{"outcome"}
+	predictions      = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "idler_prewarm_predictions_total",
+		Help:      "Number of activity predictions by outcome (hit or miss).",
+	}, predictionLabels)
+
+	prewarmWaste = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "idler_prewarm_wasted_seconds_total",
+		Help:      "Time (s) Jenkins was kept warm for predicted activity which did not happen.",
+	})
 )
 
 func registerMetrics() {
 	reqDuration = register(reqDuration, "idler_request_duration_seconds").(*prometheus.HistogramVec)
+	decisions = register(decisions, "idler_decisions_total").(*prometheus.CounterVec)
+	predictions = register(predictions, "idler_prewarm_predictions_total").(*prometheus.CounterVec)
+	prewarmWaste = register(prewarmWaste, "idler_prewarm_wasted_seconds_total").(prometheus.Counter)
 }
 
 func register(c prometheus.Collector, name string) prometheus.Collector {
@@ -52,6 +78,21 @@ func reportRequestDuration(jenkinsService, operation string, code int, elapsedTi
 	}
 }
 
+func reportDecision(action string, dryRun bool) {
+	if action != "" {
+		decisions.WithLabelValues(action, strconv.FormatBool(dryRun)).Inc()
+	}
+}
+
+func reportPrediction(hit bool, wasted float64) {
+	if hit {
+		predictions.WithLabelValues("hit").Inc()
+		return
+	}
+	predictions.WithLabelValues("miss").Inc()
+	prewarmWaste.Add(wasted)
+}
+
 func codeVal(status int) string {
 	code := (status - (status % 100)) / 100
 	return strconv.Itoa(code) + "xx"
@@ -4,6 +4,8 @@ package metric
 type Recorder interface {
 	Initialize()
 	RecordReqDuration(jenkinsService, operation string, code int, elapsedTime float64)
+	RecordDecision(action string, dryRun bool)
+	RecordPrediction(hit bool, wasted float64)
 }
 
 // PrometheusRecorder struct used to record metrics to be consumed by Prometheus
@@ -19,3 +21,13 @@ func (pr PrometheusRecorder) Initialize() {
 func (pr PrometheusRecorder) RecordReqDuration(jenkinsService, operation string, code int, elapsedTime float64) {
 	reportRequestDuration(jenkinsService, operation, code, elapsedTime)
 }
+
+// RecordDecision records the action an idle check decided on, and whether it was a dry-run
+func (pr PrometheusRecorder) RecordDecision(action string, dryRun bool) {
+	reportDecision(action, dryRun)
+}
+
+// RecordPrediction records whether a predicted activity happened, and for how long (s) Jenkins was warmed in vain if not
+func (pr PrometheusRecorder) RecordPrediction(hit bool, wasted float64) {
+	reportPrediction(hit, wasted)
+}
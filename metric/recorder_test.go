@@ -44,6 +44,54 @@ func TestReqDurationMetric(t *testing.T) {
 	checkHistogram(t, m, uint64(len(reqTimes)), expectedBound, expectedCnt)
 }
 
+func TestDecisionMetric(t *testing.T) {
+	recorder := PrometheusRecorder{}
+	recorder.RecordDecision("idle", true)
+	recorder.RecordDecision("idle", true)
+	recorder.RecordDecision("unidle", false)
+
+	m := &dto.Metric{}
+	idleMetric, _ := decisions.GetMetricWithLabelValues("idle", "true")
+	idleMetric.Write(m)
+	if m.Counter.GetValue() != 2 {
+		t.Errorf("dry-run idle decision count was incorrect, want: 2, got: %f", m.Counter.GetValue())
+	}
+
+	m = &dto.Metric{}
+	unidleMetric, _ := decisions.GetMetricWithLabelValues("unidle", "false")
+	unidleMetric.Write(m)
+	if m.Counter.GetValue() != 1 {
+		t.Errorf("unidle decision count was incorrect, want: 1, got: %f", m.Counter.GetValue())
+	}
+}
+
+func TestPredictionMetric(t *testing.T) {
+	recorder := PrometheusRecorder{}
+	recorder.RecordPrediction(true, 0)
+	recorder.RecordPrediction(false, 1800)
+	recorder.RecordPrediction(false, 600)
+
+	m := &dto.Metric{}
+	hitMetric, _ := predictions.GetMetricWithLabelValues("hit")
+	hitMetric.Write(m)
+	if m.Counter.GetValue() != 1 {
+		t.Errorf("prediction hit count was incorrect, want: 1, got: %f", m.Counter.GetValue())
+	}
+
+	m = &dto.Metric{}
+	missMetric, _ := predictions.GetMetricWithLabelValues("miss")
+	missMetric.Write(m)
+	if m.Counter.GetValue() != 2 {
+		t.Errorf("prediction miss count was incorrect, want: 2, got: %f", m.Counter.GetValue())
+	}
+
+	m = &dto.Metric{}
+	prewarmWaste.Write(m)
+	if m.Counter.GetValue() != 2400 {
+		t.Errorf("wasted warm-up time was incorrect, want: 2400, got: %f", m.Counter.GetValue())
+	}
+}
+
 func checkHistogram(t *testing.T, m *dto.Metric, expectedCount uint64, expectedBound []float64, expectedCnt []uint64) {
 	if expectedCount != m.Histogram.GetSampleCount() {
 		t.Errorf("Histogram count was incorrect, want: %d, got: %d",